@@ -0,0 +1,126 @@
+package mnemo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestCanAccess — verifies allowed and denied access checks.
+// ---------------------------------------------------------------------------
+
+func TestCanAccess(t *testing.T) {
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in CanAccessInput
+		_ = json.Unmarshal(args, &in)
+		if in.Permission == PermissionRead {
+			return CanAccessResponse{Allowed: true}, nil
+		}
+		reason := "agent has read-only access"
+		return CanAccessResponse{Allowed: false, Reason: &reason}, nil
+	})
+
+	ctx := context.Background()
+	if ok, err := c.CanAccess(ctx, "mem-1", PermissionRead); err != nil || !ok {
+		t.Errorf("CanAccess(read) = %v, %v, want true, nil", ok, err)
+	}
+	if ok, err := c.CanAccess(ctx, "mem-1", PermissionWrite); err != nil || ok {
+		t.Errorf("CanAccess(write) = %v, %v, want false, nil", ok, err)
+	}
+
+	allowed, err := c.CanAccessContext(ctx, CanAccessInput{MemoryID: "mem-1", Permission: PermissionRead})
+	if err != nil {
+		t.Fatalf("CanAccessContext: %v", err)
+	}
+	if !allowed.Allowed || allowed.Reason != nil {
+		t.Errorf("read access = %+v, want allowed without reason", allowed)
+	}
+
+	denied, err := c.CanAccessContext(ctx, CanAccessInput{MemoryID: "mem-1", Permission: PermissionWrite})
+	if err != nil {
+		t.Fatalf("CanAccessContext: %v", err)
+	}
+	if denied.Allowed || denied.Reason == nil || *denied.Reason != "agent has read-only access" {
+		t.Errorf("write access = %+v, want denied with reason", denied)
+	}
+
+	var verr *ValidationError
+	if _, err := c.CanAccess(ctx, "mem-1", "own"); !errors.As(err, &verr) {
+		t.Errorf("CanAccess with unknown permission = %v, want ValidationError", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestPermissionDeniedError — verifies denied writes return a typed error.
+// ---------------------------------------------------------------------------
+
+func TestPermissionDeniedError(t *testing.T) {
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		return toolFailure("permission denied: memory is owned by agent-2"), nil
+	})
+
+	_, err := c.Share(ShareInput{MemoryID: "mem-1", TargetAgentID: "agent-3"})
+	var pd *PermissionDeniedError
+	if !errors.As(err, &pd) {
+		t.Fatalf("Share() = %v, want *PermissionDeniedError", err)
+	}
+	if pd.MemoryID != "mem-1" || pd.Permission != PermissionShare || pd.Reason != "memory is owned by agent-2" {
+		t.Errorf("PermissionDeniedError = %+v", pd)
+	}
+	if !errors.Is(err, ErrPermissionDenied) {
+		t.Error("errors.Is(err, ErrPermissionDenied) = false, want true")
+	}
+
+	_, err = c.Forget(ForgetInput{MemoryIDs: []string{"mem-1", "mem-2"}})
+	if !errors.As(err, &pd) || pd.Permission != PermissionDelete || pd.MemoryID != "" {
+		t.Errorf("Forget() = %v, want delete PermissionDeniedError without a single memory ID", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestFilterAccessible — verifies only allowed IDs are returned, in order.
+// ---------------------------------------------------------------------------
+
+func TestFilterAccessible(t *testing.T) {
+	var calls int
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		calls++
+		if name != "mnemo.batch_check_access" {
+			t.Errorf("tool = %q, want mnemo.batch_check_access", name)
+		}
+		var in BatchCheckAccessInput
+		_ = json.Unmarshal(args, &in)
+		var resp BatchCheckAccessResponse
+		for _, check := range in.Checks {
+			resp.Results = append(resp.Results, CanAccessResult{
+				MemoryID:   check.MemoryID,
+				Permission: check.Permission,
+				Allowed:    check.MemoryID != "mem-2",
+			})
+		}
+		return resp, nil
+	})
+
+	got, err := c.FilterAccessible(context.Background(), []string{"mem-3", "mem-2", "mem-1"}, PermissionWrite)
+	if err != nil {
+		t.Fatalf("FilterAccessible: %v", err)
+	}
+	if want := []string{"mem-3", "mem-1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterAccessible() = %v, want %v", got, want)
+	}
+
+	if got, err := c.FilterAccessible(context.Background(), nil, PermissionWrite); err != nil || got != nil {
+		t.Errorf("FilterAccessible(nil) = %v, %v, want nil, nil", got, err)
+	}
+	if calls != 1 {
+		t.Errorf("server calls = %d, want 1", calls)
+	}
+
+	var verr *ValidationError
+	if _, err := c.BatchCheckAccess(BatchCheckAccessInput{}); !errors.As(err, &verr) {
+		t.Errorf("BatchCheckAccess with no checks = %v, want ValidationError", err)
+	}
+}
@@ -0,0 +1,93 @@
+package mnemo
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// restoreChunkSize is the number of backup bytes sent per mnemo.restore
+// call. It is a variable so tests can exercise multi-chunk uploads.
+var restoreChunkSize = 256 * 1024
+
+// Backup exports the memory store in input.Format and writes the backup to w.
+//
+// The server answers with several content items: the first is the JSON
+// BackupSummary, and each following item is a base64-encoded chunk of the
+// backup. Chunks are decoded and written to w in order; if a write fails the
+// backup written so far is incomplete.
+func (c *Client) Backup(ctx context.Context, input BackupInput, w io.Writer) (*BackupSummary, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	var contents toolContents
+	if err := c.callTool(ctx, "mnemo.backup", input, &contents); err != nil {
+		return nil, err
+	}
+	if len(contents) == 0 {
+		return nil, fmt.Errorf("mnemo mnemo.backup: no content in result")
+	}
+
+	var summary BackupSummary
+	if err := c.unmarshal([]byte(contents[0].Text), &summary); err != nil {
+		return nil, fmt.Errorf("mnemo mnemo.backup: unmarshal summary: %w", err)
+	}
+
+	for i, item := range contents[1:] {
+		chunk, err := base64.StdEncoding.DecodeString(item.Text)
+		if err != nil {
+			return nil, fmt.Errorf("mnemo mnemo.backup: decode chunk %d: %w", i, err)
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return nil, fmt.Errorf("mnemo mnemo.backup: write chunk %d: %w", i, err)
+		}
+	}
+	return &summary, nil
+}
+
+// RestoreFromBackup imports a backup produced by Backup, reading it from r.
+// The backup is streamed to the server in chunks of base64-encoded bytes, so
+// r is never read into memory in full. The response to the final chunk
+// reports the outcome of the import.
+func (c *Client) RestoreFromBackup(ctx context.Context, r io.Reader, opts RestoreOptions) (*RestoreBackupResponse, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	uploadID, err := newIdempotencyKey()
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(r)
+	buf := make([]byte, restoreChunkSize)
+	for index := 0; ; index++ {
+		n, err := io.ReadFull(br, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("mnemo mnemo.restore: read chunk %d: %w", index, err)
+		}
+		final := err != nil
+		if !final {
+			if _, err := br.Peek(1); err == io.EOF {
+				final = true
+			}
+		}
+
+		chunk := restoreChunkInput{
+			RestoreOptions: opts,
+			UploadID:       uploadID,
+			ChunkIndex:     index,
+			Data:           base64.StdEncoding.EncodeToString(buf[:n]),
+			Final:          final,
+		}
+		var resp RestoreBackupResponse
+		if err := c.callTool(ctx, "mnemo.restore", chunk, &resp); err != nil {
+			return nil, err
+		}
+		if final {
+			return &resp, nil
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package mnemo
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestBackup — verifies backup chunks are streamed to the writer.
+// ---------------------------------------------------------------------------
+
+func TestBackup(t *testing.T) {
+	chunks := []string{`{"memories":[{"id":"mem-1"},`, `{"id":"mem-2"}]}`}
+	var gotFormat string
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in BackupInput
+		_ = json.Unmarshal(args, &in)
+		gotFormat = in.Format
+
+		summary, _ := json.Marshal(BackupSummary{
+			RecordCount: 2,
+			SizeBytes:   int64(len(chunks[0]) + len(chunks[1])),
+			Checksum:    "abc123",
+			Format:      in.Format,
+			CreatedAt:   "2024-01-01T00:00:00Z",
+		})
+		items := toolContents{{Type: "text", Text: string(summary)}}
+		for _, chunk := range chunks {
+			items = append(items, jsonRPCContent{Type: "text", Text: base64.StdEncoding.EncodeToString([]byte(chunk))})
+		}
+		return items, nil
+	})
+
+	var buf bytes.Buffer
+	summary, err := c.Backup(context.Background(), BackupInput{Format: BackupFormatJSONArchive}, &buf)
+	if err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	if gotFormat != "json_archive" {
+		t.Errorf("format = %q, want %q", gotFormat, "json_archive")
+	}
+	if summary.RecordCount <= 0 {
+		t.Errorf("RecordCount = %d, want > 0", summary.RecordCount)
+	}
+	if want := chunks[0] + chunks[1]; buf.String() != want {
+		t.Errorf("backup bytes = %q, want %q", buf.String(), want)
+	}
+	if int64(buf.Len()) != summary.SizeBytes {
+		t.Errorf("wrote %d bytes, SizeBytes = %d", buf.Len(), summary.SizeBytes)
+	}
+
+	var verr *ValidationError
+	if _, err := c.Backup(context.Background(), BackupInput{Format: "tarball"}, &buf); !errors.As(err, &verr) {
+		t.Errorf("Backup with unknown format = %v, want ValidationError", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRestoreFromBackupDryRun — verifies a dry-run restore streams every
+// chunk and writes nothing.
+// ---------------------------------------------------------------------------
+
+func TestRestoreFromBackupDryRun(t *testing.T) {
+	old := restoreChunkSize
+	restoreChunkSize = 16
+	defer func() { restoreChunkSize = old }()
+
+	archive := `{"memories":[{"id":"mem-1","content":"a"},{"id":"mem-2","content":"b"}]}`
+
+	var received bytes.Buffer
+	var chunks, writes int
+	uploadIDs := map[string]bool{}
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in restoreChunkInput
+		_ = json.Unmarshal(args, &in)
+		if in.ChunkIndex != chunks {
+			t.Errorf("chunk_index = %d, want %d", in.ChunkIndex, chunks)
+		}
+		chunks++
+		uploadIDs[in.UploadID] = true
+		data, _ := base64.StdEncoding.DecodeString(in.Data)
+		received.Write(data)
+
+		if !in.Final {
+			return RestoreBackupResponse{Status: "pending"}, nil
+		}
+		if !in.DryRun {
+			writes++
+			return RestoreBackupResponse{Imported: 2, Status: "restored"}, nil
+		}
+		return RestoreBackupResponse{Imported: 2, Status: "dry_run"}, nil
+	})
+
+	resp, err := c.RestoreFromBackup(context.Background(), bytes.NewReader([]byte(archive)),
+		RestoreOptions{Format: BackupFormatJSONArchive, DryRun: true})
+	if err != nil {
+		t.Fatalf("RestoreFromBackup: %v", err)
+	}
+	if resp.Status != "dry_run" {
+		t.Errorf("Status = %q, want %q", resp.Status, "dry_run")
+	}
+	if writes != 0 {
+		t.Errorf("writes = %d, want 0 for a dry run", writes)
+	}
+	if received.String() != archive {
+		t.Errorf("server received %q, want %q", received.String(), archive)
+	}
+	if want := (len(archive) + 15) / 16; chunks != want {
+		t.Errorf("chunks = %d, want %d", chunks, want)
+	}
+	if len(uploadIDs) != 1 {
+		t.Errorf("upload IDs = %v, want a single ID shared by every chunk", uploadIDs)
+	}
+
+	var verr *ValidationError
+	if _, err := c.RestoreFromBackup(context.Background(), bytes.NewReader(nil), RestoreOptions{Format: "csv"}); !errors.As(err, &verr) {
+		t.Errorf("RestoreFromBackup with unknown format = %v, want ValidationError", err)
+	}
+}
@@ -0,0 +1,271 @@
+package mnemo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CachedClient wraps a MemoryClient with an in-memory cache of Recall
+// results. Identical RecallInputs within the TTL are answered from the
+// cache. Any Remember or Forget for an agent drops that agent's cached
+// entries. All other calls pass straight through. Fields supplied by the
+// context, such as WithAgentID, count as part of the input.
+//
+// CachedClient is safe for concurrent use.
+type CachedClient struct {
+	inner      MemoryClient
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	order   []string // keys in insertion order, oldest first
+
+	// generations counts invalidations per agent, so a Recall that was in
+	// flight during an invalidation does not cache its stale result.
+	generations map[string]uint64
+}
+
+// cacheEntry is one cached Recall result.
+type cacheEntry struct {
+	agent   string
+	resp    RecallResponse
+	expires time.Time
+}
+
+// NewCachedClient wraps inner with a Recall cache holding at most
+// maxEntries results for ttl each. When full, the oldest entry is evicted.
+// A maxEntries below 1 is treated as 1.
+func NewCachedClient(inner MemoryClient, ttl time.Duration, maxEntries int) MemoryClient {
+	if maxEntries < 1 {
+		maxEntries = 1
+	}
+	return &CachedClient{
+		inner:       inner,
+		ttl:         ttl,
+		maxEntries:  maxEntries,
+		now:         time.Now,
+		entries:     make(map[string]cacheEntry),
+		generations: make(map[string]uint64),
+	}
+}
+
+// RecallContext returns a cached result for an identical input if one has
+// not expired, and otherwise calls the wrapped client and caches the result.
+func (cc *CachedClient) RecallContext(ctx context.Context, input RecallInput) (*RecallResponse, error) {
+	resolved := applyContextFields(ctx, "mnemo.recall", input).(RecallInput)
+	key, err := recallCacheKey(resolved)
+	if err != nil {
+		return nil, err
+	}
+	agent := agentKey(resolved.AgentID)
+
+	cc.mu.Lock()
+	if e, ok := cc.entries[key]; ok {
+		if cc.now().Before(e.expires) {
+			cc.mu.Unlock()
+			return copyRecallResponse(e.resp), nil
+		}
+		cc.remove(key)
+	}
+	gen := cc.generations[agent]
+	cc.mu.Unlock()
+
+	resp, err := cc.inner.RecallContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.generations[agent] != gen {
+		return resp, nil
+	}
+	if _, ok := cc.entries[key]; !ok {
+		for len(cc.order) >= cc.maxEntries {
+			cc.remove(cc.order[0])
+		}
+		cc.order = append(cc.order, key)
+	}
+	cc.entries[key] = cacheEntry{agent: agent, resp: *copyRecallResponse(*resp), expires: cc.now().Add(cc.ttl)}
+	return resp, nil
+}
+
+// RememberContext calls the wrapped client and invalidates the agent's
+// cached recalls.
+func (cc *CachedClient) RememberContext(ctx context.Context, input RememberInput) (*RememberResponse, error) {
+	resolved := applyContextFields(ctx, "mnemo.remember", input).(RememberInput)
+	defer cc.invalidate(agentKey(resolved.AgentID))
+	return cc.inner.RememberContext(ctx, input)
+}
+
+// ForgetContext calls the wrapped client and invalidates the agent's cached
+// recalls.
+func (cc *CachedClient) ForgetContext(ctx context.Context, input ForgetInput) (*ForgetResponse, error) {
+	resolved := applyContextFields(ctx, "mnemo.forget", input).(ForgetInput)
+	defer cc.invalidate(agentKey(resolved.AgentID))
+	return cc.inner.ForgetContext(ctx, input)
+}
+
+// ShareContext calls the wrapped client.
+func (cc *CachedClient) ShareContext(ctx context.Context, input ShareInput) (*ShareResponse, error) {
+	return cc.inner.ShareContext(ctx, input)
+}
+
+// CheckpointContext calls the wrapped client.
+func (cc *CachedClient) CheckpointContext(ctx context.Context, input CheckpointInput) (*CheckpointResponse, error) {
+	return cc.inner.CheckpointContext(ctx, input)
+}
+
+// BranchContext calls the wrapped client.
+func (cc *CachedClient) BranchContext(ctx context.Context, input BranchInput) (*BranchResponse, error) {
+	return cc.inner.BranchContext(ctx, input)
+}
+
+// MergeContext calls the wrapped client.
+func (cc *CachedClient) MergeContext(ctx context.Context, input MergeInput) (*MergeResponse, error) {
+	return cc.inner.MergeContext(ctx, input)
+}
+
+// ReplayContext calls the wrapped client.
+func (cc *CachedClient) ReplayContext(ctx context.Context, input ReplayInput) (*ReplayResponse, error) {
+	return cc.inner.ReplayContext(ctx, input)
+}
+
+// VerifyContext calls the wrapped client.
+func (cc *CachedClient) VerifyContext(ctx context.Context, input VerifyInput) (*VerifyResponse, error) {
+	return cc.inner.VerifyContext(ctx, input)
+}
+
+// DelegateContext calls the wrapped client.
+func (cc *CachedClient) DelegateContext(ctx context.Context, input DelegateInput) (*DelegateResponse, error) {
+	return cc.inner.DelegateContext(ctx, input)
+}
+
+// invalidate drops every cached entry belonging to agent and stops Recalls
+// already in flight for it from caching their results.
+func (cc *CachedClient) invalidate(agent string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	cc.generations[agent]++
+	for key, e := range cc.entries {
+		if e.agent == agent {
+			cc.remove(key)
+		}
+	}
+}
+
+// remove deletes key from the cache. Must be called with cc.mu held.
+func (cc *CachedClient) remove(key string) {
+	delete(cc.entries, key)
+	for i, k := range cc.order {
+		if k == key {
+			cc.order = append(cc.order[:i], cc.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// recallCacheKey hashes everything in input that can change the result,
+// including the client-side fields that are not sent to the server.
+func recallCacheKey(input RecallInput) (string, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("mnemo: cache key: %w", err)
+	}
+	h := sha256.New()
+	h.Write(data)
+	if input.DeduplicateThreshold != nil {
+		fmt.Fprintf(h, "|dedupe=%v", *input.DeduplicateThreshold)
+	}
+	if input.FallbackStrategy != nil {
+		fmt.Fprintf(h, "|fallback=%q", *input.FallbackStrategy)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// agentKey maps an optional agent ID to a cache partition. Nil means the
+// client's default agent.
+func agentKey(id *string) string {
+	if id == nil {
+		return ""
+	}
+	return *id
+}
+
+// copyRecallResponse returns a deep copy of resp, so callers can modify
+// what they receive without corrupting the cached entry.
+func copyRecallResponse(resp RecallResponse) *RecallResponse {
+	out := resp
+	if resp.Memories != nil {
+		out.Memories = make([]RecalledMemory, len(resp.Memories))
+		for i, m := range resp.Memories {
+			out.Memories[i] = copyRecalledMemory(m)
+		}
+	}
+	out.SearchMetadata = copyPtr(resp.SearchMetadata)
+	out.FallbackStrategyUsed = copyPtr(resp.FallbackStrategyUsed)
+	return &out
+}
+
+// copyRecalledMemory returns a copy of m that shares no slices, maps, or
+// pointers with it.
+func copyRecalledMemory(m RecalledMemory) RecalledMemory {
+	out := m
+	if m.Tags != nil {
+		out.Tags = append([]string(nil), m.Tags...)
+	}
+	if m.Metadata != nil {
+		out.Metadata = copyJSONValue(m.Metadata).(map[string]interface{})
+	}
+	if m.ScoreBreakdown != nil {
+		out.ScoreBreakdown = make(map[string]float32, len(m.ScoreBreakdown))
+		for k, v := range m.ScoreBreakdown {
+			out.ScoreBreakdown[k] = v
+		}
+	}
+	out.TTLSeconds = copyPtr(m.TTLSeconds)
+	out.CreatedBy = copyPtr(m.CreatedBy)
+	out.SourceType = copyPtr(m.SourceType)
+	out.SourceID = copyPtr(m.SourceID)
+	out.RerankerScore = copyPtr(m.RerankerScore)
+	out.ConfidenceScore = copyPtr(m.ConfidenceScore)
+	return out
+}
+
+// copyJSONValue deep-copies the maps and slices of a decoded JSON value.
+// Scalars are returned as is.
+func copyJSONValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			out[k] = copyJSONValue(e)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = copyJSONValue(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// copyPtr returns a pointer to a copy of *p, or nil if p is nil.
+func copyPtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
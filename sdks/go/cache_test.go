@@ -0,0 +1,252 @@
+package mnemo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// TestCachedClientHitMissExpiry — verifies hits, misses, and TTL expiry.
+// ---------------------------------------------------------------------------
+
+func TestCachedClientHitMissExpiry(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeMemoryClient{}
+	mc := NewCachedClient(fake, time.Minute, 10)
+	cc := mc.(*CachedClient)
+
+	clock := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	cc.now = func() time.Time { return clock }
+
+	if _, err := mc.RecallContext(ctx, RecallInput{Query: "prefs"}); err != nil {
+		t.Fatalf("RecallContext: %v", err)
+	}
+	if _, err := mc.RecallContext(ctx, RecallInput{Query: "prefs"}); err != nil {
+		t.Fatalf("RecallContext: %v", err)
+	}
+	if got := fake.count("recall"); got != 1 {
+		t.Errorf("inner recall calls after hit = %d, want 1", got)
+	}
+
+	if _, err := mc.RecallContext(ctx, RecallInput{Query: "other"}); err != nil {
+		t.Fatalf("RecallContext: %v", err)
+	}
+	if got := fake.count("recall"); got != 2 {
+		t.Errorf("inner recall calls after miss = %d, want 2", got)
+	}
+
+	clock = clock.Add(2 * time.Minute)
+	if _, err := mc.RecallContext(ctx, RecallInput{Query: "prefs"}); err != nil {
+		t.Fatalf("RecallContext: %v", err)
+	}
+	if got := fake.count("recall"); got != 3 {
+		t.Errorf("inner recall calls after expiry = %d, want 3", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCachedClientInvalidation — verifies writes drop only that agent's cache.
+// ---------------------------------------------------------------------------
+
+func TestCachedClientInvalidation(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeMemoryClient{}
+	mc := NewCachedClient(fake, time.Hour, 10)
+
+	alice, bob := "alice", "bob"
+	_, _ = mc.RecallContext(ctx, RecallInput{Query: "q", AgentID: &alice})
+	_, _ = mc.RecallContext(ctx, RecallInput{Query: "q", AgentID: &bob})
+
+	if _, err := mc.RememberContext(ctx, RememberInput{Content: "new fact", AgentID: &alice}); err != nil {
+		t.Fatalf("RememberContext: %v", err)
+	}
+
+	_, _ = mc.RecallContext(ctx, RecallInput{Query: "q", AgentID: &alice})
+	_, _ = mc.RecallContext(ctx, RecallInput{Query: "q", AgentID: &bob})
+	if got := fake.count("recall"); got != 3 {
+		t.Errorf("inner recall calls = %d, want 3 (alice refetched, bob cached)", got)
+	}
+
+	if _, err := mc.ForgetContext(ctx, ForgetInput{MemoryIDs: []string{"m1"}, AgentID: &bob}); err != nil {
+		t.Fatalf("ForgetContext: %v", err)
+	}
+	_, _ = mc.RecallContext(ctx, RecallInput{Query: "q", AgentID: &bob})
+	if got := fake.count("recall"); got != 4 {
+		t.Errorf("inner recall calls = %d, want 4 after forget", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCachedClientMaxEntries — verifies the oldest entry is evicted.
+// ---------------------------------------------------------------------------
+
+func TestCachedClientMaxEntries(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeMemoryClient{}
+	mc := NewCachedClient(fake, time.Hour, 2)
+
+	_, _ = mc.RecallContext(ctx, RecallInput{Query: "a"})
+	_, _ = mc.RecallContext(ctx, RecallInput{Query: "b"})
+	_, _ = mc.RecallContext(ctx, RecallInput{Query: "c"}) // evicts "a"
+	_, _ = mc.RecallContext(ctx, RecallInput{Query: "c"})
+	_, _ = mc.RecallContext(ctx, RecallInput{Query: "a"})
+
+	if got := fake.count("recall"); got != 4 {
+		t.Errorf("inner recall calls = %d, want 4", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCachedClientContextAgent — verifies WithAgentID partitions the cache.
+// ---------------------------------------------------------------------------
+
+func TestCachedClientContextAgent(t *testing.T) {
+	fake := &fakeMemoryClient{}
+	mc := NewCachedClient(fake, time.Hour, 10)
+
+	aliceCtx := WithAgentID(context.Background(), "alice")
+	bobCtx := WithAgentID(context.Background(), "bob")
+
+	_, _ = mc.RecallContext(aliceCtx, RecallInput{Query: "q"})
+	_, _ = mc.RecallContext(bobCtx, RecallInput{Query: "q"})
+	if got := fake.count("recall"); got != 2 {
+		t.Fatalf("inner recall calls = %d, want 2 (one per context agent)", got)
+	}
+
+	if _, err := mc.RememberContext(aliceCtx, RememberInput{Content: "new fact"}); err != nil {
+		t.Fatalf("RememberContext: %v", err)
+	}
+	_, _ = mc.RecallContext(aliceCtx, RecallInput{Query: "q"})
+	_, _ = mc.RecallContext(bobCtx, RecallInput{Query: "q"})
+	if got := fake.count("recall"); got != 3 {
+		t.Errorf("inner recall calls = %d, want 3 (alice refetched, bob cached)", got)
+	}
+}
+
+// blockingRecallClient is a fakeMemoryClient whose RecallContext calls
+// hook before answering, letting a test interleave other calls.
+type blockingRecallClient struct {
+	*fakeMemoryClient
+	hook func()
+}
+
+func (b *blockingRecallClient) RecallContext(ctx context.Context, input RecallInput) (*RecallResponse, error) {
+	if b.hook != nil {
+		b.hook()
+	}
+	return b.fakeMemoryClient.RecallContext(ctx, input)
+}
+
+// ---------------------------------------------------------------------------
+// TestCachedClientStaleFill — verifies a Recall in flight during an
+// invalidation does not cache its result.
+// ---------------------------------------------------------------------------
+
+func TestCachedClientStaleFill(t *testing.T) {
+	ctx := context.Background()
+	inner := &blockingRecallClient{fakeMemoryClient: &fakeMemoryClient{}}
+	mc := NewCachedClient(inner, time.Hour, 10)
+
+	inner.hook = func() {
+		inner.hook = nil
+		if _, err := mc.RememberContext(ctx, RememberInput{Content: "new fact"}); err != nil {
+			t.Errorf("RememberContext: %v", err)
+		}
+	}
+
+	_, _ = mc.RecallContext(ctx, RecallInput{Query: "q"})
+	_, _ = mc.RecallContext(ctx, RecallInput{Query: "q"})
+	if got := inner.count("recall"); got != 2 {
+		t.Errorf("inner recall calls = %d, want 2 (stale fill dropped)", got)
+	}
+}
+
+// metadataRecallClient is a fakeMemoryClient whose recalls carry tags,
+// metadata, and pointer fields, to exercise copying of cached responses.
+type metadataRecallClient struct {
+	*fakeMemoryClient
+}
+
+func (m *metadataRecallClient) RecallContext(ctx context.Context, input RecallInput) (*RecallResponse, error) {
+	if err := m.record("recall"); err != nil {
+		return nil, err
+	}
+	creator := "alice"
+	return &RecallResponse{
+		Memories: []RecalledMemory{{
+			ID:        "mem-1",
+			Tags:      []string{"pref"},
+			Metadata:  map[string]interface{}{"nested": map[string]interface{}{"k": "v"}},
+			CreatedBy: &creator,
+		}},
+		Total: 1,
+	}, nil
+}
+
+// ---------------------------------------------------------------------------
+// TestCachedClientCopiesResponses — verifies mutating a returned response
+// does not change what later hits see.
+// ---------------------------------------------------------------------------
+
+func TestCachedClientCopiesResponses(t *testing.T) {
+	ctx := context.Background()
+	mc := NewCachedClient(&metadataRecallClient{&fakeMemoryClient{}}, time.Hour, 10)
+
+	first, err := mc.RecallContext(ctx, RecallInput{Query: "q"})
+	if err != nil {
+		t.Fatalf("RecallContext: %v", err)
+	}
+	m := &first.Memories[0]
+	m.Tags[0] = "changed"
+	m.Metadata["nested"].(map[string]interface{})["k"] = "changed"
+	*m.CreatedBy = "changed"
+
+	second, err := mc.RecallContext(ctx, RecallInput{Query: "q"})
+	if err != nil {
+		t.Fatalf("RecallContext: %v", err)
+	}
+	got := second.Memories[0]
+	if got.Tags[0] != "pref" {
+		t.Errorf("Tags[0] = %q, want %q", got.Tags[0], "pref")
+	}
+	if v := got.Metadata["nested"].(map[string]interface{})["k"]; v != "v" {
+		t.Errorf("Metadata nested k = %v, want %q", v, "v")
+	}
+	if *got.CreatedBy != "alice" {
+		t.Errorf("CreatedBy = %q, want %q", *got.CreatedBy, "alice")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallCacheKeyClientFields — verifies client-side-only RecallInput
+// fields change the cache key.
+// ---------------------------------------------------------------------------
+
+func TestRecallCacheKeyClientFields(t *testing.T) {
+	threshold := float32(0.9)
+	fallback := "bm25"
+	tests := []struct {
+		name  string
+		input RecallInput
+	}{
+		{"DeduplicateThreshold", RecallInput{Query: "q", DeduplicateThreshold: &threshold}},
+		{"FallbackStrategy", RecallInput{Query: "q", FallbackStrategy: &fallback}},
+	}
+
+	base, err := recallCacheKey(RecallInput{Query: "q"})
+	if err != nil {
+		t.Fatalf("recallCacheKey: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := recallCacheKey(tt.input)
+			if err != nil {
+				t.Fatalf("recallCacheKey: %v", err)
+			}
+			if key == base {
+				t.Errorf("key with %s matches key without it", tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package mnemo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitBreaker configures the failure handling of an InstrumentedClient.
+type CircuitBreaker struct {
+	// Threshold is the number of consecutive failed calls that opens the
+	// circuit. Values below 1 are treated as 1. Only calls that may not have
+	// reached the server, or that timed out, count as failed; an error
+	// returned by the server shows it is up, and resets the count.
+	Threshold int
+
+	// ResetAfter is how long the circuit stays open before a single probe
+	// call is allowed through (the half-open state).
+	ResetAfter time.Duration
+}
+
+// CircuitOpenError is returned by InstrumentedClient while the circuit is
+// open. No request reaches the wrapped client.
+type CircuitOpenError struct {
+	// Failures is the number of consecutive failures that opened the circuit.
+	Failures int
+
+	// RetryAt is when the circuit will allow a probe call.
+	RetryAt time.Time
+}
+
+// Error implements the error interface.
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("mnemo: circuit open after %d consecutive failures, retry at %s",
+		e.Failures, e.RetryAt.Format(time.RFC3339))
+}
+
+// circuitState is the state of an InstrumentedClient's breaker.
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// InstrumentedClient wraps a MemoryClient with a circuit breaker. After
+// Threshold consecutive failures every call fails fast with
+// *CircuitOpenError until ResetAfter has elapsed; then one probe call is let
+// through, and its outcome closes or re-opens the circuit.
+//
+// InstrumentedClient is safe for concurrent use.
+type InstrumentedClient struct {
+	inner   MemoryClient
+	breaker CircuitBreaker
+	now     func() time.Time
+
+	mu       sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+}
+
+// NewInstrumentedClient wraps inner with the given circuit breaker.
+func NewInstrumentedClient(inner MemoryClient, breaker CircuitBreaker) *InstrumentedClient {
+	if breaker.Threshold < 1 {
+		breaker.Threshold = 1
+	}
+	return &InstrumentedClient{
+		inner:   inner,
+		breaker: breaker,
+		now:     time.Now,
+	}
+}
+
+// RememberContext calls the wrapped client's RememberContext through the
+// breaker.
+func (ic *InstrumentedClient) RememberContext(ctx context.Context, input RememberInput) (*RememberResponse, error) {
+	return guard(ic, func() (*RememberResponse, error) { return ic.inner.RememberContext(ctx, input) })
+}
+
+// RecallContext calls the wrapped client's RecallContext through the breaker.
+func (ic *InstrumentedClient) RecallContext(ctx context.Context, input RecallInput) (*RecallResponse, error) {
+	return guard(ic, func() (*RecallResponse, error) { return ic.inner.RecallContext(ctx, input) })
+}
+
+// ForgetContext calls the wrapped client's ForgetContext through the breaker.
+func (ic *InstrumentedClient) ForgetContext(ctx context.Context, input ForgetInput) (*ForgetResponse, error) {
+	return guard(ic, func() (*ForgetResponse, error) { return ic.inner.ForgetContext(ctx, input) })
+}
+
+// ShareContext calls the wrapped client's ShareContext through the breaker.
+func (ic *InstrumentedClient) ShareContext(ctx context.Context, input ShareInput) (*ShareResponse, error) {
+	return guard(ic, func() (*ShareResponse, error) { return ic.inner.ShareContext(ctx, input) })
+}
+
+// CheckpointContext calls the wrapped client's CheckpointContext through the
+// breaker.
+func (ic *InstrumentedClient) CheckpointContext(ctx context.Context, input CheckpointInput) (*CheckpointResponse, error) {
+	return guard(ic, func() (*CheckpointResponse, error) { return ic.inner.CheckpointContext(ctx, input) })
+}
+
+// BranchContext calls the wrapped client's BranchContext through the breaker.
+func (ic *InstrumentedClient) BranchContext(ctx context.Context, input BranchInput) (*BranchResponse, error) {
+	return guard(ic, func() (*BranchResponse, error) { return ic.inner.BranchContext(ctx, input) })
+}
+
+// MergeContext calls the wrapped client's MergeContext through the breaker.
+func (ic *InstrumentedClient) MergeContext(ctx context.Context, input MergeInput) (*MergeResponse, error) {
+	return guard(ic, func() (*MergeResponse, error) { return ic.inner.MergeContext(ctx, input) })
+}
+
+// ReplayContext calls the wrapped client's ReplayContext through the breaker.
+func (ic *InstrumentedClient) ReplayContext(ctx context.Context, input ReplayInput) (*ReplayResponse, error) {
+	return guard(ic, func() (*ReplayResponse, error) { return ic.inner.ReplayContext(ctx, input) })
+}
+
+// VerifyContext calls the wrapped client's VerifyContext through the breaker.
+func (ic *InstrumentedClient) VerifyContext(ctx context.Context, input VerifyInput) (*VerifyResponse, error) {
+	return guard(ic, func() (*VerifyResponse, error) { return ic.inner.VerifyContext(ctx, input) })
+}
+
+// DelegateContext calls the wrapped client's DelegateContext through the
+// breaker.
+func (ic *InstrumentedClient) DelegateContext(ctx context.Context, input DelegateInput) (*DelegateResponse, error) {
+	return guard(ic, func() (*DelegateResponse, error) { return ic.inner.DelegateContext(ctx, input) })
+}
+
+// guard runs call if the breaker admits it and records the outcome.
+func guard[T any](ic *InstrumentedClient, call func() (T, error)) (T, error) {
+	if err := ic.admit(); err != nil {
+		var zero T
+		return zero, err
+	}
+	resp, err := call()
+	ic.record(err)
+	return resp, err
+}
+
+// admit reports whether a call may proceed, moving an open circuit to
+// half-open once ResetAfter has elapsed.
+func (ic *InstrumentedClient) admit() error {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	switch ic.state {
+	case circuitOpen:
+		retryAt := ic.openedAt.Add(ic.breaker.ResetAfter)
+		if ic.now().Before(retryAt) {
+			return &CircuitOpenError{Failures: ic.failures, RetryAt: retryAt}
+		}
+		ic.state = circuitHalfOpen
+		return nil
+	case circuitHalfOpen:
+		// A probe is already in flight; keep failing fast until it reports.
+		return &CircuitOpenError{Failures: ic.failures, RetryAt: ic.openedAt.Add(ic.breaker.ResetAfter)}
+	default:
+		return nil
+	}
+}
+
+// record updates the breaker with the outcome of an admitted call.
+func (ic *InstrumentedClient) record(err error) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	var rpcErr *RPCError
+	var toolErr *ToolError
+	switch {
+	case err == nil, errors.As(err, &rpcErr), errors.As(err, &toolErr):
+		// The server answered.
+		ic.state = circuitClosed
+		ic.failures = 0
+		return
+	case !isTransportError(err) && !errors.Is(err, context.DeadlineExceeded):
+		// The call was rejected or abandoned on the client side, which says
+		// nothing about the server. A probe that ends this way leaves the
+		// circuit open for the next call to probe again.
+		if ic.state == circuitHalfOpen {
+			ic.state = circuitOpen
+		}
+		return
+	}
+
+	ic.failures++
+	if ic.state == circuitHalfOpen || ic.failures >= ic.breaker.Threshold {
+		ic.state = circuitOpen
+		ic.openedAt = ic.now()
+	}
+}
@@ -0,0 +1,123 @@
+package mnemo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Compile-time checks that both implementations satisfy MemoryClient.
+var (
+	_ MemoryClient = (*Client)(nil)
+	_ MemoryClient = (*InstrumentedClient)(nil)
+)
+
+// ---------------------------------------------------------------------------
+// TestCircuitBreakerOpensAndRecovers — verifies open, fail-fast, and reset.
+// ---------------------------------------------------------------------------
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeMemoryClient{err: errors.New("process crashed")}
+	ic := NewInstrumentedClient(fake, CircuitBreaker{Threshold: 3, ResetAfter: time.Minute})
+
+	clock := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ic.now = func() time.Time { return clock }
+
+	for i := 0; i < 3; i++ {
+		if _, err := ic.RecallContext(ctx, RecallInput{Query: "q"}); err == nil {
+			t.Fatalf("call %d: expected inner error, got nil", i)
+		}
+	}
+	if got := fake.count("recall"); got != 3 {
+		t.Fatalf("inner recall calls = %d, want 3", got)
+	}
+
+	// The circuit is now open: calls fail fast without reaching inner.
+	_, err := ic.RememberContext(ctx, RememberInput{Content: "x"})
+	var open *CircuitOpenError
+	if !errors.As(err, &open) {
+		t.Fatalf("RememberContext() = %v, want *CircuitOpenError", err)
+	}
+	if open.Failures != 3 {
+		t.Errorf("Failures = %d, want 3", open.Failures)
+	}
+	if got := fake.count("remember"); got != 0 {
+		t.Errorf("inner remember calls = %d, want 0 while open", got)
+	}
+
+	// After ResetAfter, a single successful probe closes the circuit.
+	fake.setErr(nil)
+	clock = clock.Add(time.Minute + time.Second)
+
+	if _, err := ic.RememberContext(ctx, RememberInput{Content: "x"}); err != nil {
+		t.Fatalf("probe RememberContext() = %v, want nil", err)
+	}
+	if _, err := ic.RecallContext(ctx, RecallInput{Query: "q"}); err != nil {
+		t.Fatalf("RecallContext() after recovery = %v, want nil", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCircuitBreakerFailedProbeReopens — verifies a failed probe re-opens.
+// ---------------------------------------------------------------------------
+
+func TestCircuitBreakerFailedProbeReopens(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeMemoryClient{err: errors.New("still down")}
+	ic := NewInstrumentedClient(fake, CircuitBreaker{Threshold: 1, ResetAfter: time.Second})
+
+	clock := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ic.now = func() time.Time { return clock }
+
+	_, _ = ic.VerifyContext(ctx, VerifyInput{})
+
+	clock = clock.Add(2 * time.Second)
+	if _, err := ic.VerifyContext(ctx, VerifyInput{}); err == nil || errors.As(err, new(*CircuitOpenError)) {
+		t.Fatalf("probe VerifyContext() = %v, want inner error", err)
+	}
+
+	_, err := ic.VerifyContext(ctx, VerifyInput{})
+	if !errors.As(err, new(*CircuitOpenError)) {
+		t.Fatalf("VerifyContext() after failed probe = %v, want *CircuitOpenError", err)
+	}
+	if got := fake.count("verify"); got != 2 {
+		t.Errorf("inner verify calls = %d, want 2", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCircuitBreakerIgnoresServerErrors — verifies only transport failures
+// and timeouts count toward opening the circuit.
+// ---------------------------------------------------------------------------
+
+func TestCircuitBreakerIgnoresServerErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		opens bool
+	}{
+		{"rpc error", &RPCError{Code: CodeMethodNotFound, Message: "no such tool"}, false},
+		{"tool error", &ToolError{Message: "not found: memory mem-1"}, false},
+		{"validation error", &ValidationError{Field: "query", Message: "is required"}, false},
+		{"canceled", context.Canceled, false},
+		{"timeout", context.DeadlineExceeded, true},
+		{"transport", errors.New("write to stdin: broken pipe"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			fake := &fakeMemoryClient{err: tt.err}
+			ic := NewInstrumentedClient(fake, CircuitBreaker{Threshold: 2, ResetAfter: time.Minute})
+
+			for i := 0; i < 2; i++ {
+				_, _ = ic.RecallContext(ctx, RecallInput{Query: "q"})
+			}
+			_, err := ic.RecallContext(ctx, RecallInput{Query: "q"})
+			if opened := errors.As(err, new(*CircuitOpenError)); opened != tt.opens {
+				t.Errorf("circuit open after %s errors = %v, want %v", tt.name, opened, tt.opens)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package mnemo
+
+import "encoding/json"
+
+// JSONEncoder encodes JSON-RPC messages sent to the server. Marshal must
+// return a single line; the client appends the newline that delimits
+// messages.
+type JSONEncoder interface {
+	Marshal(v any) ([]byte, error)
+}
+
+// JSONDecoder decodes JSON-RPC messages and tool results received from the
+// server.
+type JSONDecoder interface {
+	Unmarshal(data []byte, v any) error
+}
+
+// marshal encodes v with ClientOptions.JSONEncoder, or encoding/json if none
+// is set.
+func (c *Client) marshal(v any) ([]byte, error) {
+	if c.opts.JSONEncoder != nil {
+		return c.opts.JSONEncoder.Marshal(v)
+	}
+	return json.Marshal(v)
+}
+
+// unmarshal decodes data into v with ClientOptions.JSONDecoder, or
+// encoding/json if none is set.
+func (c *Client) unmarshal(data []byte, v any) error {
+	if c.opts.JSONDecoder != nil {
+		return c.opts.JSONDecoder.Unmarshal(data, v)
+	}
+	return json.Unmarshal(data, v)
+}
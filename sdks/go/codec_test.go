@@ -0,0 +1,103 @@
+package mnemo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+// bomEncoder is a JSONEncoder that prepends a UTF-8 byte order mark to every
+// message and counts its calls.
+type bomEncoder struct {
+	calls int
+}
+
+func (e *bomEncoder) Marshal(v any) ([]byte, error) {
+	e.calls++
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("\xef\xbb\xbf"), data...), nil
+}
+
+// countingDecoder is a JSONDecoder that counts its calls.
+type countingDecoder struct {
+	calls int
+}
+
+func (d *countingDecoder) Unmarshal(data []byte, v any) error {
+	d.calls++
+	return json.Unmarshal(data, v)
+}
+
+// ---------------------------------------------------------------------------
+// TestCustomJSONCodec — verifies a custom encoder and decoder are used for
+// Remember.
+// ---------------------------------------------------------------------------
+
+func TestCustomJSONCodec(t *testing.T) {
+	enc := &bomEncoder{}
+	dec := &countingDecoder{}
+	c := newPipeClientWithOptions(t, ClientOptions{JSONEncoder: enc, JSONDecoder: dec},
+		func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+			return RememberResponse{ID: "mem-1", Status: "remembered"}, nil
+		})
+	var log bytes.Buffer
+	c.SetDebug(&log)
+
+	resp, err := c.Remember(RememberInput{Content: "encoded elsewhere"})
+	if err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+	if resp.ID != "mem-1" {
+		t.Errorf("ID = %q, want %q", resp.ID, "mem-1")
+	}
+	if enc.calls != 1 {
+		t.Errorf("encoder calls = %d, want 1", enc.calls)
+	}
+	// One call decodes the JSON-RPC envelope, one the tool result.
+	if dec.calls != 2 {
+		t.Errorf("decoder calls = %d, want 2", dec.calls)
+	}
+	if !bytes.Contains(log.Bytes(), []byte("\xef\xbb\xbf")) {
+		t.Error("request sent without the encoder's byte order mark")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCustomJSONCodecEverywhere — verifies compressed arguments and the
+// backup summary also go through the custom codec.
+// ---------------------------------------------------------------------------
+
+func TestCustomJSONCodecEverywhere(t *testing.T) {
+	enc := &bomEncoder{}
+	dec := &countingDecoder{}
+	c := newPipeClientWithOptions(t, ClientOptions{JSONEncoder: enc, JSONDecoder: dec, CompressMessages: true},
+		func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+			if name == "mnemo.backup" {
+				return toolContents{{Type: "text", Text: `{"record_count":0}`}}, nil
+			}
+			return RememberResponse{ID: "mem-1", Status: "remembered"}, nil
+		})
+
+	if _, err := c.Remember(RememberInput{Content: strings.Repeat("x", compressThreshold)}); err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+	// One call encodes the arguments for compression, one the request.
+	if enc.calls != 2 {
+		t.Errorf("encoder calls = %d, want 2", enc.calls)
+	}
+
+	dec.calls = 0
+	if _, err := c.Backup(context.Background(), BackupInput{Format: BackupFormatJSONArchive}, io.Discard); err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	// One call decodes the JSON-RPC envelope, one the summary.
+	if dec.calls != 2 {
+		t.Errorf("decoder calls = %d, want 2", dec.calls)
+	}
+}
@@ -0,0 +1,176 @@
+package mnemo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// compressedMetadataKey flags a memory whose content was stored compressed
+// by CompressContentThreshold.
+const compressedMetadataKey = "mnemo_compressed"
+
+// compressedContentPrefix starts the Content of a memory stored compressed,
+// so it can be restored without fetching the memory's metadata.
+const compressedContentPrefix = "mnemo+gzip:"
+
+// compressArguments replaces params.Arguments with their gzip-compressed,
+// base64-encoded JSON when it exceeds compressThreshold, and sets the
+// contentEncoding hint so the server knows to decode them.
+func (c *Client) compressArguments(params *toolCallParams) error {
+	data, err := c.marshal(params.Arguments)
+	if err != nil {
+		return err
+	}
+	if len(data) <= compressThreshold {
+		return nil
+	}
+
+	encoded, err := gzipBase64(data)
+	if err != nil {
+		return err
+	}
+	params.Arguments = encoded
+	if params.Meta == nil {
+		params.Meta = &toolCallMeta{}
+	}
+	params.Meta.ContentEncoding = contentEncodingGzip
+	return nil
+}
+
+// compressContent returns input with Content replaced by its prefixed
+// gzip+base64 encoding and the compressed flag added to a copy of Metadata.
+func compressContent(input RememberInput) (RememberInput, error) {
+	encoded, err := gzipBase64([]byte(input.Content))
+	if err != nil {
+		return input, err
+	}
+
+	metadata := make(map[string]interface{}, len(input.Metadata)+1)
+	for k, v := range input.Metadata {
+		metadata[k] = v
+	}
+	metadata[compressedMetadataKey] = true
+
+	input.Content = compressedContentPrefix + encoded
+	input.Metadata = metadata
+	return input, nil
+}
+
+// decompressContent restores the original Content of a memory stored by
+// compressContent and removes the compressed flag from its Metadata. The
+// content prefix identifies compressed content whether or not metadata was
+// fetched. Other memories are left unchanged.
+func decompressContent(m *RecalledMemory) error {
+	content, err := decompressText(m.Content)
+	if err != nil {
+		return fmt.Errorf("mnemo: decompress memory %s: %w", m.ID, err)
+	}
+	m.Content = content
+	delete(m.Metadata, compressedMetadataKey)
+	return nil
+}
+
+// decompressMemories runs decompressContent on each memory.
+func decompressMemories(memories []RecalledMemory) error {
+	for i := range memories {
+		if err := decompressContent(&memories[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decompressText returns the original text of content written by
+// compressContent, or content itself if it lacks compressedContentPrefix.
+func decompressText(content string) (string, error) {
+	encoded, ok := strings.CutPrefix(content, compressedContentPrefix)
+	if !ok {
+		return content, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return "", err
+	}
+	plain, err := io.ReadAll(zr)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// compressedSnapshot is the form CheckpointInput.Compress gives a state
+// snapshot. Encoding marks it so DecompressSnapshot can recognize it.
+type compressedSnapshot struct {
+	Encoding string `json:"mnemo_encoding"`
+	Data     string `json:"data"`
+}
+
+// compressSnapshot returns snapshot as a compressedSnapshot holding its
+// gzip+base64-encoded JSON.
+func compressSnapshot(snapshot interface{}) (compressedSnapshot, error) {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return compressedSnapshot{}, err
+	}
+	encoded, err := gzipBase64(data)
+	if err != nil {
+		return compressedSnapshot{}, err
+	}
+	return compressedSnapshot{Encoding: contentEncodingGzip, Data: encoded}, nil
+}
+
+// DecompressSnapshot returns the original state snapshot of a checkpoint
+// created with CheckpointInput.Compress, decoded as encoding/json would
+// decode it into an interface{}. Snapshots that were not compressed are
+// returned unchanged. Replay calls it already; it is needed only for
+// snapshots obtained some other way.
+func DecompressSnapshot(data interface{}) (interface{}, error) {
+	m, ok := data.(map[string]interface{})
+	if !ok || len(m) != 2 || m["mnemo_encoding"] != contentEncodingGzip {
+		return data, nil
+	}
+	encoded, ok := m["data"].(string)
+	if !ok {
+		return data, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("mnemo: decompress snapshot: %w", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("mnemo: decompress snapshot: %w", err)
+	}
+	plain, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("mnemo: decompress snapshot: %w", err)
+	}
+	var snapshot interface{}
+	if err := json.Unmarshal(plain, &snapshot); err != nil {
+		return nil, fmt.Errorf("mnemo: decompress snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// gzipBase64 returns the base64 encoding of data compressed with gzip.
+func gzipBase64(data []byte) (string, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
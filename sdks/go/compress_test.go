@@ -0,0 +1,225 @@
+package mnemo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestCompressContentRoundTrip — verifies compressed content recalls intact.
+// ---------------------------------------------------------------------------
+
+func TestCompressContentRoundTrip(t *testing.T) {
+	var stored []RecalledMemory
+	var includeMetadata bool
+	c := newPipeClientWithOptions(t, ClientOptions{CompressContentThreshold: 1024}, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		switch name {
+		case "mnemo.remember":
+			var in RememberInput
+			_ = json.Unmarshal(args, &in)
+			stored = append(stored, RecalledMemory{ID: fmt.Sprintf("mem-%d", len(stored)+1), Content: in.Content, Metadata: in.Metadata})
+			return RememberResponse{ID: stored[len(stored)-1].ID, Status: "remembered"}, nil
+		default:
+			var in RecallInput
+			_ = json.Unmarshal(args, &in)
+			includeMetadata = in.IncludeMetadata
+			return RecallResponse{Memories: stored, Total: len(stored)}, nil
+		}
+	})
+
+	large := strings.Repeat("embedding vector chunk ", 200)
+	meta := map[string]interface{}{"source": "doc"}
+	if _, err := c.Remember(RememberInput{Content: large, Metadata: meta}); err != nil {
+		t.Fatalf("Remember large: %v", err)
+	}
+	if _, err := c.Remember(RememberInput{Content: "short note"}); err != nil {
+		t.Fatalf("Remember short: %v", err)
+	}
+
+	if stored[0].Content == large || len(stored[0].Content) >= len(large) {
+		t.Error("large content was not stored compressed")
+	}
+	if _, ok := meta[compressedMetadataKey]; ok {
+		t.Error("caller's Metadata map was modified")
+	}
+	if stored[1].Content != "short note" || stored[1].Metadata != nil {
+		t.Errorf("short memory = %+v, want stored unchanged", stored[1])
+	}
+
+	resp, err := c.Recall(RecallInput{Query: "chunk"})
+	if err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if includeMetadata {
+		t.Error("include_metadata was requested although the caller did not ask for it")
+	}
+	if resp.Memories[0].Content != large {
+		t.Errorf("recalled content length = %d, want original %d", len(resp.Memories[0].Content), len(large))
+	}
+	if _, ok := resp.Memories[0].Metadata[compressedMetadataKey]; ok {
+		t.Error("compressed flag leaked into recalled Metadata")
+	}
+	if resp.Memories[0].Metadata["source"] != "doc" {
+		t.Errorf("Metadata[source] = %v, want doc", resp.Memories[0].Metadata["source"])
+	}
+	if resp.Memories[1].Content != "short note" {
+		t.Errorf("short content = %q, want %q", resp.Memories[1].Content, "short note")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestDecompressWithoutThreshold — verifies a client that does not compress
+// still restores content another client stored compressed.
+// ---------------------------------------------------------------------------
+
+func TestDecompressWithoutThreshold(t *testing.T) {
+	var opts ClientOptions
+	CompressLargeContent(1024)(&opts)
+	if opts.CompressContentThreshold != 1024 {
+		t.Fatalf("CompressContentThreshold = %d, want 1024", opts.CompressContentThreshold)
+	}
+
+	large := strings.Repeat("embedding vector chunk ", 200)
+	compressed, err := compressContent(RememberInput{Content: large})
+	if err != nil {
+		t.Fatalf("compressContent: %v", err)
+	}
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in RecallInput
+		_ = json.Unmarshal(args, &in)
+		if !in.IncludeMetadata {
+			return RecallResponse{Memories: []RecalledMemory{{ID: "mem-1", Content: compressed.Content}}, Total: 1}, nil
+		}
+		return RecallResponse{Memories: []RecalledMemory{{ID: "mem-1", Content: compressed.Content, Metadata: compressed.Metadata}}, Total: 1}, nil
+	})
+
+	resp, err := c.Recall(RecallInput{Query: "chunk", IncludeMetadata: true})
+	if err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if resp.Memories[0].Content != large {
+		t.Errorf("recalled content length = %d, want original %d", len(resp.Memories[0].Content), len(large))
+	}
+	if _, ok := resp.Memories[0].Metadata[compressedMetadataKey]; ok || resp.Memories[0].Metadata == nil {
+		t.Errorf("Metadata = %v, want requested metadata without the compressed flag", resp.Memories[0].Metadata)
+	}
+
+	resp, err = c.Recall(RecallInput{Query: "chunk"})
+	if err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if resp.Memories[0].Content != large {
+		t.Errorf("recalled content length = %d, want original %d", len(resp.Memories[0].Content), len(large))
+	}
+	if resp.Memories[0].Metadata != nil {
+		t.Errorf("Metadata = %v, want nil when not requested", resp.Memories[0].Metadata)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCompressContentReplay — verifies Replay and ConversationReplay return
+// compressed content intact.
+// ---------------------------------------------------------------------------
+
+func TestCompressContentReplay(t *testing.T) {
+	var stored []ReplayMemory
+	c := newPipeClientWithOptions(t, ClientOptions{CompressContentThreshold: 1024}, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		if name == "mnemo.remember" {
+			var in RememberInput
+			_ = json.Unmarshal(args, &in)
+			stored = append(stored, ReplayMemory{ID: "mem-1", Content: in.Content, Metadata: in.Metadata})
+			return RememberResponse{ID: "mem-1", Status: "remembered"}, nil
+		}
+		return ReplayResponse{MemoryCount: len(stored), Memories: stored, Status: "replayed"}, nil
+	})
+
+	large := strings.Repeat("conversation turn ", 200)
+	if _, err := c.Remember(RememberInput{Content: large}); err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+
+	resp, err := c.Replay(ReplayInput{ThreadID: "thread-1"})
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if resp.Memories[0].Content != large {
+		t.Errorf("replayed content length = %d, want original %d", len(resp.Memories[0].Content), len(large))
+	}
+	if _, ok := resp.Memories[0].Metadata[compressedMetadataKey]; ok {
+		t.Error("compressed flag leaked into replayed Metadata")
+	}
+
+	err = c.ConversationReplay(context.Background(), "thread-1", nil, func(m ReplayMemory) error {
+		if m.Content != large {
+			t.Errorf("ConversationReplay content length = %d, want original %d", len(m.Content), len(large))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ConversationReplay: %v", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCompressSnapshotRoundTrip — verifies a compressed checkpoint snapshot
+// replays identically to an uncompressed one.
+// ---------------------------------------------------------------------------
+
+func TestCompressSnapshotRoundTrip(t *testing.T) {
+	var stored json.RawMessage
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		switch name {
+		case "mnemo.checkpoint":
+			var in struct {
+				StateSnapshot json.RawMessage `json:"state_snapshot"`
+				Compress      bool            `json:"compress"`
+			}
+			_ = json.Unmarshal(args, &in)
+			stored = in.StateSnapshot
+			return CheckpointResponse{CheckpointID: "cp-1", BranchName: "main", Status: "created", Compressed: in.Compress}, nil
+		default:
+			return json.RawMessage(`{"checkpoint": {"id": "cp-1", "branch_name": "main", "state_snapshot": ` +
+				string(stored) + `, "created_at": "2024-06-01T12:00:00Z"}, "status": "replayed"}`), nil
+		}
+	})
+
+	history := make([]interface{}, 0, 200)
+	for i := 0; i < 200; i++ {
+		history = append(history, map[string]interface{}{"turn": float64(i), "text": fmt.Sprintf("message %03d %s", i, strings.Repeat("x", 32))})
+	}
+	snapshot := map[string]interface{}{"history": history, "mood": "curious"}
+	plain, _ := json.Marshal(snapshot)
+	if len(plain) < 10*1024 {
+		t.Fatalf("snapshot is %d bytes, want at least 10 KB", len(plain))
+	}
+
+	cp, err := c.Checkpoint(CheckpointInput{ThreadID: "thread-1", StateSnapshot: snapshot, Compress: true})
+	if err != nil {
+		t.Fatalf("Checkpoint: %v", err)
+	}
+	if !cp.Compressed {
+		t.Error("Compressed = false, want true")
+	}
+	if len(stored) >= len(plain) {
+		t.Errorf("stored snapshot is %d bytes, want fewer than the original %d", len(stored), len(plain))
+	}
+
+	replay, err := c.Replay(ReplayInput{ThreadID: "thread-1"})
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if !reflect.DeepEqual(replay.Checkpoint.StateSnapshot, snapshot) {
+		t.Error("replayed snapshot differs from the original")
+	}
+
+	var generic interface{}
+	_ = json.Unmarshal(plain, &generic)
+	same, err := DecompressSnapshot(generic)
+	if err != nil || !reflect.DeepEqual(same, generic) {
+		t.Errorf("DecompressSnapshot(uncompressed) = %v, want it unchanged", err)
+	}
+}
@@ -0,0 +1,150 @@
+package mnemo
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"reflect"
+)
+
+// agentIDKey, orgIDKey, threadIDKey, and personaKey are the context keys set
+// by WithAgentID, WithOrgID, WithThreadID, and WithPersona.
+type (
+	agentIDKey  struct{}
+	orgIDKey    struct{}
+	threadIDKey struct{}
+	personaKey  struct{}
+)
+
+// correlationIDKey is the context key set by WithCorrelationID.
+type correlationIDKey struct{}
+
+// contextField pairs a context key with the input struct field it fills.
+// If tools is non-nil, the field is filled only for those tools.
+type contextField struct {
+	key   interface{}
+	field string
+	tools map[string]struct{}
+}
+
+// contextFields lists the input fields that callTool fills from the context.
+var contextFields = []contextField{
+	{agentIDKey{}, "AgentID", nil},
+	{orgIDKey{}, "OrgID", nil},
+	{threadIDKey{}, "ThreadID", map[string]struct{}{
+		"mnemo.remember":         {},
+		"mnemo.recall":           {},
+		"mnemo.checkpoint":       {},
+		"mnemo.branch":           {},
+		"mnemo.merge":            {},
+		"mnemo.replay":           {},
+		"mnemo.summarize_thread": {},
+	}},
+	{personaKey{}, "PersonaID", map[string]struct{}{
+		"mnemo.remember": {},
+		"mnemo.recall":   {},
+	}},
+}
+
+// WithAgentID returns a copy of ctx carrying id as the agent identifier.
+// Calls made with the returned context use id for any input whose AgentID
+// is unset, so it need not be repeated on every input struct.
+func WithAgentID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, agentIDKey{}, id)
+}
+
+// WithOrgID returns a copy of ctx carrying id as the organization
+// identifier, used for any input whose OrgID is unset.
+func WithOrgID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, orgIDKey{}, id)
+}
+
+// WithThreadID returns a copy of ctx carrying id as the conversation thread.
+// Remember, Recall, Checkpoint, Branch, Merge, and Replay use it when their
+// input's ThreadID is unset, which suits agents that work within a single
+// thread for the lifetime of a context.
+func WithThreadID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, threadIDKey{}, id)
+}
+
+// WithPersona returns a copy of ctx carrying id as the persona the agent is
+// acting as. Remember tags new memories with it and Recall searches only
+// that persona's memories, when the input's PersonaID is unset.
+func WithPersona(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, personaKey{}, id)
+}
+
+// WithCorrelationID returns a copy of ctx carrying id as the correlation ID.
+// Every call made with the returned context sends id in the request's _meta
+// as "x-correlation-id", so a single operation can be traced through the
+// SDK, the server, and any logs in between. Calls whose context has no
+// correlation ID get a fresh random UUID.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID carried by ctx, or ""
+// if there is none. Interceptors see the ID generated for the call.
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// newCorrelationID returns a random (version 4) UUID.
+func newCorrelationID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("mnemo: correlation ID: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// applyContextFields returns the arguments of tool with every unset field
+// listed in contextFields filled from ctx. args is returned unchanged if it
+// is not a struct or the context carries nothing to apply; otherwise a
+// modified copy is returned and the caller's value is left alone.
+func applyContextFields(ctx context.Context, tool string, args interface{}) interface{} {
+	v := reflect.ValueOf(args)
+	if v.Kind() != reflect.Struct {
+		return args
+	}
+
+	var out reflect.Value
+	for _, cf := range contextFields {
+		if cf.tools != nil {
+			if _, ok := cf.tools[tool]; !ok {
+				continue
+			}
+		}
+		id, _ := ctx.Value(cf.key).(string)
+		if id == "" {
+			continue
+		}
+		if !out.IsValid() {
+			out = reflect.New(v.Type()).Elem()
+			out.Set(v)
+		}
+		setIfEmpty(out.FieldByName(cf.field), id)
+	}
+
+	if !out.IsValid() {
+		return args
+	}
+	return out.Interface()
+}
+
+// setIfEmpty stores id in f if f is an empty string or nil *string field.
+// Missing or differently typed fields are ignored.
+func setIfEmpty(f reflect.Value, id string) {
+	if !f.IsValid() || !f.CanSet() {
+		return
+	}
+	switch {
+	case f.Kind() == reflect.String && f.String() == "":
+		f.SetString(id)
+	case f.Kind() == reflect.Ptr && f.Type().Elem().Kind() == reflect.String && f.IsNil():
+		f.Set(reflect.ValueOf(&id))
+	}
+}
@@ -0,0 +1,205 @@
+package mnemo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestContextAgentID — verifies context-carried IDs reach the request JSON.
+// ---------------------------------------------------------------------------
+
+func TestContextAgentID(t *testing.T) {
+	var gotArgs map[string]interface{}
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		gotArgs = nil
+		_ = json.Unmarshal(args, &gotArgs)
+		return RememberResponse{ID: "mem-1", Status: "remembered"}, nil
+	})
+
+	ctx := WithOrgID(WithAgentID(context.Background(), "ctx-agent"), "ctx-org")
+	if _, err := c.RememberContext(ctx, RememberInput{Content: "hello"}); err != nil {
+		t.Fatalf("RememberContext: %v", err)
+	}
+	if gotArgs["agent_id"] != "ctx-agent" {
+		t.Errorf("agent_id = %v, want %q", gotArgs["agent_id"], "ctx-agent")
+	}
+	if gotArgs["org_id"] != "ctx-org" {
+		t.Errorf("org_id = %v, want %q", gotArgs["org_id"], "ctx-org")
+	}
+
+	explicit := "input-agent"
+	input := RememberInput{Content: "hello", AgentID: &explicit}
+	if _, err := c.RememberContext(ctx, input); err != nil {
+		t.Fatalf("RememberContext: %v", err)
+	}
+	if gotArgs["agent_id"] != "input-agent" {
+		t.Errorf("agent_id = %v, want explicit %q to win", gotArgs["agent_id"], "input-agent")
+	}
+
+	if _, err := c.Remember(RememberInput{Content: "hello"}); err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+	if _, ok := gotArgs["agent_id"]; ok {
+		t.Errorf("agent_id = %v, want omitted without a context value", gotArgs["agent_id"])
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestApplyContextFieldsCopies — verifies the caller's input is not mutated.
+// ---------------------------------------------------------------------------
+
+func TestApplyContextFieldsCopies(t *testing.T) {
+	ctx := WithAgentID(context.Background(), "ctx-agent")
+	input := RecallInput{Query: "q"}
+
+	out, ok := applyContextFields(ctx, "mnemo.recall", input).(RecallInput)
+	if !ok {
+		t.Fatalf("applyContextFields returned %T, want RecallInput", out)
+	}
+	if out.AgentID == nil || *out.AgentID != "ctx-agent" {
+		t.Errorf("AgentID = %v, want ctx-agent", out.AgentID)
+	}
+	if input.AgentID != nil {
+		t.Error("caller's input was modified")
+	}
+
+	if got := applyContextFields(ctx, "mnemo.recall", "not a struct"); got != "not a struct" {
+		t.Errorf("applyContextFields(string) = %v, want unchanged", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestContextThreadID — verifies a context thread ID fills thread-scoped tools.
+// ---------------------------------------------------------------------------
+
+func TestContextThreadID(t *testing.T) {
+	var gotArgs map[string]interface{}
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		gotArgs = nil
+		_ = json.Unmarshal(args, &gotArgs)
+		return map[string]interface{}{"status": "ok"}, nil
+	})
+
+	ctx := WithThreadID(context.Background(), "thread-ctx")
+	if _, err := c.RememberContext(ctx, RememberInput{Content: "in thread"}); err != nil {
+		t.Fatalf("RememberContext: %v", err)
+	}
+	if gotArgs["thread_id"] != "thread-ctx" {
+		t.Errorf("remember thread_id = %v, want %q", gotArgs["thread_id"], "thread-ctx")
+	}
+
+	if _, err := c.CheckpointContext(ctx, CheckpointInput{StateSnapshot: map[string]interface{}{}}); err != nil {
+		t.Fatalf("CheckpointContext: %v", err)
+	}
+	if gotArgs["thread_id"] != "thread-ctx" {
+		t.Errorf("checkpoint thread_id = %v, want %q", gotArgs["thread_id"], "thread-ctx")
+	}
+
+	if _, err := c.ReplayContext(ctx, ReplayInput{ThreadID: "thread-explicit"}); err != nil {
+		t.Fatalf("ReplayContext: %v", err)
+	}
+	if gotArgs["thread_id"] != "thread-explicit" {
+		t.Errorf("replay thread_id = %v, want explicit value to win", gotArgs["thread_id"])
+	}
+
+	if _, err := c.VerifyContext(ctx, VerifyInput{}); err != nil {
+		t.Fatalf("VerifyContext: %v", err)
+	}
+	if _, ok := gotArgs["thread_id"]; ok {
+		t.Errorf("verify thread_id = %v, want it left unset", gotArgs["thread_id"])
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCorrelationID — verifies correlation IDs round-trip through a real
+// child process and are generated when absent.
+// ---------------------------------------------------------------------------
+
+func TestCorrelationID(t *testing.T) {
+	c := newProcessClient(t)
+	defer c.Close()
+
+	var seen string
+	c.Use(func(ctx context.Context, method string, req, resp interface{}, next Handler) error {
+		seen = CorrelationIDFromContext(ctx)
+		return next(ctx, req, resp)
+	})
+	var log bytes.Buffer
+	c.SetDebug(&log)
+	var warned error
+	c.opts.OnWarning = func(err error) { warned = err }
+
+	ctx := WithCorrelationID(context.Background(), "trace-1234")
+	if _, err := c.RememberContext(ctx, RememberInput{Content: "traced"}); err != nil {
+		t.Fatalf("RememberContext: %v", err)
+	}
+	if seen != "trace-1234" {
+		t.Errorf("interceptor correlation ID = %q, want %q", seen, "trace-1234")
+	}
+	// The ID appears once in the request and once in the echoed response.
+	if n := strings.Count(log.String(), `"x-correlation-id":"trace-1234"`); n != 2 {
+		t.Errorf("correlation ID logged %d times, want 2:\n%s", n, log.String())
+	}
+	if warned != nil {
+		t.Errorf("unexpected warning: %v", warned)
+	}
+
+	if _, err := c.Recall(RecallInput{Query: "traced"}); err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(seen) {
+		t.Errorf("generated correlation ID = %q, want a version 4 UUID", seen)
+	}
+	if !strings.Contains(log.String(), seen) {
+		t.Errorf("generated correlation ID %q not sent", seen)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestPersona — verifies PersonaID is sent from the input or the context and
+// omitted otherwise.
+// ---------------------------------------------------------------------------
+
+func TestPersona(t *testing.T) {
+	var gotArgs map[string]interface{}
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		gotArgs = nil
+		_ = json.Unmarshal(args, &gotArgs)
+		return map[string]interface{}{"status": "ok"}, nil
+	})
+
+	if _, err := c.Recall(RecallInput{Query: "anyone"}); err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if _, ok := gotArgs["persona_id"]; ok {
+		t.Errorf("persona_id = %v, want it omitted when nil", gotArgs["persona_id"])
+	}
+
+	persona := "support-bot"
+	if _, err := c.Remember(RememberInput{Content: "refund policy", PersonaID: &persona}); err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+	if gotArgs["persona_id"] != "support-bot" {
+		t.Errorf("remember persona_id = %v, want %q", gotArgs["persona_id"], "support-bot")
+	}
+
+	ctx := WithPersona(context.Background(), "sales-bot")
+	if _, err := c.RecallContext(ctx, RecallInput{Query: "pricing"}); err != nil {
+		t.Fatalf("RecallContext: %v", err)
+	}
+	if gotArgs["persona_id"] != "sales-bot" {
+		t.Errorf("recall persona_id = %v, want %q from context", gotArgs["persona_id"], "sales-bot")
+	}
+
+	if _, err := c.VerifyContext(ctx, VerifyInput{}); err != nil {
+		t.Fatalf("VerifyContext: %v", err)
+	}
+	if _, ok := gotArgs["persona_id"]; ok {
+		t.Errorf("verify persona_id = %v, want it left unset", gotArgs["persona_id"])
+	}
+}
@@ -0,0 +1,76 @@
+package mnemo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// redactedValue replaces the value of every key listed in
+// ClientOptions.RedactKeys in debug output.
+const redactedValue = "[REDACTED]"
+
+// SetDebug writes every raw JSON-RPC message exchanged with the mnemo
+// process to w, one line per message: outgoing messages are prefixed with
+// "→" and incoming ones with "←". Values of keys listed in
+// ClientOptions.RedactKeys are masked. Pass nil to stop logging.
+func (c *Client) SetDebug(w io.Writer) {
+	c.debugMu.Lock()
+	defer c.debugMu.Unlock()
+	c.debug = w
+}
+
+// logDebug writes one message to the debug writer, if any. It is safe to
+// call from any goroutine.
+func (c *Client) logDebug(prefix string, msg []byte) {
+	c.debugMu.Lock()
+	defer c.debugMu.Unlock()
+	if c.debug == nil {
+		return
+	}
+	if len(c.opts.RedactKeys) > 0 {
+		msg = redactJSON(msg, c.opts.RedactKeys)
+	}
+	fmt.Fprintf(c.debug, "%s %s\n", prefix, msg)
+}
+
+// redactJSON returns msg with the values of the given keys replaced. A
+// message that is not valid JSON is returned unchanged.
+func redactJSON(msg []byte, keys []string) []byte {
+	var v interface{}
+	if err := json.Unmarshal(msg, &v); err != nil {
+		return msg
+	}
+
+	redact := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		redact[strings.ToLower(k)] = struct{}{}
+	}
+	redactValue(v, redact)
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return msg
+	}
+	return out
+}
+
+// redactValue masks matching keys in v in place, descending into nested
+// objects and arrays.
+func redactValue(v interface{}, redact map[string]struct{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if _, ok := redact[strings.ToLower(k)]; ok {
+				v[k] = redactedValue
+				continue
+			}
+			redactValue(child, redact)
+		}
+	case []interface{}:
+		for _, child := range v {
+			redactValue(child, redact)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package mnemo
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestSetDebug — verifies raw traffic is logged with direction prefixes.
+// ---------------------------------------------------------------------------
+
+func TestSetDebug(t *testing.T) {
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		return RecallResponse{Total: 0}, nil
+	})
+
+	var buf bytes.Buffer
+	c.SetDebug(&buf)
+	if _, err := c.Recall(RecallInput{Query: "debug me"}); err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d debug lines, want 2:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "→ ") || !strings.Contains(lines[0], "mnemo.recall") {
+		t.Errorf("outgoing line = %q, want → prefix and method name", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "← ") {
+		t.Errorf("incoming line = %q, want ← prefix", lines[1])
+	}
+
+	buf.Reset()
+	c.SetDebug(nil)
+	if _, err := c.Recall(RecallInput{Query: "quiet"}); err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("debug output after SetDebug(nil) = %q, want empty", buf.String())
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestSetDebugRedactKeys — verifies listed keys are masked in debug output.
+// ---------------------------------------------------------------------------
+
+func TestSetDebugRedactKeys(t *testing.T) {
+	const secret = "sk-test-0123456789"
+	c := newPipeClientWithOptions(t, ClientOptions{RedactKeys: []string{"openai_api_key"}}, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		return RememberResponse{ID: "mem-1", Status: "remembered"}, nil
+	})
+
+	var buf bytes.Buffer
+	c.SetDebug(&buf)
+	input := RememberInput{
+		Content:  "config note",
+		Metadata: map[string]interface{}{"OpenAI_API_Key": secret, "model": "small"},
+	}
+	if _, err := c.Remember(input); err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, secret) {
+		t.Errorf("debug output leaks the key:\n%s", out)
+	}
+	if !strings.Contains(out, redactedValue) {
+		t.Errorf("debug output missing %q:\n%s", redactedValue, out)
+	}
+	if !strings.Contains(out, "small") {
+		t.Errorf("debug output should keep unlisted fields:\n%s", out)
+	}
+}
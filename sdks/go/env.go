@@ -0,0 +1,69 @@
+package mnemo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Environment variables read by NewClientFromEnv.
+const (
+	EnvCommand     = "MNEMO_COMMAND"
+	EnvDbPath      = "MNEMO_DB_PATH"
+	EnvAgentID     = "MNEMO_AGENT_ID"
+	EnvOrgID       = "MNEMO_ORG_ID"
+	EnvOpenAIKey   = "MNEMO_OPENAI_API_KEY"
+	EnvDimensions  = "MNEMO_DIMENSIONS"
+	EnvPostgresURL = "MNEMO_POSTGRES_URL"
+	EnvLogLevel    = "MNEMO_LOG_LEVEL"
+
+	// EnvNoTelemetry sets ClientOptions.DisableTelemetry. It accepts the
+	// values understood by strconv.ParseBool.
+	EnvNoTelemetry = "MNEMO_NO_TELEMETRY"
+)
+
+// NewClientFromEnv is like NewClient but builds ClientOptions from the
+// MNEMO_* environment variables (see the Env* constants). At least one of
+// MNEMO_DB_PATH and MNEMO_POSTGRES_URL must be set.
+func NewClientFromEnv(options ...Option) (*Client, error) {
+	opts, err := optionsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(opts, options...)
+}
+
+// optionsFromEnv reads ClientOptions from the environment.
+func optionsFromEnv() (ClientOptions, error) {
+	opts := ClientOptions{
+		Command:     os.Getenv(EnvCommand),
+		DbPath:      os.Getenv(EnvDbPath),
+		AgentID:     os.Getenv(EnvAgentID),
+		OrgID:       os.Getenv(EnvOrgID),
+		OpenAIKey:   os.Getenv(EnvOpenAIKey),
+		PostgresURL: os.Getenv(EnvPostgresURL),
+		LogLevel:    os.Getenv(EnvLogLevel),
+	}
+
+	if v := os.Getenv(EnvDimensions); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return ClientOptions{}, fmt.Errorf("mnemo: %s must be a positive integer, got %q", EnvDimensions, v)
+		}
+		opts.Dimensions = n
+	}
+
+	if v := os.Getenv(EnvNoTelemetry); v != "" {
+		disable, err := strconv.ParseBool(v)
+		if err != nil {
+			return ClientOptions{}, fmt.Errorf("mnemo: %s must be a boolean, got %q", EnvNoTelemetry, v)
+		}
+		opts.DisableTelemetry = disable
+	}
+
+	if opts.DbPath == "" && opts.PostgresURL == "" {
+		return ClientOptions{}, errors.New("mnemo: one of " + EnvDbPath + " or " + EnvPostgresURL + " must be set")
+	}
+	return opts, nil
+}
@@ -0,0 +1,96 @@
+package mnemo
+
+import "testing"
+
+// ---------------------------------------------------------------------------
+// TestOptionsFromEnv — verifies ClientOptions are read from MNEMO_* vars.
+// ---------------------------------------------------------------------------
+
+func TestOptionsFromEnv(t *testing.T) {
+	t.Setenv(EnvCommand, "/usr/local/bin/mnemo")
+	t.Setenv(EnvDbPath, "/data/agent.db")
+	t.Setenv(EnvAgentID, "agent-env")
+	t.Setenv(EnvOrgID, "org-env")
+	t.Setenv(EnvOpenAIKey, "sk-env")
+	t.Setenv(EnvDimensions, "768")
+	t.Setenv(EnvPostgresURL, "")
+	t.Setenv(EnvLogLevel, "debug")
+
+	opts, err := optionsFromEnv()
+	if err != nil {
+		t.Fatalf("optionsFromEnv: %v", err)
+	}
+	want := ClientOptions{
+		Command:    "/usr/local/bin/mnemo",
+		DbPath:     "/data/agent.db",
+		AgentID:    "agent-env",
+		OrgID:      "org-env",
+		OpenAIKey:  "sk-env",
+		Dimensions: 768,
+		LogLevel:   "debug",
+	}
+	if opts.Command != want.Command || opts.DbPath != want.DbPath || opts.AgentID != want.AgentID ||
+		opts.OrgID != want.OrgID || opts.OpenAIKey != want.OpenAIKey ||
+		opts.Dimensions != want.Dimensions || opts.LogLevel != want.LogLevel {
+		t.Errorf("optionsFromEnv() = %+v, want %+v", opts, want)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestOptionsFromEnvErrors — verifies missing storage and bad dimensions fail.
+// ---------------------------------------------------------------------------
+
+func TestOptionsFromEnvErrors(t *testing.T) {
+	t.Setenv(EnvDbPath, "")
+	t.Setenv(EnvPostgresURL, "")
+	t.Setenv(EnvDimensions, "")
+	if _, err := NewClientFromEnv(); err == nil {
+		t.Error("NewClientFromEnv without storage: expected error, got nil")
+	}
+
+	t.Setenv(EnvPostgresURL, "postgres://localhost/mnemo")
+	opts, err := optionsFromEnv()
+	if err != nil {
+		t.Fatalf("optionsFromEnv with only PostgresURL: %v", err)
+	}
+	if opts.PostgresURL != "postgres://localhost/mnemo" {
+		t.Errorf("PostgresURL = %q, want postgres://localhost/mnemo", opts.PostgresURL)
+	}
+
+	t.Setenv(EnvDimensions, "many")
+	if _, err := optionsFromEnv(); err == nil {
+		t.Error("optionsFromEnv with non-numeric dimensions: expected error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestOptionsFromEnvNoTelemetry — verifies MNEMO_NO_TELEMETRY reaches the
+// command line.
+// ---------------------------------------------------------------------------
+
+func TestOptionsFromEnvNoTelemetry(t *testing.T) {
+	t.Setenv(EnvDbPath, "/data/agent.db")
+	t.Setenv(EnvPostgresURL, "")
+	t.Setenv(EnvDimensions, "")
+	t.Setenv(EnvNoTelemetry, "1")
+
+	opts, err := optionsFromEnv()
+	if err != nil {
+		t.Fatalf("optionsFromEnv: %v", err)
+	}
+	if !opts.DisableTelemetry {
+		t.Error("DisableTelemetry = false, want true")
+	}
+	args, err := buildArgs(opts)
+	if err != nil {
+		t.Fatalf("buildArgs: %v", err)
+	}
+	if args[len(args)-1] != "--no-telemetry" {
+		t.Errorf("buildArgs() = %v, want --no-telemetry", args)
+	}
+
+	t.Setenv(EnvNoTelemetry, "sometimes")
+	if _, err := NewClientFromEnv(); err == nil {
+		t.Error("NewClientFromEnv with invalid MNEMO_NO_TELEMETRY: expected error, got nil")
+	}
+}
@@ -0,0 +1,307 @@
+package mnemo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ErrStdinWriteTimeout is returned when a write to the mnemo process stdin
+// exceeds ClientOptions.StdinWriteTimeout. The process is killed, or the
+// DialFunc connection closed, when this happens, so the client must be closed
+// and recreated.
+var ErrStdinWriteTimeout = errors.New("mnemo: timed out writing to process stdin")
+
+// Sentinel errors for use with errors.Is. Errors reported by the mnemo
+// server wrap one of these when they are recognized; see RPCError and
+// ToolError.
+var (
+	// ErrClientClosed is returned by calls made after Close, or after
+	// GracefulClose has started draining the client.
+	ErrClientClosed = errors.New("mnemo: client closed")
+
+	// ErrNotFound reports that a memory, checkpoint, or branch does not exist.
+	ErrNotFound = errors.New("mnemo: not found")
+
+	// ErrMethodNotFound reports that the server does not offer the method or
+	// tool called, or does not expose it to this caller.
+	ErrMethodNotFound = errors.New("mnemo: method not found")
+
+	// ErrConflict reports a write that conflicts with stored state, such as
+	// a duplicate key.
+	ErrConflict = errors.New("mnemo: conflict")
+
+	// ErrIntegrity reports stored data that failed verification, such as
+	// encrypted content whose authentication tag does not match.
+	ErrIntegrity = errors.New("mnemo: integrity check failed")
+
+	// ErrPermissionDenied reports that the agent lacks access to a memory.
+	ErrPermissionDenied = errors.New("mnemo: permission denied")
+
+	// ErrQuotaExceeded reports that the server, or a proxy in front of it,
+	// refused the call for exceeding a rate or usage limit.
+	ErrQuotaExceeded = errors.New("mnemo: quota exceeded")
+)
+
+// JSON-RPC error codes returned by the mnemo server.
+const (
+	// CodeNotFound is MCP's "resource not found" code, returned by
+	// resources/read for an unknown memory (crates/mnemo-mcp/src/server.rs,
+	// read_resource).
+	CodeNotFound = -32002
+
+	// CodeMethodNotFound is the JSON-RPC 2.0 "method not found" code,
+	// returned for unknown methods and, per
+	// crates/mnemo-mcp/src/role_filter.rs, for tools the caller's roles
+	// deny.
+	CodeMethodNotFound = -32601
+)
+
+// rpcErrorSentinels maps server error codes to their sentinel errors.
+var rpcErrorSentinels = map[int]error{
+	CodeNotFound:       ErrNotFound,
+	CodeMethodNotFound: ErrMethodNotFound,
+}
+
+// toolErrorSentinels maps the message prefixes of failed tool results to
+// their sentinel errors. The prefixes are those of the server's core error
+// type (crates/mnemo-core/src/error.rs). DuckDB reports constraint
+// violations as "Constraint Error: ...", which the server wraps as a storage
+// error; "decryption tag mismatch" is returned for tampered encrypted
+// content (crates/mnemo-core/src/encryption.rs).
+var toolErrorSentinels = []struct {
+	prefix   string
+	sentinel error
+}{
+	{"not found: ", ErrNotFound},
+	{"permission denied: ", ErrPermissionDenied},
+	{"storage error: Constraint Error: ", ErrConflict},
+	{"validation error: decryption tag mismatch", ErrIntegrity},
+}
+
+// httpStatusSentinels maps the HTTP statuses that fail a call over the HTTP
+// transport to their sentinel errors.
+var httpStatusSentinels = map[int]error{
+	http.StatusConflict:        ErrConflict,
+	http.StatusTooManyRequests: ErrQuotaExceeded,
+}
+
+// RPCError is a JSON-RPC error returned by the mnemo server. If Code is
+// known, errors.Is matches the corresponding sentinel, for example
+// errors.Is(err, ErrNotFound).
+type RPCError struct {
+	Code    int
+	Message string
+}
+
+// Error implements the error interface.
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// Unwrap returns the sentinel error for Code, or nil if the code is not
+// recognized.
+func (e *RPCError) Unwrap() error {
+	return rpcErrorSentinels[e.Code]
+}
+
+// ToolError is a tool call the server ran but that failed, reported as a
+// result with isError set rather than as a JSON-RPC error. If Message is
+// recognized, errors.Is matches the corresponding sentinel, for example
+// errors.Is(err, ErrPermissionDenied).
+type ToolError struct {
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ToolError) Error() string {
+	return "tool error: " + e.Message
+}
+
+// Unwrap returns the sentinel error for Message, or nil if it is not
+// recognized.
+func (e *ToolError) Unwrap() error {
+	for _, s := range toolErrorSentinels {
+		if strings.HasPrefix(e.Message, s.prefix) {
+			return s.sentinel
+		}
+	}
+	return nil
+}
+
+// ValidationError reports an input field that failed client-side validation.
+// It is returned before any request is sent to the mnemo process.
+type ValidationError struct {
+	// Field is the JSON name of the offending field.
+	Field string
+
+	// Message describes why the value was rejected.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("mnemo: invalid %s: %s", e.Field, e.Message)
+}
+
+// VersionMismatchWarning reports that the server negotiated a different MCP
+// protocol version than the client requested. The client keeps working and
+// uses the server's version; it is delivered to ClientOptions.OnWarning.
+type VersionMismatchWarning struct {
+	// Requested is the version the client asked for.
+	Requested string
+
+	// Server is the version the server reported.
+	Server string
+}
+
+// Newer reports whether the server speaks a newer protocol than requested.
+// MCP protocol versions are dates, so they compare as strings.
+func (w *VersionMismatchWarning) Newer() bool {
+	return w.Server > w.Requested
+}
+
+// Error implements the error interface.
+func (w *VersionMismatchWarning) Error() string {
+	if w.Newer() {
+		return fmt.Sprintf("mnemo: server protocol version %s is newer than requested %s; newer server features may be ignored",
+			w.Server, w.Requested)
+	}
+	return fmt.Sprintf("mnemo: server protocol version %s is older than requested %s; some features may be unavailable",
+		w.Server, w.Requested)
+}
+
+// PermissionDeniedError is returned by Share and Forget when the server
+// denies the operation. It matches ErrPermissionDenied with errors.Is and
+// carries the server's explanation.
+type PermissionDeniedError struct {
+	// MemoryID is the memory the operation targeted, when there was exactly
+	// one.
+	MemoryID string
+
+	// Permission is the access level the operation required.
+	Permission string
+
+	// Reason is the server's explanation of the denial.
+	Reason string
+
+	tool *ToolError
+}
+
+// Error implements the error interface.
+func (e *PermissionDeniedError) Error() string {
+	if e.MemoryID == "" {
+		return fmt.Sprintf("mnemo: %s permission denied: %s", e.Permission, e.Reason)
+	}
+	return fmt.Sprintf("mnemo: %s permission denied on memory %s: %s", e.Permission, e.MemoryID, e.Reason)
+}
+
+// Unwrap returns the underlying *ToolError, which in turn matches
+// ErrPermissionDenied.
+func (e *PermissionDeniedError) Unwrap() error {
+	return e.tool
+}
+
+// asPermissionDenied converts a permission-denied tool error into a
+// *PermissionDeniedError for the given memory and permission. Other errors
+// are returned unchanged.
+func asPermissionDenied(err error, memoryID, permission string) error {
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || !errors.Is(toolErr, ErrPermissionDenied) {
+		return err
+	}
+	return &PermissionDeniedError{
+		MemoryID:   memoryID,
+		Permission: permission,
+		Reason:     strings.TrimPrefix(toolErr.Message, "permission denied: "),
+		tool:       toolErr,
+	}
+}
+
+// DegradedError is returned by HealthCheck alongside the HealthStatus when the
+// server is not fully healthy.
+type DegradedError struct {
+	// Status is the reported health, HealthDegraded or HealthUnavailable.
+	Status string
+
+	// Details holds the server's per-component diagnostics.
+	Details map[string]string
+}
+
+// Error implements the error interface.
+func (e *DegradedError) Error() string {
+	if len(e.Details) == 0 {
+		return fmt.Sprintf("mnemo: server %s", e.Status)
+	}
+	keys := make([]string, 0, len(e.Details))
+	for k := range e.Details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + ": " + e.Details[k]
+	}
+	return fmt.Sprintf("mnemo: server %s (%s)", e.Status, strings.Join(parts, "; "))
+}
+
+// SchemaMigrationRequiredError is returned by NewClient when the server's
+// storage schema must be migrated before use. Pass WithSkipSchemaCheck to
+// connect anyway.
+type SchemaMigrationRequiredError struct {
+	// Version is the schema version currently on disk.
+	Version uint32
+
+	// MinCompatible is the oldest schema version the server can use without
+	// migrating.
+	MinCompatible uint32
+}
+
+// Error implements the error interface.
+func (e *SchemaMigrationRequiredError) Error() string {
+	return fmt.Sprintf("mnemo: storage schema version %d requires migration (minimum compatible %d)",
+		e.Version, e.MinCompatible)
+}
+
+// SeedError is returned by SeedMemories when some corpus entries could not be
+// stored. Failures maps each failed entry's index in the corpus to its error.
+type SeedError struct {
+	Failures map[int]error
+}
+
+// Error implements the error interface.
+func (e *SeedError) Error() string {
+	idx := make([]int, 0, len(e.Failures))
+	for i := range e.Failures {
+		idx = append(idx, i)
+	}
+	if len(idx) == 0 {
+		return "mnemo: seeding failed"
+	}
+	sort.Ints(idx)
+	return fmt.Sprintf("mnemo: seeding failed for %d entries; first (entry %d): %v",
+		len(idx), idx[0], e.Failures[idx[0]])
+}
+
+// Unwrap returns the per-entry errors so errors.Is and errors.As can match
+// any of them.
+func (e *SeedError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, err := range e.Failures {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// ProtectedBranchError is returned by Checkpoint and Branch when the call
+// would write to a branch listed in ClientOptions.ProtectedBranches.
+type ProtectedBranchError struct {
+	Branch string
+}
+
+// Error implements the error interface.
+func (e *ProtectedBranchError) Error() string {
+	return fmt.Sprintf("mnemo: branch %q is protected", e.Branch)
+}
@@ -0,0 +1,92 @@
+package mnemo
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestRPCErrorSentinels — verifies each server code maps to its sentinel.
+// ---------------------------------------------------------------------------
+
+func TestRPCErrorSentinels(t *testing.T) {
+	tests := []struct {
+		code int
+		want error
+	}{
+		{CodeNotFound, ErrNotFound},
+		{CodeMethodNotFound, ErrMethodNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want.Error(), func(t *testing.T) {
+			c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+				return nil, &jsonRPCError{Code: tt.code, Message: "server says no"}
+			})
+
+			_, err := c.Recall(RecallInput{Query: "q"})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Recall() = %v, want errors.Is %v", err, tt.want)
+			}
+			var rpcErr *RPCError
+			if !errors.As(err, &rpcErr) || rpcErr.Code != tt.code {
+				t.Errorf("Recall() = %v, want *RPCError with code %d", err, tt.code)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRPCErrorUnknownCode — verifies unknown codes match no sentinel.
+// ---------------------------------------------------------------------------
+
+func TestRPCErrorUnknownCode(t *testing.T) {
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		return nil, &jsonRPCError{Code: -32603, Message: "internal error"}
+	})
+
+	_, err := c.Recall(RecallInput{Query: "q"})
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Message != "internal error" {
+		t.Fatalf("Recall() = %v, want *RPCError", err)
+	}
+	for _, sentinel := range []error{ErrNotFound, ErrMethodNotFound, ErrConflict, ErrIntegrity, ErrPermissionDenied, ErrQuotaExceeded, ErrClientClosed} {
+		if errors.Is(err, sentinel) {
+			t.Errorf("errors.Is(err, %v) = true for code -32603", sentinel)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestToolErrorSentinels — verifies failed tool results surface as
+// *ToolError and match the sentinel for their message.
+// ---------------------------------------------------------------------------
+
+func TestToolErrorSentinels(t *testing.T) {
+	tests := []struct {
+		message string
+		want    error
+	}{
+		{"not found: memory mem-1", ErrNotFound},
+		{"permission denied: memory is private", ErrPermissionDenied},
+		{`storage error: Constraint Error: Duplicate key "id: mem-1" violates primary key constraint`, ErrConflict},
+		{"validation error: decryption tag mismatch", ErrIntegrity},
+		{"storage error: disk full", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+				return toolFailure(tt.message), nil
+			})
+
+			_, err := c.Recall(RecallInput{Query: "q"})
+			var toolErr *ToolError
+			if !errors.As(err, &toolErr) || toolErr.Message != tt.message {
+				t.Fatalf("Recall() = %v, want *ToolError with message %q", err, tt.message)
+			}
+			if got := errors.Unwrap(toolErr); got != tt.want {
+				t.Errorf("errors.Unwrap(ToolError) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
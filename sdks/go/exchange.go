@@ -0,0 +1,120 @@
+package mnemo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Built-in memory types accepted by RememberInput.MemoryType.
+var memoryTypes = map[string]struct{}{
+	"episodic":   {},
+	"semantic":   {},
+	"procedural": {},
+	"working":    {},
+}
+
+// Scopes accepted by RememberInput.Scope.
+var memoryScopes = map[string]struct{}{
+	"private": {},
+	"shared":  {},
+	"public":  {},
+	"global":  {},
+}
+
+// MemoryFromJSON decodes a memory received from another source into a
+// RememberInput ready to pass to Remember. Unknown fields are rejected.
+//
+// Every field is checked; if any are invalid the returned error joins one
+// *ValidationError per invalid field, so errors.As finds the first and
+// the full list is available through Unwrap() []error.
+func MemoryFromJSON(data []byte) (*RememberInput, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var input RememberInput
+	if err := dec.Decode(&input); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, &ValidationError{
+				Field:   typeErr.Field,
+				Message: fmt.Sprintf("must be %s, got JSON %s", typeErr.Type, typeErr.Value),
+			}
+		}
+		return nil, fmt.Errorf("mnemo: decode memory: %w", err)
+	}
+
+	if errs := validateRememberFields(input); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &input, nil
+}
+
+// validateRememberFields returns a *ValidationError for each field of input
+// the server would reject.
+func validateRememberFields(input RememberInput) []error {
+	var errs []error
+	if input.Content == "" {
+		errs = append(errs, &ValidationError{Field: "content", Message: "is required"})
+	}
+	if input.MemoryType != nil {
+		if !knownMemoryType(*input.MemoryType) {
+			errs = append(errs, &ValidationError{Field: "memory_type", Message: fmt.Sprintf("unknown memory type %q", *input.MemoryType)})
+		}
+	}
+	if input.Scope != nil {
+		if _, ok := memoryScopes[*input.Scope]; !ok {
+			errs = append(errs, &ValidationError{Field: "scope", Message: fmt.Sprintf("unknown scope %q", *input.Scope)})
+		}
+	}
+	if input.Importance != nil && (*input.Importance < 0 || *input.Importance > 1) {
+		errs = append(errs, &ValidationError{Field: "importance", Message: fmt.Sprintf("must be between 0 and 1, got %v", *input.Importance)})
+	}
+	if input.DecayRate != nil && *input.DecayRate < 0 {
+		errs = append(errs, &ValidationError{Field: "decay_rate", Message: fmt.Sprintf("must not be negative, got %v", *input.DecayRate)})
+	}
+	if input.TTLSeconds != nil && *input.TTLSeconds == 0 {
+		errs = append(errs, &ValidationError{Field: "ttl_seconds", Message: "must be positive"})
+	}
+	for i, tag := range input.Tags {
+		if tag == "" {
+			errs = append(errs, &ValidationError{Field: "tags", Message: fmt.Sprintf("tag %d is empty", i)})
+		}
+	}
+	for i, id := range input.RelatedTo {
+		if id == "" {
+			errs = append(errs, &ValidationError{Field: "related_to", Message: fmt.Sprintf("memory ID %d is empty", i)})
+		}
+	}
+	return errs
+}
+
+// MemoryToJSON encodes m in a canonical form suitable for hashing, signing,
+// or comparing across systems: object keys are sorted at every level,
+// there is no insignificant whitespace, HTML characters are not escaped, and
+// numbers keep their original precision. The same memory always produces the
+// same bytes.
+func MemoryToJSON(m RecalledMemory) ([]byte, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("mnemo: encode memory: %w", err)
+	}
+
+	// Round-tripping through interface{} turns every object into a map,
+	// which encoding/json writes with sorted keys.
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("mnemo: encode memory: %w", err)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("mnemo: encode memory: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,121 @@
+package mnemo
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestMemoryFromJSON — verifies a valid memory decodes into RememberInput.
+// ---------------------------------------------------------------------------
+
+func TestMemoryFromJSON(t *testing.T) {
+	input, err := MemoryFromJSON([]byte(`{
+		"content": "user prefers dark mode",
+		"memory_type": "semantic",
+		"scope": "shared",
+		"importance": 0.8,
+		"tags": ["prefs"],
+		"metadata": {"source": "import"}
+	}`))
+	if err != nil {
+		t.Fatalf("MemoryFromJSON: %v", err)
+	}
+	if input.Content != "user prefers dark mode" {
+		t.Errorf("Content = %q, want %q", input.Content, "user prefers dark mode")
+	}
+	if input.MemoryType == nil || *input.MemoryType != "semantic" {
+		t.Errorf("MemoryType = %v, want semantic", input.MemoryType)
+	}
+	if input.Importance == nil || *input.Importance != 0.8 {
+		t.Errorf("Importance = %v, want 0.8", input.Importance)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestMemoryFromJSONInvalid — verifies every invalid field is reported.
+// ---------------------------------------------------------------------------
+
+func TestMemoryFromJSONInvalid(t *testing.T) {
+	_, err := MemoryFromJSON([]byte(`{"content":"","memory_type":"dream","importance":1.5,"tags":["ok",""]}`))
+	if err == nil {
+		t.Fatal("MemoryFromJSON: expected error, got nil")
+	}
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("MemoryFromJSON() err = %T, want joined errors", err)
+	}
+	var fields []string
+	for _, e := range joined.Unwrap() {
+		var verr *ValidationError
+		if !errors.As(e, &verr) {
+			t.Fatalf("error %v is not a *ValidationError", e)
+		}
+		fields = append(fields, verr.Field)
+	}
+	want := []string{"content", "memory_type", "importance", "tags"}
+	if len(fields) != len(want) {
+		t.Fatalf("invalid fields = %v, want %v", fields, want)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Errorf("field[%d] = %q, want %q", i, fields[i], want[i])
+		}
+	}
+
+	var verr *ValidationError
+	if _, err := MemoryFromJSON([]byte(`{"content":"x","importance":"high"}`)); !errors.As(err, &verr) || verr.Field != "importance" {
+		t.Errorf("MemoryFromJSON with string importance = %v, want ValidationError on importance", err)
+	}
+	if _, err := MemoryFromJSON([]byte(`{"content":"x","colour":"red"}`)); err == nil {
+		t.Error("MemoryFromJSON with unknown field: expected error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestMemoryToJSONCanonical — verifies the canonical form is deterministic.
+// ---------------------------------------------------------------------------
+
+func TestMemoryToJSONCanonical(t *testing.T) {
+	m := RecalledMemory{
+		ID:         "mem-1",
+		Content:    "a <b> & c",
+		MemoryType: "semantic",
+		Importance: 0.7,
+		Tags:       []string{"x"},
+		Metadata: map[string]interface{}{
+			"zeta":  1,
+			"alpha": map[string]interface{}{"y": true, "b": "two"},
+			"mid":   []interface{}{3, "three"},
+		},
+	}
+
+	first, err := MemoryToJSON(m)
+	if err != nil {
+		t.Fatalf("MemoryToJSON: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		again, err := MemoryToJSON(m)
+		if err != nil {
+			t.Fatalf("MemoryToJSON: %v", err)
+		}
+		if !bytes.Equal(first, again) {
+			t.Fatalf("MemoryToJSON call %d = %s, want %s", i, again, first)
+		}
+	}
+
+	if !bytes.Contains(first, []byte(`"metadata":{"alpha":{"b":"two","y":true},"mid":[3,"three"],"zeta":1}`)) {
+		t.Errorf("metadata keys not sorted: %s", first)
+	}
+	if !bytes.Contains(first, []byte(`"content":"a <b> & c"`)) {
+		t.Errorf("content was HTML-escaped: %s", first)
+	}
+	if bytes.Index(first, []byte(`"agent_id"`)) > bytes.Index(first, []byte(`"content"`)) {
+		t.Errorf("top-level keys not sorted: %s", first)
+	}
+	if bytes.ContainsAny(first, "\n\t") {
+		t.Errorf("canonical form contains whitespace: %s", first)
+	}
+}
@@ -0,0 +1,258 @@
+package mnemo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeServerEnv, when set, makes the test binary act as a fake mnemo server
+// on its stdin and stdout instead of running tests. Tests that need a real
+// child process point ClientOptions.Command at os.Args[0] with it set.
+const fakeServerEnv = "MNEMO_GO_FAKE_SERVER"
+
+// fakeMigrationEnv, when set alongside fakeServerEnv, makes the fake server
+// report that its storage schema needs migrating.
+const fakeMigrationEnv = "MNEMO_GO_FAKE_MIGRATION"
+
+// fakeStderrEnv, when set alongside fakeServerEnv, makes the fake server
+// write a large burst to stderr as it shuts down.
+const fakeStderrEnv = "MNEMO_GO_FAKE_STDERR"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeServerEnv) != "" {
+		serveFake(os.Stdin, os.Stdout, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+			if name == "mnemo.schema_version" && os.Getenv(fakeMigrationEnv) != "" {
+				return SchemaVersionResponse{Version: 3, MinCompatible: 5, MigrationRequired: true}, nil
+			}
+			return map[string]interface{}{"status": "ok"}, nil
+		})
+		if os.Getenv(fakeStderrEnv) != "" {
+			// More than any pipe buffer, so an undrained reader blocks.
+			fmt.Fprint(os.Stderr, strings.Repeat("shutting down\n", 1<<16))
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// newProcessClient starts the test binary as a fake mnemo server and
+// returns a Client connected to it.
+func newProcessClient(t *testing.T) *Client {
+	t.Helper()
+	t.Setenv(fakeServerEnv, "1")
+
+	c, err := NewClient(ClientOptions{Command: os.Args[0]})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return c
+}
+
+// toolHandler answers one tools/call request on the fake mnemo server. It
+// returns the value to encode as the tool's text content, or an RPC error.
+// A toolContents value is sent as the result's content items verbatim, and a
+// toolFailure as a result with isError set. The request's correlation ID is
+// echoed in the result's _meta.
+type toolHandler func(name string, args json.RawMessage) (interface{}, *jsonRPCError)
+
+// toolFailure, returned from a toolHandler, reports a failed tool call the
+// way the mnemo server does: as an isError result holding the message.
+type toolFailure string
+
+// newPipeClient returns a Client wired over in-memory pipes to a fake mnemo
+// server that answers initialize itself and hands every tools/call to
+// handler. The pipes are closed when the test finishes.
+func newPipeClient(t *testing.T, handler toolHandler) *Client {
+	t.Helper()
+	return newPipeClientWithOptions(t, ClientOptions{}, handler)
+}
+
+// newPipeClientWithOptions is like newPipeClient but configures the Client
+// with opts.
+func newPipeClientWithOptions(t *testing.T, opts ClientOptions, handler toolHandler) *Client {
+	t.Helper()
+
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+	go serveFake(serverR, serverW, handler)
+
+	t.Cleanup(func() {
+		_ = clientW.Close()
+		_ = serverW.Close()
+	})
+
+	return &Client{stdin: clientW, stdout: newResponseScanner(clientR, opts.MaxResponseBytes), opts: opts}
+}
+
+// serveFake is the request loop of the fake mnemo server. A UTF-8 byte order
+// mark before a request is ignored.
+func serveFake(r io.Reader, w io.WriteCloser, handler toolHandler) {
+	defer w.Close()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		var req struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			ID     *int            `json:"id"`
+		}
+		line := bytes.TrimPrefix(scanner.Bytes(), []byte("\xef\xbb\xbf"))
+		if err := json.Unmarshal(line, &req); err != nil || req.ID == nil {
+			continue
+		}
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": *req.ID}
+		switch req.Method {
+		case "initialize":
+			resp["result"] = map[string]interface{}{
+				"protocolVersion": "2024-11-05",
+				"serverInfo":      map[string]interface{}{"name": "mnemo-fake"},
+			}
+		case "tools/call":
+			var params struct {
+				Name      string          `json:"name"`
+				Arguments json.RawMessage `json:"arguments"`
+				Meta      *toolCallMeta   `json:"_meta"`
+			}
+			_ = json.Unmarshal(req.Params, &params)
+			result, rpcErr := handler(params.Name, params.Arguments)
+			if rpcErr != nil {
+				resp["error"] = rpcErr
+				break
+			}
+			out := map[string]interface{}{}
+			if params.Meta != nil && params.Meta.CorrelationID != "" {
+				out["_meta"] = toolCallMeta{CorrelationID: params.Meta.CorrelationID}
+			}
+			if items, ok := result.(toolContents); ok {
+				out["content"] = items
+			} else if msg, ok := result.(toolFailure); ok {
+				out["isError"] = true
+				out["content"] = []map[string]interface{}{{"type": "text", "text": string(msg)}}
+			} else {
+				text, _ := json.Marshal(result)
+				out["content"] = []map[string]interface{}{{"type": "text", "text": string(text)}}
+			}
+			resp["result"] = out
+		default:
+			resp["error"] = &jsonRPCError{Code: -32601, Message: "method not found"}
+		}
+
+		data, _ := json.Marshal(resp)
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return
+		}
+	}
+}
+
+// fakeMemoryClient is an in-process MemoryClient for testing wrappers. Each
+// call is counted by tool name; err, when set, is returned from every call.
+type fakeMemoryClient struct {
+	mu    sync.Mutex
+	calls map[string]int
+	err   error
+}
+
+// setErr replaces the error returned from subsequent calls.
+func (f *fakeMemoryClient) setErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+// count returns how many times the named method has been called.
+func (f *fakeMemoryClient) count(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[name]
+}
+
+// record counts a call and returns the configured error.
+func (f *fakeMemoryClient) record(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[name]++
+	return f.err
+}
+
+func (f *fakeMemoryClient) RememberContext(ctx context.Context, input RememberInput) (*RememberResponse, error) {
+	if err := f.record("remember"); err != nil {
+		return nil, err
+	}
+	return &RememberResponse{ID: "mem-1", Status: "remembered"}, nil
+}
+
+func (f *fakeMemoryClient) RecallContext(ctx context.Context, input RecallInput) (*RecallResponse, error) {
+	if err := f.record("recall"); err != nil {
+		return nil, err
+	}
+	return &RecallResponse{Memories: []RecalledMemory{{ID: "mem-1", Content: input.Query}}, Total: 1}, nil
+}
+
+func (f *fakeMemoryClient) ForgetContext(ctx context.Context, input ForgetInput) (*ForgetResponse, error) {
+	if err := f.record("forget"); err != nil {
+		return nil, err
+	}
+	return &ForgetResponse{Forgotten: input.MemoryIDs, Status: "forgotten"}, nil
+}
+
+func (f *fakeMemoryClient) ShareContext(ctx context.Context, input ShareInput) (*ShareResponse, error) {
+	if err := f.record("share"); err != nil {
+		return nil, err
+	}
+	return &ShareResponse{MemoryID: input.MemoryID, Status: "shared"}, nil
+}
+
+func (f *fakeMemoryClient) CheckpointContext(ctx context.Context, input CheckpointInput) (*CheckpointResponse, error) {
+	if err := f.record("checkpoint"); err != nil {
+		return nil, err
+	}
+	return &CheckpointResponse{CheckpointID: "cp-1", Status: "checkpointed"}, nil
+}
+
+func (f *fakeMemoryClient) BranchContext(ctx context.Context, input BranchInput) (*BranchResponse, error) {
+	if err := f.record("branch"); err != nil {
+		return nil, err
+	}
+	return &BranchResponse{BranchName: input.NewBranchName, Status: "branched"}, nil
+}
+
+func (f *fakeMemoryClient) MergeContext(ctx context.Context, input MergeInput) (*MergeResponse, error) {
+	if err := f.record("merge"); err != nil {
+		return nil, err
+	}
+	return &MergeResponse{Status: "merged"}, nil
+}
+
+func (f *fakeMemoryClient) ReplayContext(ctx context.Context, input ReplayInput) (*ReplayResponse, error) {
+	if err := f.record("replay"); err != nil {
+		return nil, err
+	}
+	return &ReplayResponse{Status: "replayed"}, nil
+}
+
+func (f *fakeMemoryClient) VerifyContext(ctx context.Context, input VerifyInput) (*VerifyResponse, error) {
+	if err := f.record("verify"); err != nil {
+		return nil, err
+	}
+	return &VerifyResponse{Valid: true, Status: "verified"}, nil
+}
+
+func (f *fakeMemoryClient) DelegateContext(ctx context.Context, input DelegateInput) (*DelegateResponse, error) {
+	if err := f.record("delegate"); err != nil {
+		return nil, err
+	}
+	return &DelegateResponse{Delegate: input.DelegateID, Status: "delegated"}, nil
+}
@@ -0,0 +1,86 @@
+package mnemo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultHTTPTimeout bounds each HTTP request when ClientOptions.HTTPClient
+// is nil.
+const defaultHTTPTimeout = 30 * time.Second
+
+// httpTransport carries JSON-RPC messages to a mnemo server over HTTP. Each
+// message is POSTed to {url}/rpc; the body of the reply to a request is held
+// until readRawResponse collects it.
+type httpTransport struct {
+	url    string
+	token  string
+	client *http.Client
+
+	// pending is the response body to the last request sent.
+	pending []byte
+}
+
+// newHTTPTransport returns a transport that posts to baseURL with client,
+// authenticating with token as a bearer token if it is non-empty. A nil
+// client is replaced by one with defaultHTTPTimeout.
+func newHTTPTransport(baseURL, token string, client *http.Client) *httpTransport {
+	if client == nil {
+		client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	return &httpTransport{
+		url:    strings.TrimSuffix(baseURL, "/") + "/rpc",
+		token:  token,
+		client: client,
+	}
+}
+
+// send POSTs one encoded JSON-RPC message, abandoning it when ctx is done.
+// If expectReply is set, the response body is kept for receive; replies to
+// notifications are discarded.
+func (t *httpTransport) send(ctx context.Context, data []byte, expectReply bool) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if sentinel, ok := httpStatusSentinels[resp.StatusCode]; ok {
+			return fmt.Errorf("http status %s: %w", resp.Status, sentinel)
+		}
+		return fmt.Errorf("http status %s", resp.Status)
+	}
+	if expectReply {
+		t.pending = bytes.TrimSpace(body)
+	}
+	return nil
+}
+
+// receive returns the reply to the last request sent.
+func (t *httpTransport) receive() ([]byte, error) {
+	if t.pending == nil {
+		return nil, fmt.Errorf("no pending http response")
+	}
+	body := t.pending
+	t.pending = nil
+	return body, nil
+}
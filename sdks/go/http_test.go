@@ -0,0 +1,190 @@
+package mnemo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// responseWriteCloser adapts an http.ResponseWriter for serveFake.
+type responseWriteCloser struct {
+	http.ResponseWriter
+}
+
+func (responseWriteCloser) Close() error { return nil }
+
+// ---------------------------------------------------------------------------
+// TestHTTPTransport — verifies Remember round-trips over HTTP.
+// ---------------------------------------------------------------------------
+
+func TestHTTPTransport(t *testing.T) {
+	var paths, auths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		auths = append(auths, r.Header.Get("Authorization"))
+		serveFake(r.Body, responseWriteCloser{w}, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+			if name != "mnemo.remember" {
+				return map[string]interface{}{}, nil
+			}
+			var in RememberInput
+			_ = json.Unmarshal(args, &in)
+			return RememberResponse{ID: "mem-http", ContentHash: in.Content, Status: "remembered"}, nil
+		})
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(ClientOptions{}, WithHTTPTransport(srv.URL+"/", "secret-token"))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer c.Close()
+
+	resp, err := c.Remember(RememberInput{Content: "sent over http"})
+	if err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+	if resp.ID != "mem-http" || resp.ContentHash != "sent over http" {
+		t.Errorf("Remember() = %+v, want mem-http echoing the content", resp)
+	}
+
+	for i := range paths {
+		if paths[i] != "/rpc" {
+			t.Errorf("request %d path = %q, want /rpc", i, paths[i])
+		}
+		if auths[i] != "Bearer secret-token" {
+			t.Errorf("request %d Authorization = %q, want bearer token", i, auths[i])
+		}
+	}
+	// initialize, notifications/initialized, schema_version, remember.
+	if len(paths) != 4 {
+		t.Errorf("server saw %d requests, want 4", len(paths))
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestHTTPTransportError — verifies non-2xx statuses fail the call.
+// ---------------------------------------------------------------------------
+
+func TestHTTPTransportError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(ClientOptions{HTTPURL: srv.URL}); err == nil {
+		t.Error("NewClient against a 401 server: expected error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestHTTPStatusSentinels — verifies 409 and 429 statuses match ErrConflict
+// and ErrQuotaExceeded.
+// ---------------------------------------------------------------------------
+
+func TestHTTPStatusSentinels(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusConflict, ErrConflict},
+		{http.StatusTooManyRequests, ErrQuotaExceeded},
+		{http.StatusInternalServerError, nil},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if bytes.Contains(body, []byte("mnemo.recall")) {
+					http.Error(w, "refused", tt.status)
+					return
+				}
+				serveFake(io.NopCloser(bytes.NewReader(body)), responseWriteCloser{w}, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+					return map[string]interface{}{}, nil
+				})
+			}))
+			defer srv.Close()
+
+			c, err := NewClient(ClientOptions{}, WithHTTPTransport(srv.URL, ""))
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			defer c.Close()
+
+			_, err = c.Recall(RecallInput{Query: "q"})
+			if err == nil {
+				t.Fatal("Recall() = nil error, want the HTTP status")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Recall() = %v, want errors.Is %v", err, tt.want)
+			}
+			for _, sentinel := range []error{ErrConflict, ErrQuotaExceeded} {
+				if sentinel != tt.want && errors.Is(err, sentinel) {
+					t.Errorf("errors.Is(err, %v) = true for status %d", sentinel, tt.status)
+				}
+			}
+		})
+	}
+}
+
+// countingTransport counts the requests it passes to http.DefaultTransport.
+type countingTransport struct {
+	n atomic.Int32
+}
+
+func (c *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	c.n.Add(1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// ---------------------------------------------------------------------------
+// TestHTTPTransportContext — verifies the call's context cancels a stalled
+// request and that WithHTTPClient's client carries it.
+// ---------------------------------------------------------------------------
+
+func TestHTTPTransportContext(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if bytes.Contains(body, []byte("mnemo.recall")) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		serveFake(io.NopCloser(bytes.NewReader(body)), responseWriteCloser{w}, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+			return map[string]interface{}{}, nil
+		})
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	transport := &countingTransport{}
+	c, err := NewClient(ClientOptions{}, WithHTTPTransport(srv.URL, ""),
+		WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer c.Close()
+	if transport.n.Load() == 0 {
+		t.Error("WithHTTPClient's client was not used")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = c.RecallContext(ctx, RecallInput{Query: "stalled"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("RecallContext() = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("RecallContext() took %v, want it to stop at the deadline", elapsed)
+	}
+}
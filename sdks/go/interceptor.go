@@ -0,0 +1,26 @@
+package mnemo
+
+import "context"
+
+// Handler performs a tool call: it sends req and decodes the result into
+// resp.
+type Handler func(ctx context.Context, req, resp interface{}) error
+
+// Interceptor wraps a tool call. method is the MCP tool name (for example
+// "mnemo.remember"), req is the tool arguments, and resp is the destination
+// the result will be decoded into. An interceptor must call next to continue
+// the chain, and may inspect or replace req, resp, and the returned error.
+type Interceptor func(ctx context.Context, method string, req, resp interface{}, next Handler) error
+
+// Use installs interceptors around every subsequent tool call. Interceptors
+// run in the order given, across calls to Use: the first one installed is
+// the outermost.
+func (c *Client) Use(interceptors ...Interceptor) {
+	c.interceptorMu.Lock()
+	defer c.interceptorMu.Unlock()
+
+	chain := make([]Interceptor, 0, len(c.interceptors)+len(interceptors))
+	chain = append(chain, c.interceptors...)
+	chain = append(chain, interceptors...)
+	c.interceptors = chain
+}
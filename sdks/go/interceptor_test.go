@@ -0,0 +1,83 @@
+package mnemo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestInterceptorOrder — verifies interceptors wrap calls in install order.
+// ---------------------------------------------------------------------------
+
+func TestInterceptorOrder(t *testing.T) {
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		return RememberResponse{ID: "mem-1", Status: "remembered"}, nil
+	})
+
+	var trace []string
+	record := func(label string) Interceptor {
+		return func(ctx context.Context, method string, req, resp interface{}, next Handler) error {
+			trace = append(trace, label+" before "+method)
+			err := next(ctx, req, resp)
+			trace = append(trace, label+" after "+method)
+			return err
+		}
+	}
+	c.Use(record("first"))
+	c.Use(record("second"))
+
+	resp, err := c.Remember(RememberInput{Content: "hello"})
+	if err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+	if resp.ID != "mem-1" {
+		t.Errorf("ID = %q, want %q", resp.ID, "mem-1")
+	}
+
+	want := []string{
+		"first before mnemo.remember",
+		"second before mnemo.remember",
+		"second after mnemo.remember",
+		"first after mnemo.remember",
+	}
+	if len(trace) != len(want) {
+		t.Fatalf("trace = %v, want %v", trace, want)
+	}
+	for i := range want {
+		if trace[i] != want[i] {
+			t.Errorf("trace[%d] = %q, want %q", i, trace[i], want[i])
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestInterceptorShortCircuit — verifies an interceptor can skip the call.
+// ---------------------------------------------------------------------------
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	called := false
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		called = true
+		return RecallResponse{}, nil
+	})
+
+	c.Use(func(ctx context.Context, method string, req, resp interface{}, next Handler) error {
+		if r, ok := resp.(*RecallResponse); ok {
+			r.Total = 42
+			return nil
+		}
+		return next(ctx, req, resp)
+	})
+
+	resp, err := c.Recall(RecallInput{Query: "cached"})
+	if err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if resp.Total != 42 {
+		t.Errorf("Total = %d, want 42 from interceptor", resp.Total)
+	}
+	if called {
+		t.Error("server should not be called when the interceptor short-circuits")
+	}
+}
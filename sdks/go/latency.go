@@ -0,0 +1,97 @@
+package mnemo
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// probeThreadID isolates the state written by latency probes.
+const probeThreadID = "mnemo-latency-probe"
+
+// readOnlyProbeTools lists the tools probed by default. None of them change
+// server state.
+var readOnlyProbeTools = []string{
+	"mnemo.recall",
+	"mnemo.verify",
+	"mnemo.schema_version",
+	"mnemo.health",
+}
+
+// probeInput returns the smallest well-formed arguments for a probed tool.
+// "mnemo.remember" is the only write tool with a probe; ProbeLatency forgets
+// the memory it creates.
+func probeInput(tool string) (interface{}, bool) {
+	probe := probeThreadID
+	limit := 1
+	switch tool {
+	case "mnemo.recall":
+		return RecallInput{Query: "latency probe", Limit: &limit}, true
+	case "mnemo.verify":
+		return VerifyInput{ThreadID: &probe}, true
+	case "mnemo.schema_version", "mnemo.health":
+		return struct{}{}, true
+	case "mnemo.remember":
+		ttl := uint64(60)
+		return RememberInput{Content: "latency probe", ThreadID: &probe, TTLSeconds: &ttl}, true
+	default:
+		return nil, false
+	}
+}
+
+// ProbeLatency sends a minimal request to each named tool and returns the
+// wall-clock round-trip time of each. An empty tools list probes the
+// read-only tools "mnemo.recall", "mnemo.verify", "mnemo.schema_version",
+// and "mnemo.health".
+//
+// "mnemo.remember" may be named explicitly to time a write. Its probe stores
+// a short-lived memory in a dedicated thread and hard-deletes it
+// afterwards; the deletion is not timed. Other write tools cannot be
+// probed. The first failing probe stops the run and its error is returned
+// together with the durations measured so far.
+func (c *Client) ProbeLatency(ctx context.Context, tools []string) (map[string]time.Duration, error) {
+	if len(tools) == 0 {
+		tools = readOnlyProbeTools
+	}
+
+	latencies := make(map[string]time.Duration, len(tools))
+	for _, tool := range tools {
+		input, ok := probeInput(tool)
+		if !ok {
+			return latencies, fmt.Errorf("mnemo: no latency probe for tool %q", tool)
+		}
+
+		var resp map[string]interface{}
+		start := time.Now()
+		if err := c.callTool(ctx, tool, input, &resp); err != nil {
+			return latencies, err
+		}
+		latencies[tool] = time.Since(start)
+
+		if tool == "mnemo.remember" {
+			if err := c.forgetProbe(ctx, resp); err != nil {
+				return latencies, err
+			}
+		}
+	}
+	return latencies, nil
+}
+
+// ProbeLatencyAll is ProbeLatency for the default read-only tools.
+func (c *Client) ProbeLatencyAll(ctx context.Context) (map[string]time.Duration, error) {
+	return c.ProbeLatency(ctx, nil)
+}
+
+// forgetProbe hard-deletes the memory created by a remember probe, given
+// the probe's decoded response.
+func (c *Client) forgetProbe(ctx context.Context, resp map[string]interface{}) error {
+	id, _ := resp["id"].(string)
+	if id == "" {
+		return fmt.Errorf("mnemo: latency probe: remember returned no memory ID to clean up")
+	}
+	hardDelete := "hard_delete"
+	if _, err := c.ForgetContext(ctx, ForgetInput{MemoryIDs: []string{id}, Strategy: &hardDelete}); err != nil {
+		return fmt.Errorf("mnemo: latency probe: forget probe memory %s: %w", id, err)
+	}
+	return nil
+}
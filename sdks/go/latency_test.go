@@ -0,0 +1,95 @@
+package mnemo
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// TestProbeLatencyAll — verifies only read-only tools are probed by default
+// and each is timed.
+// ---------------------------------------------------------------------------
+
+func TestProbeLatencyAll(t *testing.T) {
+	var called []string
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		called = append(called, name)
+		time.Sleep(time.Millisecond)
+		return map[string]interface{}{"status": "ok"}, nil
+	})
+
+	latencies, err := c.ProbeLatencyAll(context.Background())
+	if err != nil {
+		t.Fatalf("ProbeLatencyAll: %v", err)
+	}
+	if !reflect.DeepEqual(called, readOnlyProbeTools) {
+		t.Errorf("called = %v, want only %v", called, readOnlyProbeTools)
+	}
+	for _, tool := range readOnlyProbeTools {
+		if d := latencies[tool]; d <= 0 {
+			t.Errorf("latencies[%q] = %v, want > 0", tool, d)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestProbeLatencySubset — verifies only the named tools are probed and
+// write tools other than remember are refused.
+// ---------------------------------------------------------------------------
+
+func TestProbeLatencySubset(t *testing.T) {
+	var called []string
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		called = append(called, name)
+		return map[string]interface{}{}, nil
+	})
+
+	latencies, err := c.ProbeLatency(context.Background(), []string{"mnemo.recall", "mnemo.verify"})
+	if err != nil {
+		t.Fatalf("ProbeLatency: %v", err)
+	}
+	if len(latencies) != 2 || len(called) != 2 {
+		t.Errorf("latencies = %v, called = %v, want 2 each", latencies, called)
+	}
+
+	for _, tool := range []string{"mnemo.unknown", "mnemo.delegate", "mnemo.branch", "mnemo.share"} {
+		if _, err := c.ProbeLatency(context.Background(), []string{tool}); err == nil {
+			t.Errorf("ProbeLatency(%q): expected error, got nil", tool)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestProbeLatencyRememberCleansUp — verifies the opt-in write probe deletes
+// the memory it created.
+// ---------------------------------------------------------------------------
+
+func TestProbeLatencyRememberCleansUp(t *testing.T) {
+	var forgotten ForgetInput
+	var called []string
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		called = append(called, name)
+		if name == "mnemo.forget" {
+			_ = json.Unmarshal(args, &forgotten)
+			return ForgetResponse{Forgotten: forgotten.MemoryIDs, Status: "forgotten"}, nil
+		}
+		return RememberResponse{ID: "probe-mem", Status: "remembered"}, nil
+	})
+
+	latencies, err := c.ProbeLatency(context.Background(), []string{"mnemo.remember"})
+	if err != nil {
+		t.Fatalf("ProbeLatency: %v", err)
+	}
+	if _, ok := latencies["mnemo.remember"]; !ok || len(latencies) != 1 {
+		t.Errorf("latencies = %v, want only mnemo.remember", latencies)
+	}
+	if !reflect.DeepEqual(called, []string{"mnemo.remember", "mnemo.forget"}) {
+		t.Errorf("called = %v, want remember then forget", called)
+	}
+	if !reflect.DeepEqual(forgotten.MemoryIDs, []string{"probe-mem"}) || forgotten.Strategy == nil || *forgotten.Strategy != "hard_delete" {
+		t.Errorf("forget = %+v, want a hard delete of probe-mem", forgotten)
+	}
+}
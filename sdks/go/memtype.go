@@ -0,0 +1,111 @@
+package mnemo
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// DefaultMemoryTypes is the registry RememberInput.Validate consults for
+// memory types beyond the four built-in ones.
+var DefaultMemoryTypes = &MemoryTypeRegistry{}
+
+// MemoryTypeMeta describes a custom memory type.
+type MemoryTypeMeta struct {
+	Description string
+
+	// DefaultDecayRate and DefaultImportance are used by Remember for
+	// memories of this type whose DecayRate or Importance is unset. Zero
+	// leaves the server default in place.
+	DefaultDecayRate  float32
+	DefaultImportance float32
+}
+
+// MemoryTypeRegistry holds domain-specific memory types such as
+// "preference", "constraint", or "goal". The zero value is an empty registry
+// ready to use, and it is safe for concurrent use.
+type MemoryTypeRegistry struct {
+	mu    sync.RWMutex
+	types map[string]MemoryTypeMeta
+}
+
+// Register adds a custom memory type. It returns a *ValidationError if name
+// is empty, is one of the built-in types, or is already registered.
+func (r *MemoryTypeRegistry) Register(name string, meta MemoryTypeMeta) error {
+	if name == "" {
+		return &ValidationError{Field: "memory_type", Message: "is required"}
+	}
+	if _, ok := memoryTypes[name]; ok {
+		return &ValidationError{Field: "memory_type", Message: fmt.Sprintf("%q is a built-in memory type", name)}
+	}
+	if meta.DefaultImportance < 0 || meta.DefaultImportance > 1 {
+		return &ValidationError{Field: "default_importance", Message: fmt.Sprintf("must be between 0 and 1, got %v", meta.DefaultImportance)}
+	}
+	if meta.DefaultDecayRate < 0 {
+		return &ValidationError{Field: "default_decay_rate", Message: fmt.Sprintf("must not be negative, got %v", meta.DefaultDecayRate)}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.types[name]; ok {
+		return &ValidationError{Field: "memory_type", Message: fmt.Sprintf("%q is already registered", name)}
+	}
+	if r.types == nil {
+		r.types = make(map[string]MemoryTypeMeta)
+	}
+	r.types[name] = meta
+	return nil
+}
+
+// IsRegistered reports whether name has been registered. Built-in types are
+// not registered.
+func (r *MemoryTypeRegistry) IsRegistered(name string) bool {
+	_, ok := r.Lookup(name)
+	return ok
+}
+
+// Lookup returns the metadata of a registered memory type.
+func (r *MemoryTypeRegistry) Lookup(name string) (MemoryTypeMeta, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	meta, ok := r.types[name]
+	return meta, ok
+}
+
+// knownMemoryType reports whether name is built in or registered in
+// DefaultMemoryTypes.
+func knownMemoryType(name string) bool {
+	if _, ok := memoryTypes[name]; ok {
+		return true
+	}
+	return DefaultMemoryTypes.IsRegistered(name)
+}
+
+// warnUnknownMemoryType logs a warning if name is neither built in nor
+// registered. The server may still accept it, so it is not an error.
+func warnUnknownMemoryType(name string) {
+	if !knownMemoryType(name) {
+		slog.Warn("mnemo: memory type is not built in or registered", "memory_type", name)
+	}
+}
+
+// applyMemoryTypeDefaults fills the unset Importance and DecayRate of input
+// from its registered memory type, if any.
+func applyMemoryTypeDefaults(input RememberInput) RememberInput {
+	if input.MemoryType == nil {
+		return input
+	}
+	meta, ok := DefaultMemoryTypes.Lookup(*input.MemoryType)
+	if !ok {
+		return input
+	}
+	if input.Importance == nil && meta.DefaultImportance != 0 {
+		v := meta.DefaultImportance
+		input.Importance = &v
+	}
+	if input.DecayRate == nil && meta.DefaultDecayRate != 0 {
+		v := meta.DefaultDecayRate
+		input.DecayRate = &v
+	}
+	return input
+}
@@ -0,0 +1,91 @@
+package mnemo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestMemoryTypeRegistry — verifies registration rules and lookups.
+// ---------------------------------------------------------------------------
+
+func TestMemoryTypeRegistry(t *testing.T) {
+	var r MemoryTypeRegistry
+	if r.IsRegistered("goal") {
+		t.Error("empty registry reports goal as registered")
+	}
+	if err := r.Register("goal", MemoryTypeMeta{Description: "Something the agent is working toward", DefaultImportance: 0.8}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if !r.IsRegistered("goal") {
+		t.Error("IsRegistered(goal) = false after Register")
+	}
+	if meta, ok := r.Lookup("goal"); !ok || meta.DefaultImportance != 0.8 {
+		t.Errorf("Lookup(goal) = %+v, %v", meta, ok)
+	}
+
+	var verr *ValidationError
+	for _, name := range []string{"goal", "episodic", ""} {
+		if err := r.Register(name, MemoryTypeMeta{}); !errors.As(err, &verr) {
+			t.Errorf("Register(%q) = %v, want ValidationError", name, err)
+		}
+	}
+	if err := r.Register("constraint", MemoryTypeMeta{DefaultImportance: 1.5}); !errors.As(err, &verr) {
+		t.Errorf("Register with importance 1.5 = %v, want ValidationError", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRememberCustomMemoryType — verifies Validate accepts registered types,
+// only warns about unknown ones, and Remember applies the type's defaults.
+// ---------------------------------------------------------------------------
+
+func TestRememberCustomMemoryType(t *testing.T) {
+	prevRegistry := DefaultMemoryTypes
+	DefaultMemoryTypes = &MemoryTypeRegistry{}
+	defer func() { DefaultMemoryTypes = prevRegistry }()
+
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(prev)
+
+	if err := DefaultMemoryTypes.Register("preference", MemoryTypeMeta{DefaultImportance: 0.7, DefaultDecayRate: 0.05}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	preference := "preference"
+	if err := (RememberInput{Content: "Likes window seats", MemoryType: &preference}).Validate(); err != nil {
+		t.Errorf("Validate with registered type = %v, want nil", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("log output = %q, want none for a registered type", buf.String())
+	}
+	if _, err := MemoryFromJSON([]byte(`{"content": "Likes window seats", "memory_type": "preference"}`)); err != nil {
+		t.Errorf("MemoryFromJSON with registered type = %v, want nil", err)
+	}
+
+	mood := "mood"
+	if err := (RememberInput{Content: "Cheerful today", MemoryType: &mood}).Validate(); err != nil {
+		t.Errorf("Validate with unregistered type = %v, want nil", err)
+	}
+	if !strings.Contains(buf.String(), "level=WARN") || !strings.Contains(buf.String(), "mood") {
+		t.Errorf("log output = %q, want a warning about mood", buf.String())
+	}
+
+	var sent RememberInput
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		_ = json.Unmarshal(args, &sent)
+		return RememberResponse{ID: "mem-1", Status: "remembered"}, nil
+	})
+	if _, err := c.Remember(RememberInput{Content: "Likes window seats", MemoryType: &preference}); err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+	if sent.Importance == nil || *sent.Importance != 0.7 || sent.DecayRate == nil || *sent.DecayRate != 0.05 {
+		t.Errorf("sent importance %v, decay rate %v; want the type defaults 0.7 and 0.05", sent.Importance, sent.DecayRate)
+	}
+}
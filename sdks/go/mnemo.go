@@ -1,16 +1,27 @@
 // Package mnemo is the Go SDK for Mnemo — MCP-native memory database for
 // AI agents.
 //
+// Methods whose names end in Context take a context.Context that bounds the
+// call: it is not sent if ctx is already done, and stops waiting for the
+// server's response once ctx is done.
+//
 // Package version: 0.4.5
 package mnemo
 
 import (
 	"bufio"
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
 	"os/exec"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Version is the SDK version. Kept in lockstep with the Cargo workspace
@@ -25,177 +36,1333 @@ type ClientOptions struct {
 	// DbPath is the path to the database file. Passed as --db-path.
 	DbPath string
 
-	// AgentID is the default agent identifier. Passed as --agent-id.
-	AgentID string
+	// AgentID is the default agent identifier. Passed as --agent-id.
+	AgentID string
+
+	// OrgID is the default organization identifier. Passed as --org-id.
+	OrgID string
+
+	// OpenAIKey is the OpenAI API key for embeddings. Passed as
+	// --openai-api-key.
+	OpenAIKey string
+
+	// Dimensions sets the embedding vector dimensions. Passed as --dimensions.
+	Dimensions int
+
+	// PostgresURL selects the PostgreSQL backend instead of the DbPath file.
+	// Passed as --postgres-url.
+	PostgresURL string
+
+	// DisableTelemetry opts out of anonymous usage metrics. Passed as
+	// --no-telemetry.
+	DisableTelemetry bool
+
+	// LogLevel sets the mnemo process log level, for example "debug" or
+	// "warn". Passed to the process as RUST_LOG.
+	LogLevel string
+
+	// ThreadID is the default conversation thread for calls whose input
+	// leaves ThreadID unset, as if every context carried WithThreadID. A
+	// thread set on the context takes precedence. It is not passed to the
+	// mnemo process.
+	ThreadID string
+
+	// ExtraArgs are appended verbatim after the flags built from the fields
+	// above, so newer mnemo CLI flags can be used before the SDK exposes
+	// them. An entry may not repeat a flag already set by another field.
+	ExtraArgs []string
+
+	// StdinWriteTimeout bounds how long a write to the process stdin may
+	// block before the process is killed. Zero means no timeout.
+	StdinWriteTimeout time.Duration
+
+	// CaptureStderr routes the process stderr to Client.ReadStderr instead of
+	// discarding it. The caller must then drain the reader, or the process
+	// blocks once its stderr buffer fills. Close ends the reader, so output
+	// written while the process shuts down is discarded.
+	CaptureStderr bool
+
+	// Watch configures WatchMemory.
+	Watch WatchOptions
+
+	// MaxResponseBytes is the largest single response line the client will
+	// read. Responses carrying multi-megabyte memories need a larger limit.
+	// Defaults to 1 MB.
+	MaxResponseBytes int
+
+	// CompressMessages gzip-encodes tool arguments larger than 64 KB before
+	// sending them, marking the request with a contentEncoding hint. The
+	// mnemo server must support compressed arguments.
+	CompressMessages bool
+
+	// CompressContentThreshold, when positive, stores RememberInput.Content
+	// longer than this many bytes gzip-compressed and base64-encoded behind
+	// a marker prefix, and flags it in the memory's metadata. Recall,
+	// LookupByHash, and Replay restore the original text from the prefix,
+	// so clients do so whether or not they set this option and without
+	// fetching metadata. Compressed content is not searchable by meaning.
+	CompressContentThreshold int
+
+	// ProtocolVersion is the MCP protocol version requested during the
+	// initialize handshake. Defaults to DefaultProtocolVersion.
+	ProtocolVersion string
+
+	// OnWarning receives non-fatal problems such as a
+	// *VersionMismatchWarning. If nil, warnings are logged with log/slog.
+	OnWarning func(error)
+
+	// WarmUpTimeout bounds WarmUp, which may take a while as the embedding
+	// model loads. Zero means WarmUp is bounded only by its context.
+	WarmUpTimeout time.Duration
+
+	// RedactKeys lists JSON object keys whose values are replaced with
+	// "[REDACTED]" in SetDebug output, for example "openai_api_key".
+	// Matching is case-insensitive and applies at any nesting depth.
+	RedactKeys []string
+
+	// WALPath, when set, is a file where Remember calls that fail to reach
+	// the mnemo process are logged for later replay with FlushWAL. A new
+	// client replays the log once it has connected. Remember calls are given
+	// an idempotency key so a replay cannot store a memory twice.
+	WALPath string
+
+	// WALMaxAge discards write-ahead log entries older than this instead of
+	// replaying them. Zero replays entries of any age.
+	WALMaxAge time.Duration
+
+	// IdempotencyWindow is how long the server remembers a
+	// RememberInput.IdempotencyKey. Zero uses the server default,
+	// DefaultIdempotencyWindow.
+	IdempotencyWindow time.Duration
+
+	// SkipSchemaCheck disables the storage schema check NewClient performs
+	// after connecting; see Client.SchemaVersion.
+	SkipSchemaCheck bool
+
+	// JSONEncoder and JSONDecoder replace encoding/json for the messages
+	// exchanged with the server, for example with a faster implementation.
+	// Nil uses encoding/json.
+	JSONEncoder JSONEncoder
+	JSONDecoder JSONDecoder
+
+	// ToolAliases maps SDK tool names, such as "mnemo.remember", to the
+	// names a patched server uses instead. Tools not listed keep their SDK
+	// names. Interceptors and errors still see the SDK name.
+	ToolAliases map[string]string
+
+	// DialFunc, when set, opens the connection to an already running mnemo
+	// server, for example over a Unix socket or TCP, instead of spawning a
+	// child process. Command, the CLI flag options, ExtraArgs, LogLevel, and
+	// CaptureStderr are ignored. Close closes the connection.
+	DialFunc func() (io.ReadWriteCloser, error)
+
+	// UnixSocket, when set and DialFunc is nil, connects to a mnemo server
+	// listening on this Unix domain socket instead of spawning a child
+	// process. Messages are newline-delimited JSON as over stdio. The
+	// process options are ignored as for DialFunc.
+	UnixSocket string
+
+	// HTTPURL, when set and DialFunc and UnixSocket are unset, sends JSON-RPC
+	// requests as HTTP POSTs to HTTPURL + "/rpc" instead of spawning a child
+	// process. The process options are ignored as for DialFunc.
+	HTTPURL string
+
+	// HTTPToken is sent as a bearer token with every HTTPURL request.
+	HTTPToken string
+
+	// HTTPClient sends HTTPURL requests. Defaults to a client with a 30s
+	// timeout. Each request also ends when the call's context is done.
+	HTTPClient *http.Client
+
+	// Multiplexing lets concurrent calls share the connection instead of
+	// taking turns: each call waits only to send, and a background reader
+	// hands every response to the call with the matching ID. The server
+	// must accept pipelined requests. Ignored for HTTPURL.
+	Multiplexing bool
+
+	// WarmPoolSize is the number of clients NewClientPool starts up front.
+	// NewClient ignores it.
+	WarmPoolSize int
+
+	// SeedParallelism caps how many Remember calls SeedMemories has in
+	// flight at once. Zero means 4.
+	SeedParallelism int
+
+	// ProtectedBranches lists branch names that Checkpoint and Branch refuse
+	// to write to, returning a *ProtectedBranchError without contacting the
+	// server. Use it to guard "main" against accidental writes.
+	ProtectedBranches []string
+
+	// ScopeHierarchy lists, for each scope, the broader scopes a recall in
+	// that scope also searches. For example, {"private": {"shared",
+	// "public"}} makes a private-scope Recall return shared and public
+	// memories too. It applies when RecallInput.Scope is set and
+	// RecallInput.Scopes is not.
+	ScopeHierarchy map[Scope][]Scope
+}
+
+const (
+	// defaultMaxResponseBytes is the response size limit when
+	// ClientOptions.MaxResponseBytes is unset.
+	defaultMaxResponseBytes = 1024 * 1024
+
+	// compressThreshold is the argument size above which CompressMessages
+	// takes effect.
+	compressThreshold = 64 * 1024
+
+	// contentEncodingGzip marks tool arguments as gzip-compressed,
+	// base64-encoded JSON.
+	contentEncodingGzip = "gzip+base64"
+)
+
+// DefaultProtocolVersion is the MCP protocol version requested when
+// ClientOptions.ProtocolVersion is unset.
+const DefaultProtocolVersion = "2024-11-05"
+
+// DefaultIdempotencyWindow is how long the server honours an idempotency key
+// when ClientOptions.IdempotencyWindow is unset.
+const DefaultIdempotencyWindow = 24 * time.Hour
+
+// MemoryClient is the context-aware tool surface implemented by Client. It
+// lets wrappers such as InstrumentedClient decorate a Client, and lets tests
+// substitute a fake.
+type MemoryClient interface {
+	RememberContext(ctx context.Context, input RememberInput) (*RememberResponse, error)
+	RecallContext(ctx context.Context, input RecallInput) (*RecallResponse, error)
+	ForgetContext(ctx context.Context, input ForgetInput) (*ForgetResponse, error)
+	ShareContext(ctx context.Context, input ShareInput) (*ShareResponse, error)
+	CheckpointContext(ctx context.Context, input CheckpointInput) (*CheckpointResponse, error)
+	BranchContext(ctx context.Context, input BranchInput) (*BranchResponse, error)
+	MergeContext(ctx context.Context, input MergeInput) (*MergeResponse, error)
+	ReplayContext(ctx context.Context, input ReplayInput) (*ReplayResponse, error)
+	VerifyContext(ctx context.Context, input VerifyInput) (*VerifyResponse, error)
+	DelegateContext(ctx context.Context, input DelegateInput) (*DelegateResponse, error)
+}
+
+// Client communicates with a mnemo MCP server process over STDIO.
+//
+// All exported methods are safe for concurrent use. The client manages the
+// lifecycle of the child process; call Close when done.
+type Client struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Scanner
+	stderr *io.PipeReader
+	opts   ClientOptions
+	nextID int
+	mu     sync.Mutex
+
+	// exchangeMu is held by a non-multiplexed call from sending its request
+	// until its response is read, even if the caller stops waiting. broken,
+	// guarded by exchangeMu, records why the connection can no longer be
+	// used.
+	exchangeMu ctxMutex
+	broken     error
+
+	// http replaces stdin and stdout when ClientOptions.HTTPURL is set.
+	http *httpTransport
+
+	// mux routes responses to their callers when ClientOptions.Multiplexing
+	// is set.
+	mux *multiplexer
+
+	startedAt time.Time
+
+	// protocolVersion is the version agreed during initialize.
+	protocolVersion string
+
+	// debug receives raw protocol traffic when set by SetDebug. It has its
+	// own lock because requests and responses are logged without holding
+	// mu.
+	debugMu sync.Mutex
+	debug   io.Writer
+
+	// lifecycleMu guards closed and the Add side of inflight, so no call can
+	// start once draining has begun.
+	lifecycleMu sync.Mutex
+	closed      bool
+	inflight    sync.WaitGroup
+
+	interceptorMu sync.RWMutex
+	interceptors  []Interceptor
+}
+
+// NewClient spawns a mnemo MCP server as a child process and performs the MCP
+// initialization handshake. If ClientOptions.DialFunc, UnixSocket, or HTTPURL
+// is set, NewClient connects through it instead of spawning a process.
+//
+// The caller must call Close when finished to terminate the child process and
+// release resources.
+func NewClient(opts ClientOptions, options ...Option) (*Client, error) {
+	for _, o := range options {
+		o(&opts)
+	}
+
+	if opts.DialFunc == nil && opts.UnixSocket != "" {
+		path := opts.UnixSocket
+		opts.DialFunc = func() (io.ReadWriteCloser, error) {
+			return net.Dial("unix", path)
+		}
+	}
+
+	if opts.DialFunc != nil {
+		conn, err := opts.DialFunc()
+		if err != nil {
+			return nil, fmt.Errorf("mnemo: dial: %w", err)
+		}
+		c := &Client{
+			stdin:     conn,
+			stdout:    newResponseScanner(conn, opts.MaxResponseBytes),
+			opts:      opts,
+			startedAt: time.Now(),
+		}
+		if err := c.connect(); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+
+	if opts.HTTPURL != "" {
+		c := &Client{
+			http:      newHTTPTransport(opts.HTTPURL, opts.HTTPToken, opts.HTTPClient),
+			opts:      opts,
+			startedAt: time.Now(),
+		}
+		if err := c.connect(); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+
+	command := opts.Command
+	if command == "" {
+		command = "mnemo"
+	}
+
+	args, err := buildArgs(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(command, args...)
+	cmd.Stderr = nil // let mnemo's stderr go to /dev/null by default
+	if opts.LogLevel != "" {
+		cmd.Env = append(os.Environ(), "RUST_LOG=mnemo="+opts.LogLevel)
+	}
+
+	var stderrR *io.PipeReader
+	closeStderr := func() {}
+	if opts.CaptureStderr {
+		var stderrW *io.PipeWriter
+		stderrR, stderrW = io.Pipe()
+		cmd.Stderr = &stderrSink{w: stderrW}
+		closeStderr = func() {
+			_ = stderrW.Close()
+			_ = stderrR.Close()
+		}
+	}
+
+	stdinPipe, err := cmd.StdinPipe()
+	if err != nil {
+		closeStderr()
+		return nil, fmt.Errorf("mnemo: failed to create stdin pipe: %w", err)
+	}
+
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		_ = stdinPipe.Close()
+		closeStderr()
+		return nil, fmt.Errorf("mnemo: failed to create stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		_ = stdinPipe.Close()
+		closeStderr()
+		return nil, fmt.Errorf("mnemo: failed to start process: %w", err)
+	}
+
+	c := &Client{
+		cmd:       cmd,
+		stdin:     stdinPipe,
+		stdout:    newResponseScanner(stdoutPipe, opts.MaxResponseBytes),
+		stderr:    stderrR,
+		opts:      opts,
+		nextID:    0,
+		startedAt: time.Now(),
+	}
+
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// connect runs the initialization handshake and the checks that follow it
+// on a freshly opened transport. On failure the client is closed.
+func (c *Client) connect() error {
+	if err := c.initialize(); err != nil {
+		_ = c.Close()
+		return fmt.Errorf("mnemo: initialization failed: %w", err)
+	}
+
+	if c.opts.Multiplexing && c.http == nil {
+		c.mux = newMultiplexer()
+		go c.readLoop()
+	}
+
+	if !c.opts.SkipSchemaCheck {
+		if err := c.checkSchema(context.Background()); err != nil {
+			_ = c.Close()
+			return err
+		}
+	}
+
+	if c.opts.WALPath != "" {
+		// Replay writes that failed before this client connected. Entries
+		// that still fail stay in the log.
+		if _, err := c.FlushWAL(context.Background()); err != nil {
+			c.warn(err)
+		}
+	}
+	return nil
+}
+
+// NewClientWithProtocolVersion is like NewClient but pins the MCP protocol
+// version requested during the initialize handshake.
+func NewClientWithProtocolVersion(opts ClientOptions, version string, options ...Option) (*Client, error) {
+	opts.ProtocolVersion = version
+	return NewClient(opts, options...)
+}
+
+// ProtocolVersion returns the MCP protocol version reported by the server
+// during the initialize handshake, or the requested version if the server
+// did not report one.
+func (c *Client) ProtocolVersion() string {
+	return c.protocolVersion
+}
+
+// Close terminates the child process and releases all resources. Calls made
+// after Close fail with ErrClientClosed. Close does not wait for calls that
+// have not yet been sent; use GracefulClose for that.
+func (c *Client) Close() error {
+	c.lifecycleMu.Lock()
+	c.closed = true
+	c.lifecycleMu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stdin != nil {
+		_ = c.stdin.Close()
+	}
+	if c.cmd == nil {
+		return nil
+	}
+	// Wait does not return until exec's stderr copier finishes, which never
+	// happens while the copier is blocked on a caller that stopped draining
+	// ReadStderr.
+	if sink, ok := c.cmd.Stderr.(*stderrSink); ok {
+		sink.close()
+	}
+	return c.cmd.Wait()
+}
+
+// GracefulClose stops the client from accepting new calls, which then fail
+// with ErrClientClosed, waits for calls already in flight to finish, and
+// then calls Close.
+//
+// If ctx is done before the in-flight calls finish, GracefulClose returns
+// ctx.Err() without closing; the client keeps rejecting new calls and the
+// caller may retry or call Close.
+func (c *Client) GracefulClose(ctx context.Context) error {
+	c.lifecycleMu.Lock()
+	c.closed = true
+	c.lifecycleMu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		c.inflight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return c.Close()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WarmUp sends a throwaway recall so the server loads its embedding model
+// now rather than on the first real Recall. The result is discarded. The
+// call is bounded by ClientOptions.WarmUpTimeout when set.
+func (c *Client) WarmUp(ctx context.Context) error {
+	if c.opts.WarmUpTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opts.WarmUpTimeout)
+		defer cancel()
+	}
+
+	limit := 1
+	var resp RecallResponse
+	return c.callTool(ctx, "mnemo.recall", RecallInput{Query: "_warmup_", Limit: &limit}, &resp)
+}
+
+// SchemaVersion reports the server's storage schema version and whether a
+// migration must run before the database can be used.
+func (c *Client) SchemaVersion() (*SchemaVersionResponse, error) {
+	return c.SchemaVersionContext(context.Background())
+}
+
+// SchemaVersionContext is like SchemaVersion but takes a context.
+func (c *Client) SchemaVersionContext(ctx context.Context) (*SchemaVersionResponse, error) {
+	var resp SchemaVersionResponse
+	if err := c.callTool(ctx, "mnemo.schema_version", struct{}{}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// checkSchema returns a *SchemaMigrationRequiredError if the server's
+// storage needs migrating. Servers that cannot report a schema version are
+// assumed compatible; the failure is passed to warn.
+func (c *Client) checkSchema(ctx context.Context) error {
+	resp, err := c.SchemaVersionContext(ctx)
+	if err != nil {
+		c.warn(fmt.Errorf("mnemo: schema check skipped: %w", err))
+		return nil
+	}
+	if resp.MigrationRequired {
+		return &SchemaMigrationRequiredError{Version: resp.Version, MinCompatible: resp.MinCompatible}
+	}
+	return nil
+}
+
+// HealthCheck asks the server to check its storage, embedding model, and hash
+// chain. Unlike a liveness probe it exercises each dependency. If the server
+// reports anything other than HealthOK, HealthCheck returns both the status
+// and a *DegradedError.
+func (c *Client) HealthCheck(ctx context.Context) (*HealthStatus, error) {
+	var status HealthStatus
+	if err := c.callTool(ctx, "mnemo.health", struct{}{}, &status); err != nil {
+		return nil, err
+	}
+	if status.Status != HealthOK {
+		return &status, &DegradedError{Status: status.Status, Details: status.Details}
+	}
+	return &status, nil
+}
+
+// PID returns the process ID of the mnemo child process, or 0 if the client
+// has no child process.
+func (c *Client) PID() int {
+	if c.cmd == nil || c.cmd.Process == nil {
+		return 0
+	}
+	return c.cmd.Process.Pid
+}
+
+// ProcessState returns the exit state of the mnemo child process. It is nil
+// until the process has exited and been reaped by Close.
+func (c *Client) ProcessState() *os.ProcessState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cmd == nil {
+		return nil
+	}
+	return c.cmd.ProcessState
+}
+
+// ReadStderr returns a reader over the mnemo process stderr. It yields data
+// only when ClientOptions.CaptureStderr is set; otherwise it is always at
+// EOF. The reader reaches EOF after Close.
+func (c *Client) ReadStderr() io.Reader {
+	if c.stderr == nil {
+		return strings.NewReader("")
+	}
+	return c.stderr
+}
+
+// stderrSink forwards the process stderr to the pipe behind ReadStderr until
+// it is closed, then discards it. Discarding rather than failing keeps the
+// process from being killed by SIGPIPE while it shuts down.
+type stderrSink struct {
+	w      *io.PipeWriter
+	closed atomic.Bool
+}
+
+// Write implements io.Writer.
+func (s *stderrSink) Write(p []byte) (int, error) {
+	if s.closed.Load() {
+		return len(p), nil
+	}
+	n, err := s.w.Write(p)
+	if err != nil && s.closed.Load() {
+		return len(p), nil
+	}
+	return n, err
+}
+
+// close ends the reader with EOF and unblocks any pending Write.
+func (s *stderrSink) close() {
+	s.closed.Store(true)
+	_ = s.w.Close()
+}
+
+// ReadStderrLines scans ReadStderr line by line in a background goroutine
+// and delivers each line on the returned channel. The channel is closed when
+// stderr reaches EOF.
+func (c *Client) ReadStderrLines() <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(c.ReadStderr())
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	return lines
+}
+
+// Remember stores a new memory and returns its ID and content hash.
+func (c *Client) Remember(input RememberInput) (*RememberResponse, error) {
+	return c.RememberContext(context.Background(), input)
+}
+
+// RememberContext is like Remember but takes a context.
+//
+// When ClientOptions.WALPath is set, a remember that fails because the
+// mnemo process could not be reached is appended to the write-ahead log and
+// replayed by FlushWAL.
+//
+// A custom MemoryType registered in DefaultMemoryTypes supplies Importance
+// and DecayRate when they are unset.
+func (c *Client) RememberContext(ctx context.Context, input RememberInput) (*RememberResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	input = applyMemoryTypeDefaults(input)
+	if c.opts.WALPath == "" {
+		return c.remember(ctx, input)
+	}
+
+	if input.IdempotencyKey == nil {
+		// A replay must not duplicate a write that reached the server just
+		// before the connection failed.
+		key, err := newIdempotencyKey()
+		if err != nil {
+			return nil, err
+		}
+		input.IdempotencyKey = &key
+	}
+	resp, err := c.remember(ctx, input)
+	if err != nil && isTransportError(err) {
+		if werr := c.appendWAL(input); werr != nil {
+			c.warn(werr)
+		}
+	}
+	return resp, err
+}
+
+// remember sends a remember request without write-ahead logging.
+func (c *Client) remember(ctx context.Context, input RememberInput) (*RememberResponse, error) {
+	if t := c.opts.CompressContentThreshold; t > 0 && len(input.Content) > t {
+		var err error
+		if input, err = compressContent(input); err != nil {
+			return nil, fmt.Errorf("mnemo mnemo.remember: compress content: %w", err)
+		}
+	}
+
+	var args interface{} = input
+	if input.IdempotencyKey != nil && c.opts.IdempotencyWindow > 0 {
+		args = rememberIdempotentArgs{
+			RememberInput:            input,
+			IdempotencyWindowSeconds: uint64(c.opts.IdempotencyWindow / time.Second),
+		}
+	}
+
+	var resp RememberResponse
+	if err := c.callTool(ctx, "mnemo.remember", args, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RememberIdempotent is like RememberContext but sets input.IdempotencyKey to
+// key, so retrying with the same key within the idempotency window returns
+// the original memory instead of storing a duplicate.
+func (c *Client) RememberIdempotent(ctx context.Context, input RememberInput, key string) (*RememberResponse, error) {
+	if key == "" {
+		return nil, &ValidationError{Field: "idempotency_key", Message: "is required"}
+	}
+	input.IdempotencyKey = &key
+	return c.RememberContext(ctx, input)
+}
+
+// Recall searches memories by semantic similarity and filters.
+func (c *Client) Recall(input RecallInput) (*RecallResponse, error) {
+	return c.RecallContext(context.Background(), input)
+}
+
+// RecallContext is like Recall but takes a context.
+func (c *Client) RecallContext(ctx context.Context, input RecallInput) (*RecallResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if len(input.Boost) > 0 && input.Strategy != nil && *input.Strategy == "lexical" {
+		slog.DebugContext(ctx, "mnemo: recall boost has no effect with the lexical strategy",
+			"boost", input.Boost)
+	}
+	if input.Scope != nil && len(input.Scopes) == 0 {
+		input.Scopes = expandScope(c.opts.ScopeHierarchy, Scope(*input.Scope))
+	}
+	var resp RecallResponse
+	if err := c.callTool(ctx, "mnemo.recall", input, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Total == 0 && input.FallbackStrategy != nil {
+		fallback := input
+		fallback.Strategy = input.FallbackStrategy
+		resp = RecallResponse{}
+		if err := c.callTool(ctx, "mnemo.recall", fallback, &resp); err != nil {
+			return nil, err
+		}
+		resp.FallbackAttempted = true
+		resp.FallbackStrategyUsed = input.FallbackStrategy
+	}
+	if err := decompressMemories(resp.Memories); err != nil {
+		return nil, err
+	}
+	applyRecallFilters(input, &resp)
+	return &resp, nil
+}
+
+// Forget deletes or archives memories by ID or criteria. Set
+// ForgetInput.DryRun to preview the affected IDs first; a dry run reports
+// Status "dry_run", not "forgotten".
+func (c *Client) Forget(input ForgetInput) (*ForgetResponse, error) {
+	return c.ForgetContext(context.Background(), input)
+}
+
+// ForgetContext is like Forget but takes a context.
+func (c *Client) ForgetContext(ctx context.Context, input ForgetInput) (*ForgetResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	var resp ForgetResponse
+	if err := c.callTool(ctx, "mnemo.forget", input, &resp); err != nil {
+		var memoryID string
+		if len(input.MemoryIDs) == 1 {
+			memoryID = input.MemoryIDs[0]
+		}
+		return nil, asPermissionDenied(err, memoryID, PermissionDelete)
+	}
+	return &resp, nil
+}
+
+// Share grants another agent access to a memory.
+func (c *Client) Share(input ShareInput) (*ShareResponse, error) {
+	return c.ShareContext(context.Background(), input)
+}
+
+// ShareContext is like Share but takes a context.
+func (c *Client) ShareContext(ctx context.Context, input ShareInput) (*ShareResponse, error) {
+	var resp ShareResponse
+	if err := c.callTool(ctx, "mnemo.share", input, &resp); err != nil {
+		return nil, asPermissionDenied(err, input.MemoryID, PermissionShare)
+	}
+	return &resp, nil
+}
+
+// Checkpoint creates a snapshot of the current agent state.
+func (c *Client) Checkpoint(input CheckpointInput) (*CheckpointResponse, error) {
+	return c.CheckpointContext(context.Background(), input)
+}
+
+// CheckpointContext is like Checkpoint but takes a context.
+func (c *Client) CheckpointContext(ctx context.Context, input CheckpointInput) (*CheckpointResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	branch := "main"
+	if input.BranchName != nil {
+		branch = *input.BranchName
+	}
+	if err := c.checkProtectedBranch(branch); err != nil {
+		return nil, err
+	}
+	if input.Compress {
+		snapshot, err := compressSnapshot(input.StateSnapshot)
+		if err != nil {
+			return nil, fmt.Errorf("mnemo mnemo.checkpoint: compress snapshot: %w", err)
+		}
+		input.StateSnapshot = snapshot
+	}
+	var resp CheckpointResponse
+	if err := c.callTool(ctx, "mnemo.checkpoint", input, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CheckpointAndPrepareContext creates a checkpoint and then recalls the
+// memories matching input.RecallQuery, so an agent can snapshot its state and
+// load the context for its next step in one call. If the checkpoint fails,
+// Recall is not called. If the recall fails, the checkpoint response is
+// still returned alongside the error.
+func (c *Client) CheckpointAndPrepareContext(ctx context.Context, input CheckpointAndPrepareContextInput) (*CheckpointAndPrepareContextResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	cp, err := c.CheckpointContext(ctx, input.CheckpointInput)
+	if err != nil {
+		return nil, err
+	}
+	recall, err := c.RecallContext(ctx, RecallInput{Query: input.RecallQuery, Limit: input.RecallLimit})
+	if err != nil {
+		return &CheckpointAndPrepareContextResponse{Checkpoint: cp}, err
+	}
+	return &CheckpointAndPrepareContextResponse{Checkpoint: cp, Recall: recall}, nil
+}
+
+// Branch forks the current state into a new named branch.
+func (c *Client) Branch(input BranchInput) (*BranchResponse, error) {
+	return c.BranchContext(context.Background(), input)
+}
+
+// BranchContext is like Branch but takes a context.
+func (c *Client) BranchContext(ctx context.Context, input BranchInput) (*BranchResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if err := c.checkProtectedBranch(input.NewBranchName); err != nil {
+		return nil, err
+	}
+	var resp BranchResponse
+	if err := c.callTool(ctx, "mnemo.branch", input, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Merge combines a source branch into a target branch.
+func (c *Client) Merge(input MergeInput) (*MergeResponse, error) {
+	return c.MergeContext(context.Background(), input)
+}
+
+// MergeContext is like Merge but takes a context.
+func (c *Client) MergeContext(ctx context.Context, input MergeInput) (*MergeResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	var resp MergeResponse
+	if err := c.callTool(ctx, "mnemo.merge", input, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Replay reconstructs the agent context at a specific checkpoint.
+func (c *Client) Replay(input ReplayInput) (*ReplayResponse, error) {
+	return c.ReplayContext(context.Background(), input)
+}
+
+// ReplayContext is like Replay but takes a context.
+func (c *Client) ReplayContext(ctx context.Context, input ReplayInput) (*ReplayResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	var resp ReplayResponse
+	if err := c.callTool(ctx, "mnemo.replay", input, &resp); err != nil {
+		return nil, err
+	}
+	snapshot, err := DecompressSnapshot(resp.Checkpoint.StateSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	resp.Checkpoint.StateSnapshot = snapshot
+	for i := range resp.Memories {
+		m := &resp.Memories[i]
+		content, err := decompressText(m.Content)
+		if err != nil {
+			return nil, fmt.Errorf("mnemo: decompress memory %s: %w", m.ID, err)
+		}
+		m.Content = content
+		delete(m.Metadata, compressedMetadataKey)
+	}
+	return &resp, nil
+}
+
+// Verify checks the hash chain integrity of stored memories.
+func (c *Client) Verify(input VerifyInput) (*VerifyResponse, error) {
+	return c.VerifyContext(context.Background(), input)
+}
+
+// VerifyContext is like Verify but takes a context.
+func (c *Client) VerifyContext(ctx context.Context, input VerifyInput) (*VerifyResponse, error) {
+	var resp VerifyResponse
+	if err := c.callTool(ctx, "mnemo.verify", input, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Delegate grants scoped, time-bounded permissions to another agent.
+func (c *Client) Delegate(input DelegateInput) (*DelegateResponse, error) {
+	return c.DelegateContext(context.Background(), input)
+}
+
+// DelegateContext is like Delegate but takes a context.
+func (c *Client) DelegateContext(ctx context.Context, input DelegateInput) (*DelegateResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	var resp DelegateResponse
+	if err := c.callTool(ctx, "mnemo.delegate", input, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// PinMemory protects a memory from expiry and automatic forgetting.
+func (c *Client) PinMemory(input PinMemoryInput) (*PinMemoryResponse, error) {
+	return c.PinMemoryContext(context.Background(), input)
+}
+
+// PinMemoryContext is like PinMemory but takes a context.
+func (c *Client) PinMemoryContext(ctx context.Context, input PinMemoryInput) (*PinMemoryResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	var resp PinMemoryResponse
+	if err := c.callTool(ctx, "mnemo.pin_memory", input, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UnpinMemory removes a pin set by PinMemory.
+func (c *Client) UnpinMemory(input UnpinMemoryInput) (*UnpinMemoryResponse, error) {
+	return c.UnpinMemoryContext(context.Background(), input)
+}
+
+// UnpinMemoryContext is like UnpinMemory but takes a context.
+func (c *Client) UnpinMemoryContext(ctx context.Context, input UnpinMemoryInput) (*UnpinMemoryResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	var resp UnpinMemoryResponse
+	if err := c.callTool(ctx, "mnemo.unpin_memory", input, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TagMemories adds and removes tags across multiple memories in one call.
+func (c *Client) TagMemories(input TagMemoriesInput) (*TagMemoriesResponse, error) {
+	return c.TagMemoriesContext(context.Background(), input)
+}
+
+// TagMemoriesContext is like TagMemories but takes a context.
+func (c *Client) TagMemoriesContext(ctx context.Context, input TagMemoriesInput) (*TagMemoriesResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	var resp TagMemoriesResponse
+	if err := c.callTool(ctx, "mnemo.tag_memories", input, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListTags enumerates the distinct tags in the agent's memory store.
+func (c *Client) ListTags(input ListTagsInput) (*ListTagsResponse, error) {
+	return c.ListTagsContext(context.Background(), input)
+}
+
+// ListTagsContext is like ListTags but takes a context.
+func (c *Client) ListTagsContext(ctx context.Context, input ListTagsInput) (*ListTagsResponse, error) {
+	var resp ListTagsResponse
+	if err := c.callTool(ctx, "mnemo.list_tags", input, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RenameTag renames a tag on every memory that carries it.
+func (c *Client) RenameTag(input RenameTagInput) (*RenameTagResponse, error) {
+	return c.RenameTagContext(context.Background(), input)
+}
+
+// RenameTagContext is like RenameTag but takes a context.
+func (c *Client) RenameTagContext(ctx context.Context, input RenameTagInput) (*RenameTagResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	var resp RenameTagResponse
+	if err := c.callTool(ctx, "mnemo.rename_tag", input, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// LookupByHash retrieves a memory by its content hash, for example to probe
+// for an existing memory before calling Remember.
+func (c *Client) LookupByHash(input LookupByHashInput) (*LookupByHashResponse, error) {
+	return c.LookupByHashContext(context.Background(), input)
+}
+
+// LookupByHashContext is like LookupByHash but takes a context.
+func (c *Client) LookupByHashContext(ctx context.Context, input LookupByHashInput) (*LookupByHashResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	var resp LookupByHashResponse
+	if err := c.callTool(ctx, "mnemo.lookup_hash", input, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Memory != nil {
+		if err := decompressContent(resp.Memory); err != nil {
+			return nil, err
+		}
+	}
+	return &resp, nil
+}
+
+// CopyMemory creates an independent copy of a memory for another agent or
+// organization.
+func (c *Client) CopyMemory(input CopyMemoryInput) (*CopyMemoryResponse, error) {
+	return c.CopyMemoryContext(context.Background(), input)
+}
 
-	// OrgID is the default organization identifier. Passed as --org-id.
-	OrgID string
+// CopyMemoryContext is like CopyMemory but takes a context.
+func (c *Client) CopyMemoryContext(ctx context.Context, input CopyMemoryInput) (*CopyMemoryResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	var resp CopyMemoryResponse
+	if err := c.callTool(ctx, "mnemo.copy_memory", input, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
 
-	// OpenAIKey is the OpenAI API key for embeddings. Passed as
-	// --openai-api-key.
-	OpenAIKey string
+// Transaction applies several operations atomically. If any operation fails
+// the whole transaction is rolled back and Status is "rolled_back". Use
+// BuildTransaction to assemble the input.
+func (c *Client) Transaction(input TransactionInput) (*TransactionResponse, error) {
+	return c.TransactionContext(context.Background(), input)
+}
 
-	// Dimensions sets the embedding vector dimensions. Passed as --dimensions.
-	Dimensions int
+// TransactionContext is like Transaction but takes a context.
+func (c *Client) TransactionContext(ctx context.Context, input TransactionInput) (*TransactionResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	var resp TransactionResponse
+	if err := c.callTool(ctx, "mnemo.transaction", input, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
 }
 
-// Client communicates with a mnemo MCP server process over STDIO.
-//
-// All exported methods are safe for concurrent use. The client manages the
-// lifecycle of the child process; call Close when done.
-type Client struct {
-	cmd    *exec.Cmd
-	stdin  io.WriteCloser
-	stdout *bufio.Scanner
-	nextID int
-	mu     sync.Mutex
+// ArchiveRecall searches archived memories, which Recall does not return.
+// Results have Status "archived"; use RestoreFromArchive to make them active
+// again.
+func (c *Client) ArchiveRecall(input ArchiveRecallInput) (*RecallResponse, error) {
+	return c.ArchiveRecallContext(context.Background(), input)
 }
 
-// NewClient spawns a mnemo MCP server as a child process and performs the MCP
-// initialization handshake.
-//
-// The caller must call Close when finished to terminate the child process and
-// release resources.
-func NewClient(opts ClientOptions) (*Client, error) {
-	command := opts.Command
-	if command == "" {
-		command = "mnemo"
+// ArchiveRecallContext is like ArchiveRecall but takes a context.
+func (c *Client) ArchiveRecallContext(ctx context.Context, input ArchiveRecallInput) (*RecallResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
 	}
+	var resp RecallResponse
+	if err := c.callTool(ctx, "mnemo.recall_archive", input, &resp); err != nil {
+		return nil, err
+	}
+	if err := decompressMemories(resp.Memories); err != nil {
+		return nil, err
+	}
+	applyRecallFilters(input.RecallInput, &resp)
+	return &resp, nil
+}
 
-	args := buildArgs(opts)
-
-	cmd := exec.Command(command, args...)
-	cmd.Stderr = nil // let mnemo's stderr go to /dev/null by default
+// RestoreFromArchive moves archived memories back to active storage so
+// Recall returns them again.
+func (c *Client) RestoreFromArchive(ctx context.Context, memoryIDs []string) (*RestoreResponse, error) {
+	if len(memoryIDs) == 0 {
+		return nil, &ValidationError{Field: "memory_ids", Message: "at least one memory ID is required"}
+	}
+	var resp RestoreResponse
+	if err := c.callTool(ctx, "mnemo.restore_archive", restoreArchiveInput{MemoryIDs: memoryIDs}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
 
-	stdinPipe, err := cmd.StdinPipe()
+// CanAccess reports whether the calling agent holds permission on the
+// memory, so agents can avoid attempting operations that would be denied.
+// Use CanAccessContext to also learn why access was denied.
+func (c *Client) CanAccess(ctx context.Context, memoryID, permission string) (bool, error) {
+	resp, err := c.CanAccessContext(ctx, CanAccessInput{MemoryID: memoryID, Permission: permission})
 	if err != nil {
-		return nil, fmt.Errorf("mnemo: failed to create stdin pipe: %w", err)
+		return false, err
 	}
+	return resp.Allowed, nil
+}
 
-	stdoutPipe, err := cmd.StdoutPipe()
-	if err != nil {
-		_ = stdinPipe.Close()
-		return nil, fmt.Errorf("mnemo: failed to create stdout pipe: %w", err)
+// CanAccessContext is like CanAccess but takes a CanAccessInput and returns
+// the full response, including the server's Reason for a denial.
+func (c *Client) CanAccessContext(ctx context.Context, input CanAccessInput) (*CanAccessResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
 	}
+	var resp CanAccessResponse
+	if err := c.callTool(ctx, "mnemo.can_access", input, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
 
-	if err := cmd.Start(); err != nil {
-		_ = stdinPipe.Close()
-		return nil, fmt.Errorf("mnemo: failed to start process: %w", err)
+// BatchCheckAccess checks several memory permissions in a single call.
+func (c *Client) BatchCheckAccess(input BatchCheckAccessInput) (*BatchCheckAccessResponse, error) {
+	return c.BatchCheckAccessContext(context.Background(), input)
+}
+
+// BatchCheckAccessContext is like BatchCheckAccess but takes a context.
+func (c *Client) BatchCheckAccessContext(ctx context.Context, input BatchCheckAccessInput) (*BatchCheckAccessResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	var resp BatchCheckAccessResponse
+	if err := c.callTool(ctx, "mnemo.batch_check_access", input, &resp); err != nil {
+		return nil, err
 	}
+	return &resp, nil
+}
 
-	scanner := bufio.NewScanner(stdoutPipe)
-	// Increase buffer for large JSON responses (1 MB).
-	scanner.Buffer(make([]byte, 0, 1024*1024), 1024*1024)
+// FilterAccessible returns the subset of memoryIDs on which the calling agent
+// holds permission, preserving their order. It issues one BatchCheckAccess
+// call; an empty memoryIDs returns nil without contacting the server.
+func (c *Client) FilterAccessible(ctx context.Context, memoryIDs []string, permission string) ([]string, error) {
+	if len(memoryIDs) == 0 {
+		return nil, nil
+	}
 
-	c := &Client{
-		cmd:    cmd,
-		stdin:  stdinPipe,
-		stdout: scanner,
-		nextID: 0,
+	checks := make([]CanAccessInput, len(memoryIDs))
+	for i, id := range memoryIDs {
+		checks[i] = CanAccessInput{MemoryID: id, Permission: permission}
+	}
+	resp, err := c.BatchCheckAccessContext(ctx, BatchCheckAccessInput{Checks: checks})
+	if err != nil {
+		return nil, err
 	}
 
-	if err := c.initialize(); err != nil {
-		_ = c.Close()
-		return nil, fmt.Errorf("mnemo: initialization failed: %w", err)
+	allowed := make(map[string]bool, len(resp.Results))
+	for _, r := range resp.Results {
+		if r.Allowed && r.Permission == permission {
+			allowed[r.MemoryID] = true
+		}
+	}
+	var out []string
+	for _, id := range memoryIDs {
+		if allowed[id] {
+			out = append(out, id)
+		}
 	}
+	return out, nil
+}
 
-	return c, nil
+// ListAuditEvents returns audit log events recording who accessed or
+// modified which memory and when.
+func (c *Client) ListAuditEvents(input AuditQueryInput) (*AuditResponse, error) {
+	return c.ListAuditEventsContext(context.Background(), input)
 }
 
-// Close terminates the child process and releases all resources.
-func (c *Client) Close() error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// ListAuditEventsContext is like ListAuditEvents but takes a context.
+func (c *Client) ListAuditEventsContext(ctx context.Context, input AuditQueryInput) (*AuditResponse, error) {
+	var resp AuditResponse
+	if err := c.callTool(ctx, "mnemo.audit_query", input, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
 
-	_ = c.stdin.Close()
-	return c.cmd.Wait()
+// Vacuum permanently removes soft-deleted memories and reclaims the disk
+// space they occupy.
+func (c *Client) Vacuum(input VacuumInput) (*VacuumResponse, error) {
+	return c.VacuumContext(context.Background(), input)
 }
 
-// Remember stores a new memory and returns its ID and content hash.
-func (c *Client) Remember(input RememberInput) (*RememberResponse, error) {
-	var resp RememberResponse
-	if err := c.callTool("mnemo.remember", input, &resp); err != nil {
+// VacuumContext is like Vacuum but takes a context.
+func (c *Client) VacuumContext(ctx context.Context, input VacuumInput) (*VacuumResponse, error) {
+	var resp VacuumResponse
+	if err := c.callTool(ctx, "mnemo.vacuum", input, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
-// Recall searches memories by semantic similarity and filters.
-func (c *Client) Recall(input RecallInput) (*RecallResponse, error) {
-	var resp RecallResponse
-	if err := c.callTool("mnemo.recall", input, &resp); err != nil {
+// SummarizeThread asks the server to summarize a conversation thread with
+// its language model, optionally storing the summary as a memory.
+func (c *Client) SummarizeThread(input SummarizeInput) (*SummaryResponse, error) {
+	return c.SummarizeThreadContext(context.Background(), input)
+}
+
+// SummarizeThreadContext is like SummarizeThread but takes a context.
+func (c *Client) SummarizeThreadContext(ctx context.Context, input SummarizeInput) (*SummaryResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	var resp SummaryResponse
+	if err := c.callTool(ctx, "mnemo.summarize_thread", input, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
-// Forget deletes or archives memories by ID or criteria.
-func (c *Client) Forget(input ForgetInput) (*ForgetResponse, error) {
-	var resp ForgetResponse
-	if err := c.callTool("mnemo.forget", input, &resp); err != nil {
+// CreateGroup defines a named group of agents that Share can target with
+// ShareInput.GroupID.
+func (c *Client) CreateGroup(input CreateGroupInput) (*CreateGroupResponse, error) {
+	return c.CreateGroupContext(context.Background(), input)
+}
+
+// CreateGroupContext is like CreateGroup but takes a context.
+func (c *Client) CreateGroupContext(ctx context.Context, input CreateGroupInput) (*CreateGroupResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	var resp CreateGroupResponse
+	if err := c.callTool(ctx, "mnemo.create_group", input, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
-// Share grants another agent access to a memory.
-func (c *Client) Share(input ShareInput) (*ShareResponse, error) {
-	var resp ShareResponse
-	if err := c.callTool("mnemo.share", input, &resp); err != nil {
+// ListGroups lists the agent groups visible to the agent.
+func (c *Client) ListGroups(input ListGroupsInput) (*ListGroupsResponse, error) {
+	return c.ListGroupsContext(context.Background(), input)
+}
+
+// ListGroupsContext is like ListGroups but takes a context.
+func (c *Client) ListGroupsContext(ctx context.Context, input ListGroupsInput) (*ListGroupsResponse, error) {
+	var resp ListGroupsResponse
+	if err := c.callTool(ctx, "mnemo.list_groups", input, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
-// Checkpoint creates a snapshot of the current agent state.
-func (c *Client) Checkpoint(input CheckpointInput) (*CheckpointResponse, error) {
-	var resp CheckpointResponse
-	if err := c.callTool("mnemo.checkpoint", input, &resp); err != nil {
+// CompareMemories reports how the memory idB differs from the memory idA,
+// so agents can see what changed between two versions of a belief.
+func (c *Client) CompareMemories(ctx context.Context, idA, idB string) (*MemoryDiff, error) {
+	if idA == "" {
+		return nil, &ValidationError{Field: "memory_id_a", Message: "is required"}
+	}
+	if idB == "" {
+		return nil, &ValidationError{Field: "memory_id_b", Message: "is required"}
+	}
+	var resp MemoryDiff
+	if err := c.callTool(ctx, "mnemo.compare_memories", compareMemoriesInput{MemoryIDA: idA, MemoryIDB: idB}, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
-// Branch forks the current state into a new named branch.
-func (c *Client) Branch(input BranchInput) (*BranchResponse, error) {
-	var resp BranchResponse
-	if err := c.callTool("mnemo.branch", input, &resp); err != nil {
+// ScoreMemorySimilarity returns the cosine similarity, in [0, 1], of two
+// stored memories without running a recall query, for example to detect
+// duplicates.
+func (c *Client) ScoreMemorySimilarity(idA, idB string) (float32, error) {
+	return c.ScoreMemorySimilarityContext(context.Background(), idA, idB)
+}
+
+// ScoreMemorySimilarityContext is like ScoreMemorySimilarity but takes a
+// context.
+func (c *Client) ScoreMemorySimilarityContext(ctx context.Context, idA, idB string) (float32, error) {
+	input := ScoreSimilarityInput{MemoryIDA: idA, MemoryIDB: idB}
+	if err := input.Validate(); err != nil {
+		return 0, err
+	}
+	var resp ScoreSimilarityResponse
+	if err := c.callTool(ctx, "mnemo.score_similarity", input, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Score, nil
+}
+
+// GetEmbedding embeds content with the same model the server uses for
+// stored memories, for hybrid retrieval systems built outside mnemo.
+func (c *Client) GetEmbedding(content string) ([]float32, error) {
+	resp, err := c.GetEmbeddingContext(context.Background(), GetEmbeddingInput{Content: content})
+	if err != nil {
 		return nil, err
 	}
-	return &resp, nil
+	return resp.Embedding, nil
 }
 
-// Merge combines a source branch into a target branch.
-func (c *Client) Merge(input MergeInput) (*MergeResponse, error) {
-	var resp MergeResponse
-	if err := c.callTool("mnemo.merge", input, &resp); err != nil {
+// GetEmbeddingContext is like GetEmbedding but takes a context and returns
+// the full response, including the model name. It returns an error if the
+// embedding's length does not match the reported dimensions.
+func (c *Client) GetEmbeddingContext(ctx context.Context, input GetEmbeddingInput) (*GetEmbeddingResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	var resp GetEmbeddingResponse
+	if err := c.callTool(ctx, "mnemo.get_embedding", input, &resp); err != nil {
 		return nil, err
 	}
+	if len(resp.Embedding) != resp.Dimensions {
+		return nil, fmt.Errorf("mnemo: embedding has %d values but the server reported %d dimensions",
+			len(resp.Embedding), resp.Dimensions)
+	}
 	return &resp, nil
 }
 
-// Replay reconstructs the agent context at a specific checkpoint.
-func (c *Client) Replay(input ReplayInput) (*ReplayResponse, error) {
-	var resp ReplayResponse
-	if err := c.callTool("mnemo.replay", input, &resp); err != nil {
+// SetBranchProtection marks a branch read-only on the server, or lifts the
+// protection. ClientOptions.ProtectedBranches is the client-side equivalent.
+func (c *Client) SetBranchProtection(ctx context.Context, threadID, branchName string, protected bool) (*BranchProtectionResponse, error) {
+	if branchName == "" {
+		return nil, &ValidationError{Field: "branch_name", Message: "is required"}
+	}
+	input := setBranchProtectionInput{ThreadID: threadID, BranchName: branchName, Protected: protected}
+	var resp BranchProtectionResponse
+	if err := c.callTool(ctx, "mnemo.set_branch_protection", input, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
-// Verify checks the hash chain integrity of stored memories.
-func (c *Client) Verify(input VerifyInput) (*VerifyResponse, error) {
-	var resp VerifyResponse
-	if err := c.callTool("mnemo.verify", input, &resp); err != nil {
+// Reset permanently deletes every memory, checkpoint, and delegation of the
+// configured agent, leaving the client connected, for example to start each
+// test from a clean slate. confirm must be ResetConfirmation.
+func (c *Client) Reset(ctx context.Context, confirm string) (*ResetResponse, error) {
+	if confirm != ResetConfirmation {
+		return nil, &ValidationError{Field: "confirm", Message: fmt.Sprintf("must be %q", ResetConfirmation)}
+	}
+	var resp ResetResponse
+	if err := c.callTool(ctx, "mnemo.reset_agent", resetAgentInput{Confirm: confirm}, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
-// Delegate grants scoped, time-bounded permissions to another agent.
-func (c *Client) Delegate(input DelegateInput) (*DelegateResponse, error) {
-	var resp DelegateResponse
-	if err := c.callTool("mnemo.delegate", input, &resp); err != nil {
+// CreatePreferenceProfile creates or replaces a preference profile that
+// RecallInput.PersonalizedBy can use to personalize ranking.
+func (c *Client) CreatePreferenceProfile(input PreferenceInput) (*PreferenceResponse, error) {
+	return c.CreatePreferenceProfileContext(context.Background(), input)
+}
+
+// CreatePreferenceProfileContext is like CreatePreferenceProfile but takes a
+// context.
+func (c *Client) CreatePreferenceProfileContext(ctx context.Context, input PreferenceInput) (*PreferenceResponse, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	var resp PreferenceResponse
+	if err := c.callTool(ctx, "mnemo.create_preference_profile", input, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -205,8 +1372,20 @@ func (c *Client) Delegate(input DelegateInput) (*DelegateResponse, error) {
 // Internal helpers
 // ---------------------------------------------------------------------------
 
-// buildArgs constructs the CLI arguments from the options.
-func buildArgs(opts ClientOptions) []string {
+// checkProtectedBranch returns a *ProtectedBranchError if branch is listed in
+// ClientOptions.ProtectedBranches.
+func (c *Client) checkProtectedBranch(branch string) error {
+	for _, p := range c.opts.ProtectedBranches {
+		if p == branch {
+			return &ProtectedBranchError{Branch: branch}
+		}
+	}
+	return nil
+}
+
+// buildArgs constructs the CLI arguments from the options. It returns an
+// error if ExtraArgs repeats a flag that is already set by another option.
+func buildArgs(opts ClientOptions) ([]string, error) {
 	var args []string
 	if opts.DbPath != "" {
 		args = append(args, "--db-path", opts.DbPath)
@@ -223,7 +1402,28 @@ func buildArgs(opts ClientOptions) []string {
 	if opts.Dimensions > 0 {
 		args = append(args, "--dimensions", fmt.Sprintf("%d", opts.Dimensions))
 	}
-	return args
+	if opts.PostgresURL != "" {
+		args = append(args, "--postgres-url", opts.PostgresURL)
+	}
+	if opts.DisableTelemetry {
+		args = append(args, "--no-telemetry")
+	}
+
+	known := make(map[string]struct{}, len(args))
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--") {
+			known[arg] = struct{}{}
+		}
+	}
+	for _, arg := range opts.ExtraArgs {
+		name, _, _ := strings.Cut(arg, "=")
+		if _, dup := known[name]; dup {
+			return nil, fmt.Errorf("mnemo: extra arg %q duplicates a flag set by ClientOptions", arg)
+		}
+	}
+	args = append(args, opts.ExtraArgs...)
+
+	return args, nil
 }
 
 // initialize performs the MCP initialization handshake with the server.
@@ -231,11 +1431,16 @@ func buildArgs(opts ClientOptions) []string {
 // It sends the "initialize" request and the "notifications/initialized"
 // notification as required by the MCP specification.
 func (c *Client) initialize() error {
+	requested := c.opts.ProtocolVersion
+	if requested == "" {
+		requested = DefaultProtocolVersion
+	}
+
 	initReq := jsonRPCRequest{
 		JSONRPC: "2.0",
 		Method:  "initialize",
 		Params: map[string]interface{}{
-			"protocolVersion": "2024-11-05",
+			"protocolVersion": requested,
 			"capabilities":    map[string]interface{}{},
 			"clientInfo": map[string]interface{}{
 				"name":    "mnemo-go-sdk",
@@ -245,14 +1450,35 @@ func (c *Client) initialize() error {
 		ID: intPtr(c.allocID()),
 	}
 
-	if err := c.sendRequest(initReq); err != nil {
+	if err := c.sendRequest(context.Background(), initReq); err != nil {
 		return fmt.Errorf("send initialize: %w", err)
 	}
 
-	// Read the initialize response.
-	if _, err := c.readRawResponse(); err != nil {
+	// Read the initialize response and record the negotiated version.
+	raw, err := c.readRawResponse()
+	if err != nil {
 		return fmt.Errorf("read initialize response: %w", err)
 	}
+	var initResp struct {
+		Result struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		} `json:"result"`
+		Error *jsonRPCError `json:"error"`
+	}
+	if err := c.unmarshal(raw, &initResp); err != nil {
+		return fmt.Errorf("unmarshal initialize response: %w", err)
+	}
+	if initResp.Error != nil {
+		return &RPCError{Code: initResp.Error.Code, Message: initResp.Error.Message}
+	}
+
+	c.protocolVersion = requested
+	if v := initResp.Result.ProtocolVersion; v != "" {
+		c.protocolVersion = v
+		if v != requested {
+			c.warn(&VersionMismatchWarning{Requested: requested, Server: v})
+		}
+	}
 
 	// Send the initialized notification (no id, no response expected).
 	notif := jsonRPCRequest{
@@ -260,7 +1486,7 @@ func (c *Client) initialize() error {
 		Method:  "notifications/initialized",
 	}
 
-	if err := c.sendRequest(notif); err != nil {
+	if err := c.sendRequest(context.Background(), notif); err != nil {
 		return fmt.Errorf("send initialized notification: %w", err)
 	}
 
@@ -275,87 +1501,303 @@ func (c *Client) allocID() int {
 	return id
 }
 
-// callTool sends a tools/call JSON-RPC request and unmarshals the text content
-// of the first content item into dest.
-func (c *Client) callTool(name string, arguments interface{}, dest interface{}) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// callTool fills unset identifiers from ctx, then runs a tool call through
+// the installed interceptors and invokeTool.
+func (c *Client) callTool(ctx context.Context, name string, arguments interface{}, dest interface{}) error {
+	c.lifecycleMu.Lock()
+	if c.closed {
+		c.lifecycleMu.Unlock()
+		return fmt.Errorf("mnemo %s: %w", name, ErrClientClosed)
+	}
+	c.inflight.Add(1)
+	c.lifecycleMu.Unlock()
+	defer c.inflight.Done()
+
+	if CorrelationIDFromContext(ctx) == "" {
+		id, err := newCorrelationID()
+		if err != nil {
+			return err
+		}
+		ctx = WithCorrelationID(ctx, id)
+	}
+	if c.opts.ThreadID != "" && ctx.Value(threadIDKey{}) == nil {
+		ctx = WithThreadID(ctx, c.opts.ThreadID)
+	}
+	arguments = applyContextFields(ctx, name, arguments)
 
-	id := c.allocID()
+	c.interceptorMu.RLock()
+	chain := c.interceptors
+	c.interceptorMu.RUnlock()
 
-	req := jsonRPCRequest{
-		JSONRPC: "2.0",
-		Method:  "tools/call",
-		Params: toolCallParams{
-			Name:      name,
-			Arguments: arguments,
-		},
-		ID: intPtr(id),
+	h := Handler(func(ctx context.Context, req, resp interface{}) error {
+		return c.invokeTool(ctx, name, req, resp)
+	})
+	for i := len(chain) - 1; i >= 0; i-- {
+		ic, next := chain[i], h
+		h = func(ctx context.Context, req, resp interface{}) error {
+			return ic(ctx, name, req, resp, next)
+		}
 	}
+	return h(ctx, arguments, dest)
+}
 
-	if err := c.sendRequest(req); err != nil {
-		return fmt.Errorf("mnemo %s: send: %w", name, err)
+// invokeTool sends a tools/call JSON-RPC request and unmarshals the text
+// content of the first content item into dest. If dest is a *toolContents it
+// receives every content item instead. It fails fast if ctx is already done.
+func (c *Client) invokeTool(ctx context.Context, name string, arguments interface{}, dest interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("mnemo %s: %w", name, err)
 	}
 
-	raw, err := c.readRawResponse()
-	if err != nil {
-		return fmt.Errorf("mnemo %s: read: %w", name, err)
+	params := toolCallParams{
+		Name:      name,
+		Arguments: arguments,
+	}
+	if alias, ok := c.opts.ToolAliases[name]; ok {
+		params.Name = alias
+	}
+	correlationID := CorrelationIDFromContext(ctx)
+	if correlationID != "" {
+		params.Meta = &toolCallMeta{CorrelationID: correlationID}
+	}
+	if c.opts.CompressMessages {
+		if err := c.compressArguments(&params); err != nil {
+			return fmt.Errorf("mnemo %s: compress: %w", name, err)
+		}
 	}
 
 	var rpcResp jsonRPCResponse
-	if err := json.Unmarshal(raw, &rpcResp); err != nil {
-		return fmt.Errorf("mnemo %s: unmarshal response: %w", name, err)
+	var err error
+	if c.mux != nil {
+		rpcResp, err = c.roundTripMultiplexed(ctx, params)
+	} else {
+		rpcResp, err = c.roundTrip(ctx, params)
+	}
+	if err != nil {
+		return fmt.Errorf("mnemo %s: %w", name, err)
 	}
 
 	if rpcResp.Error != nil {
-		return fmt.Errorf("mnemo %s: rpc error %d: %s", name, rpcResp.Error.Code, rpcResp.Error.Message)
+		return fmt.Errorf("mnemo %s: %w", name, &RPCError{Code: rpcResp.Error.Code, Message: rpcResp.Error.Message})
 	}
 
 	if rpcResp.Result == nil {
 		return fmt.Errorf("mnemo %s: empty result", name)
 	}
 
+	if meta := rpcResp.Result.Meta; meta != nil && meta.CorrelationID != "" && meta.CorrelationID != correlationID {
+		c.warn(fmt.Errorf("mnemo %s: response correlation ID %q does not match request %q",
+			name, meta.CorrelationID, correlationID))
+	}
+
 	if len(rpcResp.Result.Content) == 0 {
 		return fmt.Errorf("mnemo %s: no content in result", name)
 	}
 
+	if rpcResp.Result.IsError {
+		return fmt.Errorf("mnemo %s: %w", name, &ToolError{Message: rpcResp.Result.Content[0].Text})
+	}
+
+	if all, ok := dest.(*toolContents); ok {
+		*all = rpcResp.Result.Content
+		return nil
+	}
+
 	text := rpcResp.Result.Content[0].Text
-	if err := json.Unmarshal([]byte(text), dest); err != nil {
+	if err := c.unmarshal([]byte(text), dest); err != nil {
 		return fmt.Errorf("mnemo %s: unmarshal content: %w", name, err)
 	}
 
 	return nil
 }
 
+// roundTrip sends a tools/call request and reads its response. Calls are
+// serialized by exchangeMu, but a caller stops waiting, for its turn or its
+// response, as soon as ctx is done. An abandoned exchange still runs to
+// completion in the background, so the next call never reads its response.
+func (c *Client) roundTrip(ctx context.Context, params toolCallParams) (jsonRPCResponse, error) {
+	if err := c.exchangeMu.lock(ctx); err != nil {
+		return jsonRPCResponse{}, err
+	}
+	if c.broken != nil {
+		c.exchangeMu.unlock()
+		return jsonRPCResponse{}, fmt.Errorf("connection unusable: %w", c.broken)
+	}
+
+	c.mu.Lock()
+	req := jsonRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "tools/call",
+		Params:  params,
+		ID:      intPtr(c.allocID()),
+	}
+	c.mu.Unlock()
+
+	done := make(chan rpcResult, 1)
+	go func() {
+		defer c.exchangeMu.unlock()
+		resp, err := c.exchange(ctx, req)
+		done <- rpcResult{resp: resp, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.resp, res.err
+	case <-ctx.Done():
+		return jsonRPCResponse{}, ctx.Err()
+	}
+}
+
+// exchange writes req and reads its response. It must be called with
+// exchangeMu held. A failed write or read may leave a partial request or an
+// unread response on a stdio or dialed connection, so it marks the
+// connection broken.
+func (c *Client) exchange(ctx context.Context, req jsonRPCRequest) (jsonRPCResponse, error) {
+	if err := c.sendRequest(ctx, req); err != nil {
+		if c.http == nil {
+			c.broken = err
+		}
+		return jsonRPCResponse{}, fmt.Errorf("send: %w", err)
+	}
+
+	raw, err := c.readRawResponse()
+	if err != nil {
+		if c.http == nil {
+			c.broken = err
+		}
+		return jsonRPCResponse{}, fmt.Errorf("read: %w", err)
+	}
+
+	var rpcResp jsonRPCResponse
+	if err := c.unmarshal(raw, &rpcResp); err != nil {
+		return jsonRPCResponse{}, fmt.Errorf("unmarshal response: %w", err)
+	}
+	return rpcResp, nil
+}
+
+// ctxMutex is a mutual exclusion lock whose lock can be abandoned when a
+// context is done. The zero value is unlocked.
+type ctxMutex struct {
+	once sync.Once
+	ch   chan struct{}
+}
+
+// lock acquires m, or returns ctx.Err() if ctx is done first.
+func (m *ctxMutex) lock(ctx context.Context) error {
+	m.once.Do(func() { m.ch = make(chan struct{}, 1) })
+	select {
+	case m.ch <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// unlock releases m.
+func (m *ctxMutex) unlock() {
+	<-m.ch
+}
+
 // sendRequest marshals and writes a JSON-RPC request followed by a newline to
-// the child process stdin.
-func (c *Client) sendRequest(req jsonRPCRequest) error {
-	data, err := json.Marshal(req)
+// the child process stdin, or POSTs it when using HTTP. ctx bounds only the
+// HTTP POST.
+func (c *Client) sendRequest(ctx context.Context, req jsonRPCRequest) error {
+	data, err := c.marshal(req)
 	if err != nil {
 		return fmt.Errorf("marshal request: %w", err)
 	}
 
+	c.logDebug("→", data)
+	if c.http != nil {
+		if err := c.http.send(ctx, data, req.ID != nil); err != nil {
+			return fmt.Errorf("http post: %w", err)
+		}
+		return nil
+	}
 	data = append(data, '\n')
 
-	if _, err := c.stdin.Write(data); err != nil {
+	if err := c.writeStdin(data); err != nil {
 		return fmt.Errorf("write to stdin: %w", err)
 	}
 
 	return nil
 }
 
+// writeStdin writes data to the child process stdin, enforcing
+// StdinWriteTimeout when one is configured. On timeout the child process is
+// killed so the blocked write is released.
+func (c *Client) writeStdin(data []byte) error {
+	timeout := c.opts.StdinWriteTimeout
+	if timeout <= 0 {
+		_, err := c.stdin.Write(data)
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.stdin.Write(data)
+		done <- err
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		if c.cmd != nil && c.cmd.Process != nil {
+			_ = c.cmd.Process.Kill()
+		} else {
+			_ = c.stdin.Close()
+		}
+		return ErrStdinWriteTimeout
+	}
+}
+
 // readRawResponse reads the next newline-delimited JSON-RPC response from the
-// child process stdout.
+// child process stdout, or the pending reply when using HTTP.
 func (c *Client) readRawResponse() ([]byte, error) {
+	if c.http != nil {
+		raw, err := c.http.receive()
+		if err != nil {
+			return nil, err
+		}
+		c.logDebug("←", raw)
+		return raw, nil
+	}
 	if !c.stdout.Scan() {
 		if err := c.stdout.Err(); err != nil {
 			return nil, fmt.Errorf("scan stdout: %w", err)
 		}
 		return nil, fmt.Errorf("unexpected EOF from mnemo process")
 	}
+	c.logDebug("←", c.stdout.Bytes())
 	return c.stdout.Bytes(), nil
 }
 
+// warn reports a non-fatal problem to ClientOptions.OnWarning, or logs it
+// if no handler is set.
+func (c *Client) warn(err error) {
+	if c.opts.OnWarning != nil {
+		c.opts.OnWarning(err)
+		return
+	}
+	slog.Warn(err.Error())
+}
+
+// newResponseScanner returns a line scanner over the server's stdout that
+// accepts responses of up to maxBytes, or defaultMaxResponseBytes if
+// maxBytes is not positive.
+func newResponseScanner(r io.Reader, maxBytes int) *bufio.Scanner {
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxResponseBytes
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, min(maxBytes, defaultMaxResponseBytes)), maxBytes)
+	return scanner
+}
+
 // intPtr returns a pointer to the given int value.
 func intPtr(v int) *int {
 	return &v
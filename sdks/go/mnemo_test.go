@@ -1,8 +1,21 @@
 package mnemo
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 // ---------------------------------------------------------------------------
@@ -38,11 +51,12 @@ func TestBuildArgs(t *testing.T) {
 		{
 			name: "all options",
 			opts: ClientOptions{
-				DbPath:     "/data/agent.db",
-				AgentID:    "agent-1",
-				OrgID:      "org-42",
-				OpenAIKey:  "sk-test-key",
-				Dimensions: 768,
+				DbPath:      "/data/agent.db",
+				AgentID:     "agent-1",
+				OrgID:       "org-42",
+				OpenAIKey:   "sk-test-key",
+				Dimensions:  768,
+				PostgresURL: "postgres://localhost/mnemo",
 			},
 			want: []string{
 				"--db-path", "/data/agent.db",
@@ -50,6 +64,7 @@ func TestBuildArgs(t *testing.T) {
 				"--org-id", "org-42",
 				"--openai-api-key", "sk-test-key",
 				"--dimensions", "768",
+				"--postgres-url", "postgres://localhost/mnemo",
 			},
 		},
 		{
@@ -63,11 +78,37 @@ func TestBuildArgs(t *testing.T) {
 				"--agent-id", "bot",
 			},
 		},
+		{
+			name: "disable telemetry",
+			opts: ClientOptions{
+				DbPath:           "memory.db",
+				DisableTelemetry: true,
+			},
+			want: []string{
+				"--db-path", "memory.db",
+				"--no-telemetry",
+			},
+		},
+		{
+			name: "extra args",
+			opts: ClientOptions{
+				DbPath:    "memory.db",
+				ExtraArgs: []string{"--idle-timeout", "30", "--verbose"},
+			},
+			want: []string{
+				"--db-path", "memory.db",
+				"--idle-timeout", "30",
+				"--verbose",
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := buildArgs(tt.opts)
+			got, err := buildArgs(tt.opts)
+			if err != nil {
+				t.Fatalf("buildArgs() error: %v", err)
+			}
 			if len(got) != len(tt.want) {
 				t.Fatalf("buildArgs() returned %d args, want %d\ngot:  %v\nwant: %v", len(got), len(tt.want), got, tt.want)
 			}
@@ -80,6 +121,26 @@ func TestBuildArgs(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// TestBuildArgsDuplicateExtraArg — verifies ExtraArgs cannot repeat a flag.
+// ---------------------------------------------------------------------------
+
+func TestBuildArgsDuplicateExtraArg(t *testing.T) {
+	for _, extra := range [][]string{
+		{"--agent-id", "other"},
+		{"--agent-id=other"},
+	} {
+		_, err := buildArgs(ClientOptions{AgentID: "bot", ExtraArgs: extra})
+		if err == nil {
+			t.Errorf("buildArgs() with ExtraArgs %v: expected duplication error, got nil", extra)
+		}
+	}
+
+	if _, err := buildArgs(ClientOptions{ExtraArgs: []string{"--agent-id", "bot"}}); err != nil {
+		t.Errorf("buildArgs() without conflicting option: unexpected error %v", err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // TestRememberInputJSON — verifies JSON marshaling of RememberInput.
 // ---------------------------------------------------------------------------
@@ -167,9 +228,11 @@ func TestRecallInputJSON(t *testing.T) {
 	strategy := "hybrid"
 	minImp := float32(0.3)
 	after := "2024-01-01T00:00:00Z"
+	thread := "thread-7"
 
 	input := RecallInput{
 		Query:         "user preferences",
+		ThreadID:      &thread,
 		Limit:         &limit,
 		Strategy:      &strategy,
 		MinImportance: &minImp,
@@ -201,6 +264,16 @@ func TestRecallInputJSON(t *testing.T) {
 	if decoded.TemporalRange == nil || decoded.TemporalRange.After == nil {
 		t.Error("TemporalRange.After should be set")
 	}
+	if decoded.ThreadID == nil || *decoded.ThreadID != thread {
+		t.Errorf("ThreadID = %v, want %q", decoded.ThreadID, thread)
+	}
+
+	var raw map[string]interface{}
+	data, _ = json.Marshal(RecallInput{Query: "q"})
+	_ = json.Unmarshal(data, &raw)
+	if _, ok := raw["thread_id"]; ok {
+		t.Error("thread_id should be omitted when nil")
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -220,7 +293,12 @@ func TestRecallResponseJSON(t *testing.T) {
 				"tags": ["preferences"],
 				"score": 0.95,
 				"created_at": "2024-01-15T10:30:00Z",
-				"updated_at": "2024-01-15T10:30:00Z"
+				"updated_at": "2024-01-15T10:30:00Z",
+				"metadata": {"source": "settings", "confidence": 0.9},
+				"ttl_seconds": 86400,
+				"created_by": "agent-0",
+				"source_type": "user",
+				"source_id": "msg-42"
 			}
 		],
 		"total": 1
@@ -248,6 +326,60 @@ func TestRecallResponseJSON(t *testing.T) {
 	if m.Importance != 0.8 {
 		t.Errorf("Importance = %f, want 0.8", m.Importance)
 	}
+	if m.Metadata["source"] != "settings" {
+		t.Errorf("Metadata[source] = %v, want %q", m.Metadata["source"], "settings")
+	}
+	if m.Metadata["confidence"] != 0.9 {
+		t.Errorf("Metadata[confidence] = %v, want 0.9", m.Metadata["confidence"])
+	}
+	if m.TTLSeconds == nil || *m.TTLSeconds != 86400 {
+		t.Errorf("TTLSeconds = %v, want 86400", m.TTLSeconds)
+	}
+	if m.CreatedBy == nil || *m.CreatedBy != "agent-0" {
+		t.Errorf("CreatedBy = %v, want %q", m.CreatedBy, "agent-0")
+	}
+	if m.SourceType == nil || *m.SourceType != "user" {
+		t.Errorf("SourceType = %v, want %q", m.SourceType, "user")
+	}
+	if m.SourceID == nil || *m.SourceID != "msg-42" {
+		t.Errorf("SourceID = %v, want %q", m.SourceID, "msg-42")
+	}
+	if resp.SearchMetadata != nil {
+		t.Errorf("SearchMetadata = %+v, want nil when absent", resp.SearchMetadata)
+	}
+
+	withMeta := `{
+		"memories": [],
+		"total": 0,
+		"search_metadata": {
+			"strategy_used": "hybrid",
+			"candidate_count": 120,
+			"filtered_count": 115,
+			"embedding_latency_ms": 12,
+			"search_latency_ms": 34
+		}
+	}`
+	var metaResp RecallResponse
+	if err := json.Unmarshal([]byte(withMeta), &metaResp); err != nil {
+		t.Fatalf("Unmarshal RecallResponse with search_metadata: %v", err)
+	}
+	sm := metaResp.SearchMetadata
+	if sm == nil {
+		t.Fatal("SearchMetadata should be set")
+	}
+	if sm.StrategyUsed != "hybrid" || sm.CandidateCount != 120 || sm.FilteredCount != 115 {
+		t.Errorf("SearchMetadata = %+v, want hybrid/120/115", sm)
+	}
+	if sm.EmbeddingLatencyMs != 12 || sm.SearchLatencyMs != 34 {
+		t.Errorf("latencies = %d/%d, want 12/34", sm.EmbeddingLatencyMs, sm.SearchLatencyMs)
+	}
+
+	data, _ := json.Marshal(RecallResponse{Total: 0})
+	var out map[string]interface{}
+	_ = json.Unmarshal(data, &out)
+	if _, ok := out["search_metadata"]; ok {
+		t.Error("search_metadata should be omitted when nil")
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -258,10 +390,12 @@ func TestForgetInputJSON(t *testing.T) {
 	strategy := "decay"
 	maxAge := 48.0
 	minImp := float32(0.2)
+	thread := "thread-7"
 
 	input := ForgetInput{
 		MemoryIDs: []string{},
 		Strategy:  &strategy,
+		ThreadID:  &thread,
 		Criteria: &ForgetCriteria{
 			MaxAgeHours:        &maxAge,
 			MinImportanceBelow: &minImp,
@@ -288,6 +422,9 @@ func TestForgetInputJSON(t *testing.T) {
 	if decoded.Criteria.MaxAgeHours == nil || *decoded.Criteria.MaxAgeHours != maxAge {
 		t.Errorf("MaxAgeHours = %v, want %f", decoded.Criteria.MaxAgeHours, maxAge)
 	}
+	if decoded.ThreadID == nil || *decoded.ThreadID != thread {
+		t.Errorf("ThreadID = %v, want %q", decoded.ThreadID, thread)
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -317,6 +454,38 @@ func TestForgetResponseJSON(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// TestForgetResponseArchiveJSON — verifies archived IDs are kept separate.
+// ---------------------------------------------------------------------------
+
+func TestForgetResponseArchiveJSON(t *testing.T) {
+	raw := `{
+		"forgotten": [],
+		"archived_ids": ["id-1", "id-2"],
+		"errors": [],
+		"status": "archived"
+	}`
+
+	var resp ForgetResponse
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		t.Fatalf("Unmarshal ForgetResponse: %v", err)
+	}
+
+	if len(resp.ArchivedIDs) != 2 || resp.ArchivedIDs[0] != "id-1" {
+		t.Errorf("ArchivedIDs = %v, want [id-1 id-2]", resp.ArchivedIDs)
+	}
+	if len(resp.Forgotten) != 0 {
+		t.Errorf("Forgotten = %v, want empty for archive strategy", resp.Forgotten)
+	}
+
+	data, _ := json.Marshal(ForgetResponse{Forgotten: []string{"id-3"}, Status: "forgotten"})
+	var out map[string]interface{}
+	_ = json.Unmarshal(data, &out)
+	if _, ok := out["archived_ids"]; ok {
+		t.Error("archived_ids should be omitted when empty")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // TestShareInputJSON — verifies ShareInput marshaling.
 // ---------------------------------------------------------------------------
@@ -364,6 +533,8 @@ func TestCheckpointInputJSON(t *testing.T) {
 		BranchName:    &branch,
 		StateSnapshot: map[string]interface{}{"step": 5, "score": 0.87},
 		Label:         &label,
+		Tags:          []string{"refactor", "stable"},
+		Metadata:      map[string]interface{}{"model": "small", "tokens": 1200},
 	}
 
 	data, err := json.Marshal(input)
@@ -371,6 +542,39 @@ func TestCheckpointInputJSON(t *testing.T) {
 		t.Fatalf("Marshal CheckpointInput: %v", err)
 	}
 
+	var decoded CheckpointInput
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal CheckpointInput: %v", err)
+	}
+	if len(decoded.Tags) != 2 || decoded.Tags[0] != "refactor" || decoded.Tags[1] != "stable" {
+		t.Errorf("Tags = %v, want [refactor stable]", decoded.Tags)
+	}
+	if decoded.Metadata["model"] != "small" || decoded.Metadata["tokens"] != float64(1200) {
+		t.Errorf("Metadata = %v, want model=small tokens=1200", decoded.Metadata)
+	}
+
+	prefix := "nightly"
+	auto := CheckpointInput{ThreadID: "thread-1", AutoLabel: true, AutoLabelPrefix: &prefix}
+	autoData, _ := json.Marshal(auto)
+	var autoRaw map[string]interface{}
+	_ = json.Unmarshal(autoData, &autoRaw)
+	if autoRaw["auto_label"] != true || autoRaw["auto_label_prefix"] != "nightly" {
+		t.Errorf("auto label fields = %v/%v, want true/nightly", autoRaw["auto_label"], autoRaw["auto_label_prefix"])
+	}
+	if _, ok := autoRaw["label"]; ok {
+		t.Error("label should be omitted when nil")
+	}
+	if err := auto.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	var verr *ValidationError
+	if err := (CheckpointInput{AutoLabel: true, Label: &label}).Validate(); !errors.As(err, &verr) || verr.Field != "auto_label" {
+		t.Errorf("Validate() with Label and AutoLabel = %v, want auto_label ValidationError", err)
+	}
+	if err := (CheckpointInput{AutoLabelPrefix: &prefix}).Validate(); !errors.As(err, &verr) || verr.Field != "auto_label_prefix" {
+		t.Errorf("Validate() with prefix only = %v, want auto_label_prefix ValidationError", err)
+	}
+
 	var raw map[string]interface{}
 	if err := json.Unmarshal(data, &raw); err != nil {
 		t.Fatalf("Unmarshal to map: %v", err)
@@ -405,6 +609,7 @@ func TestBranchInputJSON(t *testing.T) {
 		NewBranchName:      "feature-x",
 		SourceCheckpointID: &srcCP,
 		SourceBranch:       &srcBranch,
+		AutoLabel:          true,
 	}
 
 	data, err := json.Marshal(input)
@@ -423,6 +628,9 @@ func TestBranchInputJSON(t *testing.T) {
 	if decoded.SourceCheckpointID == nil || *decoded.SourceCheckpointID != srcCP {
 		t.Errorf("SourceCheckpointID = %v, want %q", decoded.SourceCheckpointID, srcCP)
 	}
+	if !decoded.AutoLabel {
+		t.Error("AutoLabel should round-trip as true")
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -507,8 +715,8 @@ func TestReplayResponseJSON(t *testing.T) {
 		"memory_count": 2,
 		"event_count": 5,
 		"memories": [
-			{"id": "m1", "content": "first", "memory_type": "episodic", "created_at": "2024-06-01T11:00:00Z"},
-			{"id": "m2", "content": "second", "memory_type": "semantic", "created_at": "2024-06-01T11:30:00Z"}
+			{"id": "m1", "content": "first", "memory_type": "episodic", "created_at": "2024-06-01T11:00:00Z", "metadata": {"step": 1}},
+			{"id": "m2", "content": "second", "memory_type": "semantic", "created_at": "2024-06-01T11:30:00Z", "created_by": "agent-1", "source_type": "agent"}
 		],
 		"status": "replayed"
 	}`
@@ -530,6 +738,18 @@ func TestReplayResponseJSON(t *testing.T) {
 	if len(resp.Memories) != 2 {
 		t.Fatalf("Memories length = %d, want 2", len(resp.Memories))
 	}
+	if resp.Memories[0].Metadata["step"] != float64(1) {
+		t.Errorf("Memories[0].Metadata[step] = %v, want 1", resp.Memories[0].Metadata["step"])
+	}
+	if resp.Memories[1].Metadata != nil {
+		t.Errorf("Memories[1].Metadata = %v, want nil", resp.Memories[1].Metadata)
+	}
+	if resp.Memories[1].CreatedBy == nil || *resp.Memories[1].CreatedBy != "agent-1" {
+		t.Errorf("Memories[1].CreatedBy = %v, want %q", resp.Memories[1].CreatedBy, "agent-1")
+	}
+	if resp.Memories[0].SourceType != nil {
+		t.Errorf("Memories[0].SourceType = %v, want nil", resp.Memories[0].SourceType)
+	}
 	if resp.Status != "replayed" {
 		t.Errorf("Status = %q, want %q", resp.Status, "replayed")
 	}
@@ -741,6 +961,7 @@ func TestCheckpointResponseJSON(t *testing.T) {
 		"checkpoint_id": "cp-100",
 		"parent_id": "cp-99",
 		"branch_name": "main",
+		"tags": ["nightly"],
 		"status": "checkpointed"
 	}`
 
@@ -758,6 +979,9 @@ func TestCheckpointResponseJSON(t *testing.T) {
 	if resp.BranchName != "main" {
 		t.Errorf("BranchName = %q, want %q", resp.BranchName, "main")
 	}
+	if len(resp.Tags) != 1 || resp.Tags[0] != "nightly" {
+		t.Errorf("Tags = %v, want [nightly]", resp.Tags)
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -810,6 +1034,58 @@ func TestMergeResponseJSON(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// TestMergeSquashJSON — verifies squash message, label, and result ID.
+// ---------------------------------------------------------------------------
+
+func TestMergeSquashJSON(t *testing.T) {
+	strategy := "squash"
+	message := "Summary of feature-x exploration"
+	label := "feature-x squash"
+
+	input := MergeInput{
+		ThreadID:      "thread-1",
+		SourceBranch:  "feature-x",
+		Strategy:      &strategy,
+		SquashMessage: &message,
+		SquashLabel:   &label,
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal MergeInput: %v", err)
+	}
+
+	var decoded MergeInput
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal MergeInput: %v", err)
+	}
+	if decoded.SquashMessage == nil || *decoded.SquashMessage != message {
+		t.Errorf("SquashMessage = %v, want %q", decoded.SquashMessage, message)
+	}
+	if decoded.SquashLabel == nil || *decoded.SquashLabel != label {
+		t.Errorf("SquashLabel = %v, want %q", decoded.SquashLabel, label)
+	}
+
+	var squashed MergeResponse
+	raw := `{"checkpoint_id": "cp-301", "target_branch": "main", "merged_memory_count": 4, "squashed_memory_id": "mem-sq", "status": "merged"}`
+	if err := json.Unmarshal([]byte(raw), &squashed); err != nil {
+		t.Fatalf("Unmarshal MergeResponse: %v", err)
+	}
+	if squashed.SquashedMemoryID == nil || *squashed.SquashedMemoryID != "mem-sq" {
+		t.Errorf("SquashedMemoryID = %v, want %q", squashed.SquashedMemoryID, "mem-sq")
+	}
+
+	var full MergeResponse
+	raw = `{"checkpoint_id": "cp-302", "target_branch": "main", "merged_memory_count": 4, "status": "merged"}`
+	if err := json.Unmarshal([]byte(raw), &full); err != nil {
+		t.Fatalf("Unmarshal MergeResponse: %v", err)
+	}
+	if full.SquashedMemoryID != nil {
+		t.Errorf("SquashedMemoryID = %v, want nil for non-squash merge", *full.SquashedMemoryID)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // TestJSONRPCRequestMarshal — verifies the JSON-RPC request envelope.
 // ---------------------------------------------------------------------------
@@ -999,3 +1275,1985 @@ func TestVerifyBrokenChainJSON(t *testing.T) {
 		t.Errorf("Status = %q, want %q", resp.Status, "integrity_violation")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// TestStdinWriteTimeout — verifies a blocked stdin write times out.
+// ---------------------------------------------------------------------------
+
+func TestStdinWriteTimeout(t *testing.T) {
+	opts := ClientOptions{}
+	WithStdinPipeTimeout(50 * time.Millisecond)(&opts)
+	if opts.StdinWriteTimeout != 50*time.Millisecond {
+		t.Fatalf("StdinWriteTimeout = %v, want 50ms", opts.StdinWriteTimeout)
+	}
+
+	// Nobody reads from pr, so every write to pw blocks.
+	pr, pw := io.Pipe()
+	defer pr.Close()
+
+	c := &Client{stdin: pw, opts: opts}
+
+	start := time.Now()
+	err := c.sendRequest(context.Background(), jsonRPCRequest{JSONRPC: "2.0", Method: "ping"})
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, ErrStdinWriteTimeout) {
+		t.Fatalf("sendRequest() = %v, want ErrStdinWriteTimeout", err)
+	}
+	if elapsed > time.Second {
+		t.Errorf("sendRequest() took %v, want roughly the 50ms timeout", elapsed)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRoundTripContext — verifies calls stop waiting on a hung server when
+// their context is done, and that the abandoned response is not handed to
+// the next call.
+// ---------------------------------------------------------------------------
+
+func TestRoundTripContext(t *testing.T) {
+	release := make(chan struct{})
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in RecallInput
+		_ = json.Unmarshal(args, &in)
+		if in.Query == "hung" {
+			<-release
+		}
+		return RecallResponse{Memories: []RecalledMemory{{ID: in.Query}}, Total: 1}, nil
+	})
+
+	recall := func(query string, timeout time.Duration) (*RecallResponse, time.Duration, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		start := time.Now()
+		resp, err := c.RecallContext(ctx, RecallInput{Query: query})
+		return resp, time.Since(start), err
+	}
+
+	if _, elapsed, err := recall("hung", 50*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) || elapsed > time.Second {
+		t.Fatalf("RecallContext(hung) = %v after %v, want context.DeadlineExceeded at the deadline", err, elapsed)
+	}
+	if _, elapsed, err := recall("queued", 50*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) || elapsed > time.Second {
+		t.Fatalf("RecallContext(queued) = %v after %v, want context.DeadlineExceeded at the deadline", err, elapsed)
+	}
+
+	close(release)
+	resp, _, err := recall("next", 2*time.Second)
+	if err != nil {
+		t.Fatalf("RecallContext(next): %v", err)
+	}
+	if got := resp.Memories[0].ID; got != "next" {
+		t.Errorf("RecallContext(next) got the response for %q", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRoundTripBrokenConnection — verifies a failed read marks the
+// connection unusable for later calls.
+// ---------------------------------------------------------------------------
+
+func TestRoundTripBrokenConnection(t *testing.T) {
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+	defer clientW.Close()
+	go func() {
+		// Read the request, then hang up without answering.
+		_, _ = bufio.NewReader(serverR).ReadBytes('\n')
+		_ = serverW.Close()
+	}()
+	c := &Client{stdin: clientW, stdout: newResponseScanner(clientR, 0)}
+
+	if _, err := c.Recall(RecallInput{Query: "q"}); err == nil {
+		t.Fatal("Recall on a dropped connection: expected error, got nil")
+	}
+	_, err := c.Recall(RecallInput{Query: "q"})
+	if err == nil || !strings.Contains(err.Error(), "connection unusable") {
+		t.Errorf("Recall after a failed read = %v, want connection unusable", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestReadStderrLines — verifies stderr lines are delivered on the channel.
+// ---------------------------------------------------------------------------
+
+func TestReadStderrLines(t *testing.T) {
+	pr, pw := io.Pipe()
+	c := &Client{stderr: pr}
+
+	lines := c.ReadStderrLines()
+
+	go func() {
+		_, _ = io.WriteString(pw, `{"level":"warn","msg":"slow embedding"}`+"\n")
+		_ = pw.Close()
+	}()
+
+	select {
+	case line := <-lines:
+		if line != `{"level":"warn","msg":"slow embedding"}` {
+			t.Errorf("line = %q, want the JSON log line", line)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for stderr line")
+	}
+
+	if _, ok := <-lines; ok {
+		t.Error("channel should be closed after stderr EOF")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestReadStderrNotCaptured — verifies the reader is at EOF by default.
+// ---------------------------------------------------------------------------
+
+func TestReadStderrNotCaptured(t *testing.T) {
+	c := &Client{}
+
+	data, err := io.ReadAll(c.ReadStderr())
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if len(data) != 0 {
+		t.Errorf("ReadStderr() yielded %q, want nothing", data)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCloseUndrainedStderr — verifies Close returns when captured stderr was
+// never read.
+// ---------------------------------------------------------------------------
+
+func TestCloseUndrainedStderr(t *testing.T) {
+	t.Setenv(fakeServerEnv, "1")
+	t.Setenv(fakeStderrEnv, "1")
+
+	c, err := NewClient(ClientOptions{Command: os.Args[0], CaptureStderr: true})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- c.Close() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close hung with undrained stderr")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestPinMemoryJSON — verifies pin/unpin shapes, validation, and wiring.
+// ---------------------------------------------------------------------------
+
+func TestPinMemoryJSON(t *testing.T) {
+	reason := "system prompt fact"
+	data, err := json.Marshal(PinMemoryInput{MemoryID: "mem-1", PinReason: &reason})
+	if err != nil {
+		t.Fatalf("Marshal PinMemoryInput: %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+	if raw["memory_id"] != "mem-1" || raw["pin_reason"] != reason {
+		t.Errorf("PinMemoryInput JSON = %s", data)
+	}
+
+	var pinned PinMemoryResponse
+	if err := json.Unmarshal([]byte(`{"memory_id": "mem-1", "status": "pinned"}`), &pinned); err != nil {
+		t.Fatalf("Unmarshal PinMemoryResponse: %v", err)
+	}
+	if pinned.MemoryID != "mem-1" || pinned.Status != "pinned" {
+		t.Errorf("PinMemoryResponse = %+v", pinned)
+	}
+
+	var unpinned UnpinMemoryResponse
+	if err := json.Unmarshal([]byte(`{"memory_id": "mem-1", "status": "unpinned"}`), &unpinned); err != nil {
+		t.Fatalf("Unmarshal UnpinMemoryResponse: %v", err)
+	}
+	if unpinned.Status != "unpinned" {
+		t.Errorf("Status = %q, want %q", unpinned.Status, "unpinned")
+	}
+
+	var verr *ValidationError
+	if err := (PinMemoryInput{}).Validate(); !errors.As(err, &verr) || verr.Field != "memory_id" {
+		t.Errorf("PinMemoryInput{}.Validate() = %v, want memory_id ValidationError", err)
+	}
+	if err := (UnpinMemoryInput{}).Validate(); !errors.As(err, &verr) || verr.Field != "memory_id" {
+		t.Errorf("UnpinMemoryInput{}.Validate() = %v, want memory_id ValidationError", err)
+	}
+
+	var tools []string
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		tools = append(tools, name)
+		return map[string]string{"memory_id": "mem-1", "status": "ok"}, nil
+	})
+	if _, err := c.PinMemory(PinMemoryInput{MemoryID: "mem-1"}); err != nil {
+		t.Fatalf("PinMemory: %v", err)
+	}
+	if _, err := c.UnpinMemory(UnpinMemoryInput{MemoryID: "mem-1"}); err != nil {
+		t.Fatalf("UnpinMemory: %v", err)
+	}
+	if _, err := c.PinMemory(PinMemoryInput{}); err == nil {
+		t.Error("PinMemory with empty MemoryID: expected error, got nil")
+	}
+	if len(tools) != 2 || tools[0] != "mnemo.pin_memory" || tools[1] != "mnemo.unpin_memory" {
+		t.Errorf("tools called = %v, want [mnemo.pin_memory mnemo.unpin_memory]", tools)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestTagMemoriesJSON — verifies batch tagging shapes and validation.
+// ---------------------------------------------------------------------------
+
+func TestTagMemoriesJSON(t *testing.T) {
+	maxAge := 720.0
+	input := TagMemoriesInput{
+		AddTags:    []string{"archive-candidate"},
+		RemoveTags: []string{"hot"},
+		Criteria:   &ForgetCriteria{MaxAgeHours: &maxAge},
+	}
+	if err := input.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal TagMemoriesInput: %v", err)
+	}
+	var decoded TagMemoriesInput
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal TagMemoriesInput: %v", err)
+	}
+	if len(decoded.AddTags) != 1 || len(decoded.RemoveTags) != 1 {
+		t.Errorf("AddTags = %v, RemoveTags = %v", decoded.AddTags, decoded.RemoveTags)
+	}
+	if decoded.Criteria == nil || decoded.Criteria.MaxAgeHours == nil || *decoded.Criteria.MaxAgeHours != maxAge {
+		t.Errorf("Criteria = %+v, want MaxAgeHours %v", decoded.Criteria, maxAge)
+	}
+
+	raw := `{
+		"updated": 12,
+		"errors": [{"id": "mem-9", "error": "permission denied"}],
+		"status": "tagged"
+	}`
+	var resp TagMemoriesResponse
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		t.Fatalf("Unmarshal TagMemoriesResponse: %v", err)
+	}
+	if resp.Updated != 12 {
+		t.Errorf("Updated = %d, want 12", resp.Updated)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].ID != "mem-9" {
+		t.Errorf("Errors = %+v", resp.Errors)
+	}
+
+	if err := (TagMemoriesInput{MemoryIDs: []string{"mem-1"}}).Validate(); err == nil {
+		t.Error("Validate() without AddTags or RemoveTags: expected error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestListTagsJSON — verifies tag listing with and without results.
+// ---------------------------------------------------------------------------
+
+func TestListTagsJSON(t *testing.T) {
+	prefix := "proj-"
+	minCount := 2
+	data, err := json.Marshal(ListTagsInput{Prefix: &prefix, MinCount: &minCount})
+	if err != nil {
+		t.Fatalf("Marshal ListTagsInput: %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+	if raw["prefix"] != "proj-" {
+		t.Errorf("prefix = %v, want %q", raw["prefix"], "proj-")
+	}
+	if raw["min_count"] != float64(2) {
+		t.Errorf("min_count = %v, want 2", raw["min_count"])
+	}
+	if _, ok := raw["agent_id"]; ok {
+		t.Error("expected 'agent_id' to be omitted when nil")
+	}
+
+	var filtered ListTagsResponse
+	raw2 := `{"tags": [{"name": "proj-alpha", "count": 14}, {"name": "proj-beta", "count": 3}], "total": 2}`
+	if err := json.Unmarshal([]byte(raw2), &filtered); err != nil {
+		t.Fatalf("Unmarshal ListTagsResponse: %v", err)
+	}
+	if filtered.Total != 2 || len(filtered.Tags) != 2 {
+		t.Fatalf("ListTagsResponse = %+v", filtered)
+	}
+	if filtered.Tags[0].Name != "proj-alpha" || filtered.Tags[0].Count != 14 {
+		t.Errorf("Tags[0] = %+v, want proj-alpha/14", filtered.Tags[0])
+	}
+
+	var empty ListTagsResponse
+	if err := json.Unmarshal([]byte(`{"tags": [], "total": 0}`), &empty); err != nil {
+		t.Fatalf("Unmarshal ListTagsResponse: %v", err)
+	}
+	if empty.Tags == nil || len(empty.Tags) != 0 {
+		t.Errorf("Tags = %v, want empty non-nil slice", empty.Tags)
+	}
+	if empty.Total != 0 {
+		t.Errorf("Total = %d, want 0", empty.Total)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRenameTag — verifies the dry-run path and input validation.
+// ---------------------------------------------------------------------------
+
+func TestRenameTag(t *testing.T) {
+	var gotArgs RenameTagInput
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		if err := json.Unmarshal(args, &gotArgs); err != nil {
+			return nil, &jsonRPCError{Code: -32602, Message: err.Error()}
+		}
+		return RenameTagResponse{UpdatedCount: 1204, Status: "dry_run"}, nil
+	})
+
+	resp, err := c.RenameTag(RenameTagInput{OldTag: "golang", NewTag: "go-language", DryRun: true})
+	if err != nil {
+		t.Fatalf("RenameTag: %v", err)
+	}
+	if !gotArgs.DryRun {
+		t.Error("server did not receive dry_run=true")
+	}
+	if resp.UpdatedCount != 1204 {
+		t.Errorf("UpdatedCount = %d, want 1204", resp.UpdatedCount)
+	}
+	if resp.Status != "dry_run" {
+		t.Errorf("Status = %q, want %q", resp.Status, "dry_run")
+	}
+
+	tests := []struct {
+		name  string
+		input RenameTagInput
+		field string
+	}{
+		{"missing old", RenameTagInput{NewTag: "b"}, "old_tag"},
+		{"missing new", RenameTagInput{OldTag: "a"}, "new_tag"},
+		{"same tag", RenameTagInput{OldTag: "a", NewTag: "a"}, "new_tag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var verr *ValidationError
+			if err := tt.input.Validate(); !errors.As(err, &verr) || verr.Field != tt.field {
+				t.Errorf("Validate() = %v, want %s ValidationError", err, tt.field)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRememberDeduplicated — verifies identical content reports a duplicate.
+// ---------------------------------------------------------------------------
+
+func TestRememberDeduplicated(t *testing.T) {
+	byContent := map[string]string{}
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in RememberInput
+		_ = json.Unmarshal(args, &in)
+		if id, ok := byContent[in.Content]; ok && !in.AllowDuplicate {
+			return RememberResponse{ID: id, ContentHash: "h", Status: "deduplicated", Duplicate: true, ExistingID: &id}, nil
+		}
+		id := fmt.Sprintf("mem-%d", len(byContent)+1)
+		byContent[in.Content] = id
+		return RememberResponse{ID: id, ContentHash: "h", Status: "remembered"}, nil
+	})
+
+	first, err := c.Remember(RememberInput{Content: "User prefers dark mode"})
+	if err != nil {
+		t.Fatalf("first Remember: %v", err)
+	}
+	if first.Status != "remembered" || first.Duplicate {
+		t.Errorf("first = %+v, want a fresh memory", first)
+	}
+
+	second, err := c.Remember(RememberInput{Content: "User prefers dark mode"})
+	if err != nil {
+		t.Fatalf("second Remember: %v", err)
+	}
+	if second.Status != "deduplicated" {
+		t.Errorf("Status = %q, want %q", second.Status, "deduplicated")
+	}
+	if !second.Duplicate {
+		t.Error("Duplicate should be true")
+	}
+	if second.ExistingID == nil || *second.ExistingID != first.ID {
+		t.Errorf("ExistingID = %v, want %q", second.ExistingID, first.ID)
+	}
+
+	forced, err := c.Remember(RememberInput{Content: "User prefers dark mode", AllowDuplicate: true})
+	if err != nil {
+		t.Fatalf("forced Remember: %v", err)
+	}
+	if forced.Duplicate || forced.ExistingID != nil {
+		t.Errorf("forced = %+v, want a fresh memory with AllowDuplicate", forced)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestLookupByHash — verifies the found and not-found cases.
+// ---------------------------------------------------------------------------
+
+func TestLookupByHash(t *testing.T) {
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in LookupByHashInput
+		_ = json.Unmarshal(args, &in)
+		if in.ContentHash == "sha256:known" {
+			return json.RawMessage(`{"memory": {"id": "mem-1", "content": "User prefers dark mode"}, "found": true}`), nil
+		}
+		return json.RawMessage(`{"memory": null, "found": false}`), nil
+	})
+
+	hit, err := c.LookupByHash(LookupByHashInput{ContentHash: "sha256:known"})
+	if err != nil {
+		t.Fatalf("LookupByHash(known): %v", err)
+	}
+	if !hit.Found {
+		t.Error("Found should be true")
+	}
+	if hit.Memory == nil || hit.Memory.ID != "mem-1" {
+		t.Errorf("Memory = %+v, want mem-1", hit.Memory)
+	}
+
+	miss, err := c.LookupByHash(LookupByHashInput{ContentHash: "sha256:unknown"})
+	if err != nil {
+		t.Fatalf("LookupByHash(unknown): unexpected error %v", err)
+	}
+	if miss.Found {
+		t.Error("Found should be false")
+	}
+	if miss.Memory != nil {
+		t.Errorf("Memory = %+v, want nil", miss.Memory)
+	}
+
+	if _, err := c.LookupByHash(LookupByHashInput{}); err == nil {
+		t.Error("LookupByHash with empty hash: expected error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCopyMemory — verifies field round-trip and tag inheritance.
+// ---------------------------------------------------------------------------
+
+func TestCopyMemory(t *testing.T) {
+	target := "agent-2"
+	org := "org-7"
+	input := CopyMemoryInput{
+		SourceMemoryID:  "mem-1",
+		TargetAgentID:   &target,
+		TargetOrgID:     &org,
+		InheritMetadata: true,
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal CopyMemoryInput: %v", err)
+	}
+	var decoded CopyMemoryInput
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal CopyMemoryInput: %v", err)
+	}
+	if decoded.SourceMemoryID != "mem-1" || decoded.TargetAgentID == nil || *decoded.TargetAgentID != target {
+		t.Errorf("decoded = %+v", decoded)
+	}
+	if decoded.TargetOrgID == nil || *decoded.TargetOrgID != org {
+		t.Errorf("TargetOrgID = %v, want %q", decoded.TargetOrgID, org)
+	}
+	if decoded.InheritTags || !decoded.InheritMetadata {
+		t.Errorf("InheritTags = %v, InheritMetadata = %v, want false, true", decoded.InheritTags, decoded.InheritMetadata)
+	}
+
+	source := RecalledMemory{ID: "mem-1", Content: "shared fact", Tags: []string{"ops"}}
+	memories := map[string]RecalledMemory{source.ID: source}
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		switch name {
+		case "mnemo.copy_memory":
+			var in CopyMemoryInput
+			_ = json.Unmarshal(args, &in)
+			cp := memories[in.SourceMemoryID]
+			cp.ID = "mem-copy"
+			if !in.InheritTags {
+				cp.Tags = nil
+			}
+			memories[cp.ID] = cp
+			return CopyMemoryResponse{NewMemoryID: cp.ID, Status: "copied"}, nil
+		default:
+			return RecallResponse{Memories: []RecalledMemory{memories["mem-copy"]}, Total: 1}, nil
+		}
+	})
+
+	resp, err := c.CopyMemory(CopyMemoryInput{SourceMemoryID: "mem-1", TargetAgentID: &target})
+	if err != nil {
+		t.Fatalf("CopyMemory: %v", err)
+	}
+	if resp.NewMemoryID != "mem-copy" || resp.Status != "copied" {
+		t.Errorf("CopyMemoryResponse = %+v", resp)
+	}
+
+	recalled, err := c.Recall(RecallInput{Query: "shared fact"})
+	if err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if len(recalled.Memories[0].Tags) != 0 {
+		t.Errorf("copied Tags = %v, want none when InheritTags is false", recalled.Memories[0].Tags)
+	}
+
+	if _, err := c.CopyMemory(CopyMemoryInput{}); err == nil {
+		t.Error("CopyMemory with empty SourceMemoryID: expected error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestForgetDryRun — verifies dry-run and live runs report different statuses.
+// ---------------------------------------------------------------------------
+
+func TestForgetDryRun(t *testing.T) {
+	stored := map[string]bool{"mem-1": true, "mem-2": true}
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in ForgetInput
+		_ = json.Unmarshal(args, &in)
+		resp := ForgetResponse{Forgotten: in.MemoryIDs, Status: "forgotten"}
+		if in.DryRun {
+			resp.Status = "dry_run"
+			return resp, nil
+		}
+		for _, id := range in.MemoryIDs {
+			delete(stored, id)
+		}
+		return resp, nil
+	})
+
+	ids := []string{"mem-1", "mem-2"}
+	preview, err := c.Forget(ForgetInput{MemoryIDs: ids, DryRun: true})
+	if err != nil {
+		t.Fatalf("Forget dry run: %v", err)
+	}
+	if preview.Status != "dry_run" {
+		t.Errorf("dry-run Status = %q, want %q", preview.Status, "dry_run")
+	}
+	if len(preview.Forgotten) != 2 || len(stored) != 2 {
+		t.Errorf("dry run Forgotten = %v, stored = %d, want 2 listed and none deleted", preview.Forgotten, len(stored))
+	}
+
+	live, err := c.Forget(ForgetInput{MemoryIDs: ids})
+	if err != nil {
+		t.Fatalf("Forget: %v", err)
+	}
+	if live.Status == preview.Status {
+		t.Errorf("live Status = %q, want it to differ from dry-run status", live.Status)
+	}
+	if len(stored) != 0 {
+		t.Errorf("stored = %d after live forget, want 0", len(stored))
+	}
+
+	data, _ := json.Marshal(ForgetInput{MemoryIDs: ids})
+	var raw map[string]interface{}
+	_ = json.Unmarshal(data, &raw)
+	if _, ok := raw["dry_run"]; ok {
+		t.Error("dry_run should be omitted when false")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRememberIdempotent — verifies a repeated key returns the original memory.
+// ---------------------------------------------------------------------------
+
+func TestRememberIdempotent(t *testing.T) {
+	byKey := map[string]string{}
+	var gotWindow uint64
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in rememberIdempotentArgs
+		_ = json.Unmarshal(args, &in)
+		gotWindow = in.IdempotencyWindowSeconds
+		if in.IdempotencyKey == nil {
+			return RememberResponse{ID: "mem-unkeyed", Status: "remembered"}, nil
+		}
+		if id, ok := byKey[*in.IdempotencyKey]; ok {
+			return RememberResponse{ID: id, Status: "deduplicated", Duplicate: true, ExistingID: &id}, nil
+		}
+		id := fmt.Sprintf("mem-%d", len(byKey)+1)
+		byKey[*in.IdempotencyKey] = id
+		return RememberResponse{ID: id, Status: "remembered"}, nil
+	})
+	WithIdempotencyWindow(time.Hour)(&c.opts)
+
+	ctx := context.Background()
+	first, err := c.RememberIdempotent(ctx, RememberInput{Content: "retry me"}, "req-42")
+	if err != nil {
+		t.Fatalf("RememberIdempotent: %v", err)
+	}
+	if first.Status != "remembered" {
+		t.Errorf("first Status = %q, want %q", first.Status, "remembered")
+	}
+	if gotWindow != 3600 {
+		t.Errorf("idempotency_window_seconds = %d, want 3600", gotWindow)
+	}
+
+	second, err := c.RememberIdempotent(ctx, RememberInput{Content: "retry me"}, "req-42")
+	if err != nil {
+		t.Fatalf("RememberIdempotent retry: %v", err)
+	}
+	if second.Status != "deduplicated" {
+		t.Errorf("retry Status = %q, want %q", second.Status, "deduplicated")
+	}
+	if second.ID != first.ID {
+		t.Errorf("retry ID = %q, want original %q", second.ID, first.ID)
+	}
+
+	if _, err := c.RememberIdempotent(ctx, RememberInput{Content: "x"}, ""); err == nil {
+		t.Error("RememberIdempotent with empty key: expected error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestMaxResponseBytes — verifies oversized responses need a larger limit.
+// ---------------------------------------------------------------------------
+
+func TestMaxResponseBytes(t *testing.T) {
+	content := strings.Repeat("x", 2*1024*1024)
+	handler := func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		return RecallResponse{Memories: []RecalledMemory{{ID: "mem-big", Content: content}}, Total: 1}, nil
+	}
+
+	c := newPipeClientWithOptions(t, ClientOptions{MaxResponseBytes: 4 * 1024 * 1024}, handler)
+	resp, err := c.Recall(RecallInput{Query: "big"})
+	if err != nil {
+		t.Fatalf("Recall with 4 MB limit: %v", err)
+	}
+	if len(resp.Memories) != 1 || len(resp.Memories[0].Content) != len(content) {
+		t.Errorf("recalled content length = %d, want %d", len(resp.Memories[0].Content), len(content))
+	}
+
+	small := newPipeClient(t, handler)
+	if _, err := small.Recall(RecallInput{Query: "big"}); err == nil {
+		t.Error("Recall with default 1 MB limit: expected error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCompressMessages — verifies large arguments are sent gzip-encoded.
+// ---------------------------------------------------------------------------
+
+func TestCompressMessages(t *testing.T) {
+	var got RememberInput
+	var encoded bool
+	c := newPipeClientWithOptions(t, ClientOptions{CompressMessages: true}, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var b64 string
+		if err := json.Unmarshal(args, &b64); err != nil {
+			_ = json.Unmarshal(args, &got)
+			return RememberResponse{ID: "mem-1", Status: "remembered"}, nil
+		}
+		encoded = true
+		raw, _ := base64.StdEncoding.DecodeString(b64)
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, &jsonRPCError{Code: -32602, Message: err.Error()}
+		}
+		_ = json.NewDecoder(zr).Decode(&got)
+		return RememberResponse{ID: "mem-1", Status: "remembered"}, nil
+	})
+
+	large := strings.Repeat("abc", 40*1024)
+	if _, err := c.Remember(RememberInput{Content: large}); err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+	if !encoded {
+		t.Error("large arguments were not compressed")
+	}
+	if got.Content != large {
+		t.Errorf("decoded content length = %d, want %d", len(got.Content), len(large))
+	}
+
+	encoded = false
+	if _, err := c.Remember(RememberInput{Content: "small"}); err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+	if encoded || got.Content != "small" {
+		t.Error("small arguments should be sent uncompressed")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestPIDAndProcessState — verifies child process introspection.
+// ---------------------------------------------------------------------------
+
+func TestPIDAndProcessState(t *testing.T) {
+	c := newProcessClient(t)
+
+	if pid := c.PID(); pid <= 0 {
+		t.Errorf("PID() = %d, want > 0", pid)
+	}
+	if st := c.ProcessState(); st != nil {
+		t.Errorf("ProcessState() before Close = %v, want nil", st)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if st := c.ProcessState(); st == nil || !st.Exited() {
+		t.Errorf("ProcessState() after Close = %v, want exited", st)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestGracefulClose — verifies in-flight calls finish before the close.
+// ---------------------------------------------------------------------------
+
+func TestGracefulClose(t *testing.T) {
+	started := make(chan struct{})
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		return RememberResponse{ID: "mem-slow", Status: "remembered"}, nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Remember(RememberInput{Content: "slow write"})
+		done <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := c.GracefulClose(ctx); err != nil {
+		t.Fatalf("GracefulClose: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("in-flight Remember = %v, want nil", err)
+		}
+	default:
+		t.Error("GracefulClose returned before the in-flight Remember completed")
+	}
+
+	if _, err := c.Remember(RememberInput{Content: "late"}); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Remember after close = %v, want ErrClientClosed", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestGracefulCloseTimeout — verifies the context bounds the drain.
+// ---------------------------------------------------------------------------
+
+func TestGracefulCloseTimeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		close(started)
+		<-release
+		return RememberResponse{ID: "mem-stuck", Status: "remembered"}, nil
+	})
+
+	go func() { _, _ = c.Remember(RememberInput{Content: "stuck"}) }()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.GracefulClose(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GracefulClose = %v, want context.DeadlineExceeded", err)
+	}
+	if _, err := c.Recall(RecallInput{Query: "q"}); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Recall while draining = %v, want ErrClientClosed", err)
+	}
+	close(release)
+}
+
+// ---------------------------------------------------------------------------
+// TestArchiveRecallAndRestore — verifies archive search and restoration.
+// ---------------------------------------------------------------------------
+
+func TestArchiveRecallAndRestore(t *testing.T) {
+	var gotArgs map[string]interface{}
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		gotArgs = nil
+		_ = json.Unmarshal(args, &gotArgs)
+		switch name {
+		case "mnemo.recall_archive":
+			return RecallResponse{
+				Memories: []RecalledMemory{{ID: "mem-old", Content: "archived fact", Status: "archived"}},
+				Total:    1,
+			}, nil
+		case "mnemo.restore_archive":
+			return RestoreResponse{Restored: []string{"mem-old"}, Status: "restored"}, nil
+		default:
+			return nil, &jsonRPCError{Code: -32601, Message: "unexpected tool " + name}
+		}
+	})
+
+	resp, err := c.ArchiveRecall(ArchiveRecallInput{RecallInput: RecallInput{Query: "fact"}, IncludeActive: true})
+	if err != nil {
+		t.Fatalf("ArchiveRecall: %v", err)
+	}
+	if gotArgs["query"] != "fact" || gotArgs["include_active"] != true {
+		t.Errorf("server args = %v, want query and include_active at top level", gotArgs)
+	}
+	if len(resp.Memories) != 1 || resp.Memories[0].Status != "archived" {
+		t.Errorf("Memories = %+v, want one archived memory", resp.Memories)
+	}
+
+	restored, err := c.RestoreFromArchive(context.Background(), []string{"mem-old"})
+	if err != nil {
+		t.Fatalf("RestoreFromArchive: %v", err)
+	}
+	if restored.Status != "restored" || len(restored.Restored) != 1 {
+		t.Errorf("RestoreResponse = %+v, want mem-old restored", restored)
+	}
+
+	if _, err := c.RestoreFromArchive(context.Background(), nil); err == nil {
+		t.Error("RestoreFromArchive with no IDs: expected error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestProtocolVersionNegotiation — verifies the server's version is adopted.
+// ---------------------------------------------------------------------------
+
+func TestProtocolVersionNegotiation(t *testing.T) {
+	// The fake server always answers with DefaultProtocolVersion.
+	tests := []struct {
+		name      string
+		requested string
+		wantWarn  bool
+		wantNewer bool
+	}{
+		{"default", "", false, false},
+		{"server older", "2025-03-26", true, false},
+		{"server newer", "2024-01-01", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warnings []error
+			opts := ClientOptions{
+				ProtocolVersion: tt.requested,
+				OnWarning:       func(err error) { warnings = append(warnings, err) },
+			}
+			c := newPipeClientWithOptions(t, opts, nil)
+			if err := c.initialize(); err != nil {
+				t.Fatalf("initialize: %v", err)
+			}
+
+			if got := c.ProtocolVersion(); got != DefaultProtocolVersion {
+				t.Errorf("ProtocolVersion() = %q, want %q", got, DefaultProtocolVersion)
+			}
+			if !tt.wantWarn {
+				if len(warnings) != 0 {
+					t.Errorf("warnings = %v, want none", warnings)
+				}
+				return
+			}
+			var mismatch *VersionMismatchWarning
+			if len(warnings) != 1 || !errors.As(warnings[0], &mismatch) {
+				t.Fatalf("warnings = %v, want one VersionMismatchWarning", warnings)
+			}
+			if mismatch.Requested != tt.requested || mismatch.Newer() != tt.wantNewer {
+				t.Errorf("warning = %+v, Newer() = %v, want requested %q newer %v",
+					mismatch, mismatch.Newer(), tt.requested, tt.wantNewer)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestWarmUp — verifies WarmUp sends a single throwaway recall.
+// ---------------------------------------------------------------------------
+
+func TestWarmUp(t *testing.T) {
+	var calls []string
+	var query string
+	c := newPipeClientWithOptions(t, ClientOptions{WarmUpTimeout: time.Second}, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		calls = append(calls, name)
+		var in RecallInput
+		_ = json.Unmarshal(args, &in)
+		query = in.Query
+		return RecallResponse{}, nil
+	})
+
+	if err := c.WarmUp(context.Background()); err != nil {
+		t.Fatalf("WarmUp: %v", err)
+	}
+	if len(calls) != 1 || calls[0] != "mnemo.recall" {
+		t.Errorf("calls = %v, want exactly one mnemo.recall", calls)
+	}
+	if query != "_warmup_" {
+		t.Errorf("query = %q, want %q", query, "_warmup_")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestForgetCascade — verifies related memories survive unless Cascade is set.
+// ---------------------------------------------------------------------------
+
+func TestForgetCascade(t *testing.T) {
+	// mem-child and mem-grandchild descend from mem-parent via RelatedTo.
+	relatedTo := map[string]string{"mem-child": "mem-parent", "mem-grandchild": "mem-child"}
+	var stored map[string]bool
+	reset := func() {
+		stored = map[string]bool{"mem-parent": true, "mem-child": true, "mem-grandchild": true}
+	}
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in ForgetInput
+		_ = json.Unmarshal(args, &in)
+		resp := ForgetResponse{Status: "forgotten"}
+		queue := append([]string(nil), in.MemoryIDs...)
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			delete(stored, id)
+			resp.Forgotten = append(resp.Forgotten, id)
+			if !in.Cascade {
+				continue
+			}
+			for child, parent := range relatedTo {
+				if parent == id && stored[child] {
+					queue = append(queue, child)
+					resp.CascadeCount++
+				}
+			}
+		}
+		return resp, nil
+	})
+
+	reset()
+	resp, err := c.Forget(ForgetInput{MemoryIDs: []string{"mem-parent"}})
+	if err != nil {
+		t.Fatalf("Forget: %v", err)
+	}
+	if resp.CascadeCount != 0 || len(stored) != 2 {
+		t.Errorf("CascadeCount = %d, stored = %v, want related memories untouched", resp.CascadeCount, stored)
+	}
+
+	reset()
+	resp, err = c.Forget(ForgetInput{MemoryIDs: []string{"mem-parent"}, Cascade: true})
+	if err != nil {
+		t.Fatalf("Forget cascade: %v", err)
+	}
+	if resp.CascadeCount != 2 || len(stored) != 0 {
+		t.Errorf("CascadeCount = %d, stored = %v, want 2 descendants forgotten", resp.CascadeCount, stored)
+	}
+
+	data, _ := json.Marshal(ForgetInput{MemoryIDs: []string{"mem-parent"}})
+	var raw map[string]interface{}
+	_ = json.Unmarshal(data, &raw)
+	if _, ok := raw["cascade"]; ok {
+		t.Error("cascade should be omitted when false")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestMergeConflicts — verifies conflict reports and resolver validation.
+// ---------------------------------------------------------------------------
+
+func TestMergeConflicts(t *testing.T) {
+	var gotResolver string
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in MergeInput
+		_ = json.Unmarshal(args, &in)
+		if in.ConflictResolver != nil {
+			gotResolver = *in.ConflictResolver
+		}
+		return MergeResponse{
+			CheckpointID:      "cp-9",
+			TargetBranch:      "main",
+			MergedMemoryCount: 3,
+			Status:            "merged",
+			Conflicts: []MergeConflict{
+				{MemoryID: "mem-1", Resolution: gotResolver},
+				{MemoryID: "mem-2", Resolution: gotResolver},
+			},
+		}, nil
+	})
+
+	resolver := ConflictResolverNewerWins
+	resp, err := c.Merge(MergeInput{ThreadID: "thread-1", SourceBranch: "experiment", ConflictResolver: &resolver})
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if gotResolver != "newer_wins" {
+		t.Errorf("conflict_resolver = %q, want %q", gotResolver, "newer_wins")
+	}
+	if len(resp.Conflicts) != 2 {
+		t.Fatalf("len(Conflicts) = %d, want 2", len(resp.Conflicts))
+	}
+	if resp.Conflicts[1].MemoryID != "mem-2" || resp.Conflicts[1].Resolution != ConflictResolverNewerWins {
+		t.Errorf("Conflicts[1] = %+v, want mem-2 resolved by newer_wins", resp.Conflicts[1])
+	}
+
+	bogus := "coin_flip"
+	var verr *ValidationError
+	if _, err := c.Merge(MergeInput{ThreadID: "thread-1", SourceBranch: "x", ConflictResolver: &bogus}); !errors.As(err, &verr) {
+		t.Errorf("Merge with unknown resolver = %v, want ValidationError", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestAuditQueryInputJSON — verifies AuditQueryInput marshaling.
+// ---------------------------------------------------------------------------
+
+func TestAuditQueryInputJSON(t *testing.T) {
+	agent := "agent-1"
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limit := 50
+
+	data, err := json.Marshal(AuditQueryInput{
+		AgentID:    &agent,
+		EventTypes: []string{"memory_read", "memory_deleted"},
+		After:      &after,
+		Limit:      &limit,
+	})
+	if err != nil {
+		t.Fatalf("Marshal AuditQueryInput: %v", err)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal raw: %v", err)
+	}
+	if raw["after"] != "2024-01-01T00:00:00Z" {
+		t.Errorf("after = %v, want %q", raw["after"], "2024-01-01T00:00:00Z")
+	}
+	if _, ok := raw["before"]; ok {
+		t.Error("before should be omitted when nil")
+	}
+	if _, ok := raw["memory_id"]; ok {
+		t.Error("memory_id should be omitted when nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestAuditResponseJSON — verifies AuditResponse unmarshaling.
+// ---------------------------------------------------------------------------
+
+func TestAuditResponseJSON(t *testing.T) {
+	var empty AuditResponse
+	if err := json.Unmarshal([]byte(`{"events":[],"total":0}`), &empty); err != nil {
+		t.Fatalf("Unmarshal empty AuditResponse: %v", err)
+	}
+	if empty.Events == nil || len(empty.Events) != 0 || empty.Total != 0 {
+		t.Errorf("empty AuditResponse = %+v, want non-nil empty Events and Total 0", empty)
+	}
+
+	raw := `{
+		"events": [
+			{"id":"ev-1","memory_id":"mem-1","agent_id":"agent-1","event_type":"memory_created","detail":"remember","timestamp":"2024-01-01T00:00:00Z"},
+			{"id":"ev-2","memory_id":"mem-1","agent_id":"agent-2","event_type":"memory_read","detail":"recall","timestamp":"2024-01-01T00:05:00Z"}
+		],
+		"total": 7
+	}`
+	var resp AuditResponse
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		t.Fatalf("Unmarshal AuditResponse: %v", err)
+	}
+	if resp.Total != 7 {
+		t.Errorf("Total = %d, want 7", resp.Total)
+	}
+	if len(resp.Events) != 2 {
+		t.Fatalf("len(Events) = %d, want 2", len(resp.Events))
+	}
+	if ev := resp.Events[1]; ev.ID != "ev-2" || ev.AgentID != "agent-2" || ev.EventType != "memory_read" || ev.Timestamp != "2024-01-01T00:05:00Z" {
+		t.Errorf("Events[1] = %+v", ev)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestListAuditEvents — verifies ListAuditEvents calls mnemo.audit_query.
+// ---------------------------------------------------------------------------
+
+func TestListAuditEvents(t *testing.T) {
+	var tool string
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		tool = name
+		return AuditResponse{Events: []AuditEvent{{ID: "ev-1", EventType: "memory_read"}}, Total: 1}, nil
+	})
+
+	resp, err := c.ListAuditEvents(AuditQueryInput{})
+	if err != nil {
+		t.Fatalf("ListAuditEvents: %v", err)
+	}
+	if tool != "mnemo.audit_query" {
+		t.Errorf("tool = %q, want mnemo.audit_query", tool)
+	}
+	if resp.Total != 1 || len(resp.Events) != 1 || resp.Events[0].ID != "ev-1" {
+		t.Errorf("ListAuditEvents() = %+v, want one event ev-1", resp)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestHealthCheck — verifies ok, degraded, and unavailable health reports.
+// ---------------------------------------------------------------------------
+
+func TestHealthCheck(t *testing.T) {
+	fixtures := map[string]string{
+		HealthOK: `{"status":"ok","storage_ok":true,"embedding_model_ok":true,"hash_chain_ok":true,"version":"0.4.0","uptime":"1h0m0s"}`,
+		HealthDegraded: `{"status":"degraded","storage_ok":true,"embedding_model_ok":false,"hash_chain_ok":true,"version":"0.4.0","uptime":"1h0m0s",
+			"details":{"embedding_model":"openai: 429 too many requests"}}`,
+		HealthUnavailable: `{"status":"unavailable","storage_ok":false,"embedding_model_ok":false,"hash_chain_ok":false,"version":"0.4.0","uptime":"1h0m0s",
+			"details":{"storage":"database is locked"}}`,
+	}
+
+	for want, fixture := range fixtures {
+		t.Run(want, func(t *testing.T) {
+			c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+				if name != "mnemo.health" {
+					t.Errorf("tool = %q, want mnemo.health", name)
+				}
+				return json.RawMessage(fixture), nil
+			})
+
+			status, err := c.HealthCheck(context.Background())
+			if status == nil {
+				t.Fatalf("HealthCheck() status = nil, err = %v", err)
+			}
+			if status.Status != want {
+				t.Errorf("Status = %q, want %q", status.Status, want)
+			}
+
+			var degraded *DegradedError
+			if want == HealthOK {
+				if err != nil {
+					t.Errorf("HealthCheck() err = %v, want nil", err)
+				}
+				if !status.StorageOK || !status.EmbeddingModelOK || !status.HashChainOK {
+					t.Errorf("HealthStatus = %+v, want all components ok", status)
+				}
+				return
+			}
+			if !errors.As(err, &degraded) {
+				t.Fatalf("HealthCheck() err = %v, want *DegradedError", err)
+			}
+			if degraded.Status != want || len(degraded.Details) != 1 {
+				t.Errorf("DegradedError = %+v", degraded)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestSchemaVersion — verifies the schema check NewClient performs.
+// ---------------------------------------------------------------------------
+
+func TestSchemaVersion(t *testing.T) {
+	t.Run("compatible", func(t *testing.T) {
+		c := newProcessClient(t)
+		defer c.Close()
+
+		resp, err := c.SchemaVersion()
+		if err != nil {
+			t.Fatalf("SchemaVersion: %v", err)
+		}
+		if resp.MigrationRequired {
+			t.Error("MigrationRequired = true, want false")
+		}
+	})
+
+	t.Run("migration required", func(t *testing.T) {
+		t.Setenv(fakeServerEnv, "1")
+		t.Setenv(fakeMigrationEnv, "1")
+
+		c, err := NewClient(ClientOptions{Command: os.Args[0]})
+		if err == nil {
+			c.Close()
+			t.Fatal("NewClient succeeded, want SchemaMigrationRequiredError")
+		}
+		var serr *SchemaMigrationRequiredError
+		if !errors.As(err, &serr) {
+			t.Fatalf("NewClient() err = %v, want *SchemaMigrationRequiredError", err)
+		}
+		if serr.Version != 3 || serr.MinCompatible != 5 {
+			t.Errorf("SchemaMigrationRequiredError = %+v, want Version 3, MinCompatible 5", serr)
+		}
+	})
+
+	t.Run("skip schema check", func(t *testing.T) {
+		t.Setenv(fakeServerEnv, "1")
+		t.Setenv(fakeMigrationEnv, "1")
+
+		c, err := NewClient(ClientOptions{Command: os.Args[0]}, WithSkipSchemaCheck())
+		if err != nil {
+			t.Fatalf("NewClient with WithSkipSchemaCheck: %v", err)
+		}
+		defer c.Close()
+
+		resp, err := c.SchemaVersion()
+		if err != nil {
+			t.Fatalf("SchemaVersion: %v", err)
+		}
+		if !resp.MigrationRequired || resp.Version != 3 {
+			t.Errorf("SchemaVersion() = %+v, want migration required at version 3", resp)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		var warned error
+		c := newPipeClientWithOptions(t, ClientOptions{OnWarning: func(err error) { warned = err }},
+			func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+				return nil, &jsonRPCError{Code: -32601, Message: "unknown tool"}
+			})
+
+		if err := c.checkSchema(context.Background()); err != nil {
+			t.Errorf("checkSchema() = %v, want nil for a server without schema_version", err)
+		}
+		if warned == nil {
+			t.Error("OnWarning not called for failed schema check")
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// TestVacuum — verifies the vacuum request and response.
+// ---------------------------------------------------------------------------
+
+func TestVacuum(t *testing.T) {
+	var gotArgs []map[string]interface{}
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var raw map[string]interface{}
+		_ = json.Unmarshal(args, &raw)
+		gotArgs = append(gotArgs, raw)
+		status := "completed"
+		if _, ok := raw["max_duration_ms"]; ok {
+			status = "partial"
+		}
+		return VacuumResponse{RecoveredBytes: 4096, DeletedCount: 12, Duration: "250ms", Status: status}, nil
+	})
+
+	agent := "agent-1"
+	limit := 1500 * time.Millisecond
+	resp, err := c.Vacuum(VacuumInput{AgentID: &agent, MaxDuration: &limit})
+	if err != nil {
+		t.Fatalf("Vacuum: %v", err)
+	}
+	if resp.RecoveredBytes < 0 || resp.DeletedCount < 0 {
+		t.Errorf("Vacuum() = %+v, want non-negative counts", resp)
+	}
+	if resp.Status != "partial" {
+		t.Errorf("Status = %q, want %q", resp.Status, "partial")
+	}
+	if gotArgs[0]["max_duration_ms"] != float64(1500) || gotArgs[0]["agent_id"] != "agent-1" {
+		t.Errorf("args = %v, want max_duration_ms 1500 and agent_id agent-1", gotArgs[0])
+	}
+
+	zero := time.Duration(0)
+	resp, err = c.Vacuum(VacuumInput{MaxDuration: &zero})
+	if err != nil {
+		t.Fatalf("Vacuum: %v", err)
+	}
+	if _, ok := gotArgs[1]["max_duration_ms"]; ok {
+		t.Errorf("args = %v, want max_duration_ms omitted for zero MaxDuration", gotArgs[1])
+	}
+	if resp.Status != "completed" {
+		t.Errorf("Status = %q, want %q", resp.Status, "completed")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestToolAliases — verifies aliased tool names are sent to the server.
+// ---------------------------------------------------------------------------
+
+func TestToolAliases(t *testing.T) {
+	base := map[string]string{"mnemo.recall": "memory.search"}
+	opts := ClientOptions{ToolAliases: base}
+	WithToolAlias("mnemo.remember", "memory.store")(&opts)
+	if len(base) != 1 {
+		t.Errorf("WithToolAlias modified the caller's map: %v", base)
+	}
+
+	var names []string
+	c := newPipeClientWithOptions(t, opts, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		names = append(names, name)
+		return map[string]interface{}{"status": "ok"}, nil
+	})
+	var methods []string
+	c.Use(func(ctx context.Context, method string, req, resp interface{}, next Handler) error {
+		methods = append(methods, method)
+		return next(ctx, req, resp)
+	})
+
+	if _, err := c.Remember(RememberInput{Content: "aliased"}); err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+	if _, err := c.Recall(RecallInput{Query: "aliased"}); err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if _, err := c.Verify(VerifyInput{}); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	want := []string{"memory.store", "memory.search", "mnemo.verify"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("params.name[%d] = %q, want %q", i, names[i], want[i])
+		}
+	}
+	if methods[0] != "mnemo.remember" {
+		t.Errorf("interceptor method = %q, want the SDK name", methods[0])
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestDialFunc — verifies a client can run over a dialed connection.
+// ---------------------------------------------------------------------------
+
+func TestDialFunc(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	go serveFake(serverConn, serverConn, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		if name != "mnemo.remember" {
+			return map[string]interface{}{}, nil
+		}
+		var in RememberInput
+		_ = json.Unmarshal(args, &in)
+		return RememberResponse{ID: "mem-net", Status: "remembered", ContentHash: in.Content}, nil
+	})
+
+	var dials int
+	c, err := NewClient(ClientOptions{
+		Command: "/nonexistent/mnemo",
+		DialFunc: func() (io.ReadWriteCloser, error) {
+			dials++
+			return clientConn, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if dials != 1 {
+		t.Errorf("DialFunc called %d times, want 1", dials)
+	}
+	if c.PID() != 0 {
+		t.Errorf("PID() = %d, want 0 without a child process", c.PID())
+	}
+	if c.ProtocolVersion() != "2024-11-05" {
+		t.Errorf("ProtocolVersion() = %q, want the handshake to have run", c.ProtocolVersion())
+	}
+
+	resp, err := c.Remember(RememberInput{Content: "over the wire"})
+	if err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+	if resp.ID != "mem-net" || resp.ContentHash != "over the wire" {
+		t.Errorf("Remember() = %+v, want mem-net echoing the content", resp)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+
+	if _, err := NewClient(ClientOptions{DialFunc: func() (io.ReadWriteCloser, error) {
+		return nil, errors.New("connection refused")
+	}}); err == nil {
+		t.Error("NewClient with failing DialFunc: expected error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestReplayRangeMode — verifies range replays send their bounds and are
+// validated.
+// ---------------------------------------------------------------------------
+
+func TestReplayRangeMode(t *testing.T) {
+	mode := ReplayModeRange
+	from, to := "cp-1", "cp-3"
+	data, err := json.Marshal(ReplayInput{ThreadID: "thread-1", ReplayMode: &mode, FromCheckpointID: &from, ToCheckpointID: &to})
+	if err != nil {
+		t.Fatalf("Marshal ReplayInput: %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal raw: %v", err)
+	}
+	if raw["replay_mode"] != "range" || raw["from_checkpoint_id"] != "cp-1" || raw["to_checkpoint_id"] != "cp-3" {
+		t.Errorf("marshaled ReplayInput = %s, want range from cp-1 to cp-3", data)
+	}
+
+	plain, _ := json.Marshal(ReplayInput{ThreadID: "thread-1"})
+	if strings.Contains(string(plain), "replay_mode") || strings.Contains(string(plain), "from_checkpoint_id") {
+		t.Errorf("marshaled ReplayInput = %s, want range fields omitted", plain)
+	}
+
+	all := ReplayModeAll
+	bogus := "sideways"
+	invalid := []ReplayInput{
+		{ThreadID: "thread-1", ReplayMode: &mode, ToCheckpointID: &to},
+		{ThreadID: "thread-1", ReplayMode: &mode, FromCheckpointID: &from},
+		{ThreadID: "thread-1", ReplayMode: &all, FromCheckpointID: &from},
+		{ThreadID: "thread-1", FromCheckpointID: &from, ToCheckpointID: &to},
+		{ThreadID: "thread-1", ReplayMode: &bogus},
+	}
+	for i, in := range invalid {
+		var verr *ValidationError
+		if err := in.Validate(); !errors.As(err, &verr) {
+			t.Errorf("invalid[%d].Validate() = %v, want ValidationError", i, err)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestBranchCopyMemories — verifies copied branches report their copy count.
+// ---------------------------------------------------------------------------
+
+func TestBranchCopyMemories(t *testing.T) {
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in BranchInput
+		_ = json.Unmarshal(args, &in)
+		resp := BranchResponse{CheckpointID: "cp-b", BranchName: in.NewBranchName, Status: "branched"}
+		if in.CopyMemories {
+			resp.CopiedMemoryCount = 7
+			if in.CopyDepth != nil {
+				resp.CopiedMemoryCount = *in.CopyDepth
+			}
+		}
+		return resp, nil
+	})
+
+	shared, err := c.Branch(BranchInput{ThreadID: "thread-1", NewBranchName: "shared"})
+	if err != nil {
+		t.Fatalf("Branch: %v", err)
+	}
+	if shared.CopiedMemoryCount != 0 {
+		t.Errorf("CopiedMemoryCount = %d, want 0 without CopyMemories", shared.CopiedMemoryCount)
+	}
+
+	depth := 2
+	copied, err := c.Branch(BranchInput{ThreadID: "thread-1", NewBranchName: "copied", CopyMemories: true, CopyDepth: &depth})
+	if err != nil {
+		t.Fatalf("Branch: %v", err)
+	}
+	if copied.CopiedMemoryCount <= 0 {
+		t.Errorf("CopiedMemoryCount = %d, want a positive count with CopyMemories", copied.CopiedMemoryCount)
+	}
+
+	var verr *ValidationError
+	if _, err := c.Branch(BranchInput{ThreadID: "thread-1", NewBranchName: "x", CopyDepth: &depth}); !errors.As(err, &verr) {
+		t.Errorf("Branch with CopyDepth but no CopyMemories = %v, want ValidationError", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestSummarizeThread — verifies StoredMemoryID is set only when the summary
+// is stored.
+// ---------------------------------------------------------------------------
+
+func TestSummarizeThread(t *testing.T) {
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		if name != "mnemo.summarize_thread" {
+			t.Errorf("tool = %q, want mnemo.summarize_thread", name)
+		}
+		var in SummarizeInput
+		_ = json.Unmarshal(args, &in)
+		resp := SummaryResponse{Summary: "The user planned a trip.", MemoryCount: 12, Status: "summarized"}
+		if in.StoreSummary {
+			id := "mem-summary"
+			resp.StoredMemoryID = &id
+		}
+		return resp, nil
+	})
+
+	resp, err := c.SummarizeThread(SummarizeInput{ThreadID: "thread-1"})
+	if err != nil {
+		t.Fatalf("SummarizeThread: %v", err)
+	}
+	if resp.StoredMemoryID != nil {
+		t.Errorf("StoredMemoryID = %q, want nil when StoreSummary is false", *resp.StoredMemoryID)
+	}
+	if resp.Summary == "" || resp.MemoryCount != 12 {
+		t.Errorf("SummarizeThread() = %+v", resp)
+	}
+
+	stored, err := c.SummarizeThread(SummarizeInput{ThreadID: "thread-1", StoreSummary: true})
+	if err != nil {
+		t.Fatalf("SummarizeThread: %v", err)
+	}
+	if stored.StoredMemoryID == nil || *stored.StoredMemoryID != "mem-summary" {
+		t.Errorf("StoredMemoryID = %v, want mem-summary", stored.StoredMemoryID)
+	}
+
+	zero := 0
+	var verr *ValidationError
+	if _, err := c.SummarizeThread(SummarizeInput{ThreadID: "thread-1", MaxMemories: &zero}); !errors.As(err, &verr) {
+		t.Errorf("SummarizeThread with zero MaxMemories = %v, want ValidationError", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestDelegateScopeQuery — verifies ScopeQuery excludes MemoryIDs and
+// reports its match count.
+// ---------------------------------------------------------------------------
+
+func TestDelegateScopeQuery(t *testing.T) {
+	var calls int
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		calls++
+		return DelegateResponse{DelegationID: "del-1", Permission: "read", Status: "delegated", ScopeQueryCount: 42}, nil
+	})
+
+	query := "customer escalations"
+	var verr *ValidationError
+	_, err := c.Delegate(DelegateInput{DelegateID: "agent-2", Permission: "read", ScopeQuery: &query, MemoryIDs: []string{"mem-1"}})
+	if !errors.As(err, &verr) || verr.Field != "scope_query" {
+		t.Errorf("Delegate with ScopeQuery and MemoryIDs = %v, want ValidationError on scope_query", err)
+	}
+	if calls != 0 {
+		t.Errorf("server calls = %d, want 0 for an invalid input", calls)
+	}
+
+	resp, err := c.Delegate(DelegateInput{DelegateID: "agent-2", Permission: "read", ScopeQuery: &query})
+	if err != nil {
+		t.Fatalf("Delegate: %v", err)
+	}
+	if resp.ScopeQueryCount != 42 {
+		t.Errorf("ScopeQueryCount = %d, want 42", resp.ScopeQueryCount)
+	}
+
+	if err := (DelegateInput{DelegateID: "agent-2", Permission: "read", MemoryIDs: []string{"mem-1"}}).Validate(); err != nil {
+		t.Errorf("Validate with only MemoryIDs = %v, want nil", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestGroupsJSON — verifies group sharing and group management types.
+// ---------------------------------------------------------------------------
+
+func TestGroupsJSON(t *testing.T) {
+	group := "support-team"
+	data, err := json.Marshal(ShareInput{MemoryID: "mem-uuid", GroupID: &group})
+	if err != nil {
+		t.Fatalf("Marshal ShareInput: %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+	if raw["group_id"] != "support-team" {
+		t.Errorf("group_id = %v, want %q", raw["group_id"], "support-team")
+	}
+	data, err = json.Marshal(ShareInput{MemoryID: "mem-uuid", TargetAgentID: "agent-2"})
+	if err != nil {
+		t.Fatalf("Marshal ShareInput: %v", err)
+	}
+	if strings.Contains(string(data), "group_id") {
+		t.Errorf("expected 'group_id' to be omitted when nil, got %s", data)
+	}
+
+	var shared ShareResponse
+	raw2 := `{"acl_ids": ["acl-1", "acl-2"], "memory_id": "mem-uuid", "shared_with": ["agent-2", "agent-3"], "permission": "read", "status": "shared"}`
+	if err := json.Unmarshal([]byte(raw2), &shared); err != nil {
+		t.Fatalf("Unmarshal ShareResponse: %v", err)
+	}
+	if !reflect.DeepEqual(shared.SharedWith, []string{"agent-2", "agent-3"}) {
+		t.Errorf("SharedWith = %v, want [agent-2 agent-3]", shared.SharedWith)
+	}
+
+	data, err = json.Marshal(CreateGroupInput{GroupID: "support-team", Name: "Support", AgentIDs: []string{"agent-2", "agent-3"}})
+	if err != nil {
+		t.Fatalf("Marshal CreateGroupInput: %v", err)
+	}
+	raw = nil
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+	if raw["group_id"] != "support-team" || raw["name"] != "Support" {
+		t.Errorf("CreateGroupInput JSON = %s", data)
+	}
+	if ids, _ := raw["agent_ids"].([]interface{}); len(ids) != 2 {
+		t.Errorf("agent_ids = %v, want 2 entries", raw["agent_ids"])
+	}
+	if err := (CreateGroupInput{GroupID: "support-team"}).Validate(); err == nil {
+		t.Error("Validate with no AgentIDs = nil, want error")
+	}
+
+	var created CreateGroupResponse
+	raw3 := `{"group_id": "support-team", "name": "Support", "agent_ids": ["agent-2"], "status": "created"}`
+	if err := json.Unmarshal([]byte(raw3), &created); err != nil {
+		t.Fatalf("Unmarshal CreateGroupResponse: %v", err)
+	}
+	if created.GroupID != "support-team" || created.Status != "created" || len(created.AgentIDs) != 1 {
+		t.Errorf("CreateGroupResponse = %+v", created)
+	}
+
+	var listed ListGroupsResponse
+	raw4 := `{"groups": [{"group_id": "support-team", "name": "Support", "agent_ids": ["agent-2", "agent-3"]}], "total": 1}`
+	if err := json.Unmarshal([]byte(raw4), &listed); err != nil {
+		t.Fatalf("Unmarshal ListGroupsResponse: %v", err)
+	}
+	if listed.Total != 1 || len(listed.Groups) != 1 {
+		t.Fatalf("ListGroupsResponse = %+v", listed)
+	}
+	if !reflect.DeepEqual(listed.Groups[0].AgentIDs, []string{"agent-2", "agent-3"}) {
+		t.Errorf("Groups[0].AgentIDs = %v", listed.Groups[0].AgentIDs)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCompareMemories — verifies CompareMemories arguments and diff decoding.
+// ---------------------------------------------------------------------------
+
+func TestCompareMemories(t *testing.T) {
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		if name != "mnemo.compare_memories" {
+			return nil, &jsonRPCError{Code: -32601, Message: "unexpected tool " + name}
+		}
+		var in compareMemoriesInput
+		if err := json.Unmarshal(args, &in); err != nil || in.MemoryIDA != "mem-a" || in.MemoryIDB != "mem-b" {
+			return nil, &jsonRPCError{Code: -32602, Message: "bad arguments " + string(args)}
+		}
+		return json.RawMessage(`{
+			"memory_id_a": "mem-a",
+			"memory_id_b": "mem-b",
+			"content_diff": "-User prefers tea\n+User prefers coffee\n",
+			"importance_delta": 0.25,
+			"tags_added": ["beverage", "verified"],
+			"tags_removed": ["unconfirmed"],
+			"metadata_changes": {"source": "chat", "stale": null}
+		}`), nil
+	})
+
+	diff, err := c.CompareMemories(context.Background(), "mem-a", "mem-b")
+	if err != nil {
+		t.Fatalf("CompareMemories: %v", err)
+	}
+	if !reflect.DeepEqual(diff.TagsAdded, []string{"beverage", "verified"}) {
+		t.Errorf("TagsAdded = %v, want [beverage verified]", diff.TagsAdded)
+	}
+	if !reflect.DeepEqual(diff.TagsRemoved, []string{"unconfirmed"}) {
+		t.Errorf("TagsRemoved = %v, want [unconfirmed]", diff.TagsRemoved)
+	}
+	if diff.ImportanceDelta != 0.25 {
+		t.Errorf("ImportanceDelta = %v, want 0.25", diff.ImportanceDelta)
+	}
+	if v, ok := diff.MetadataChanges["stale"]; !ok || v != nil {
+		t.Errorf("MetadataChanges[stale] = %v, %v; want nil, true", v, ok)
+	}
+
+	var verr *ValidationError
+	if _, err := c.CompareMemories(context.Background(), "mem-a", ""); !errors.As(err, &verr) {
+		t.Errorf("CompareMemories with empty idB = %v, want ValidationError", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestScoreSimilarityJSON — verifies similarity scoring types and decoding.
+// ---------------------------------------------------------------------------
+
+func TestScoreSimilarityJSON(t *testing.T) {
+	data, err := json.Marshal(ScoreSimilarityInput{MemoryIDA: "mem-a", MemoryIDB: "mem-b"})
+	if err != nil {
+		t.Fatalf("Marshal ScoreSimilarityInput: %v", err)
+	}
+	var in ScoreSimilarityInput
+	if err := json.Unmarshal(data, &in); err != nil {
+		t.Fatalf("Unmarshal ScoreSimilarityInput: %v", err)
+	}
+	if in.MemoryIDA != "mem-a" || in.MemoryIDB != "mem-b" {
+		t.Errorf("ScoreSimilarityInput round trip = %+v", in)
+	}
+
+	var resp ScoreSimilarityResponse
+	if err := json.Unmarshal([]byte(`{"score": 0.875, "status": "ok"}`), &resp); err != nil {
+		t.Fatalf("Unmarshal ScoreSimilarityResponse: %v", err)
+	}
+	if k := reflect.TypeOf(resp.Score).Kind(); k != reflect.Float32 {
+		t.Errorf("Score kind = %v, want float32", k)
+	}
+
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		return ScoreSimilarityResponse{Score: 0.875, Status: "ok"}, nil
+	})
+	score, err := c.ScoreMemorySimilarity("mem-a", "mem-b")
+	if err != nil {
+		t.Fatalf("ScoreMemorySimilarity: %v", err)
+	}
+	if score != 0.875 {
+		t.Errorf("score = %v, want 0.875", score)
+	}
+	if _, err := c.ScoreMemorySimilarity("", "mem-b"); err == nil {
+		t.Error("ScoreMemorySimilarity with empty idA = nil error, want ValidationError")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestGetEmbedding — verifies embedding decoding and the dimension check.
+// ---------------------------------------------------------------------------
+
+func TestGetEmbedding(t *testing.T) {
+	dims := 3
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in GetEmbeddingInput
+		_ = json.Unmarshal(args, &in)
+		model := "text-embedding-small"
+		if in.ModelOverride != nil {
+			model = *in.ModelOverride
+		}
+		return json.RawMessage(fmt.Sprintf(`{"embedding": [0.1, -0.5, 0.25], "model": %q, "dimensions": %d}`, model, dims)), nil
+	})
+
+	emb, err := c.GetEmbedding("hello world")
+	if err != nil {
+		t.Fatalf("GetEmbedding: %v", err)
+	}
+	if !reflect.DeepEqual(emb, []float32{0.1, -0.5, 0.25}) {
+		t.Errorf("embedding = %v, want [0.1 -0.5 0.25]", emb)
+	}
+
+	override := "text-embedding-large"
+	resp, err := c.GetEmbeddingContext(context.Background(), GetEmbeddingInput{Content: "hello world", ModelOverride: &override})
+	if err != nil {
+		t.Fatalf("GetEmbeddingContext: %v", err)
+	}
+	if resp.Model != override || resp.Dimensions != 3 {
+		t.Errorf("response = %+v, want model %q with 3 dimensions", resp, override)
+	}
+
+	dims = 4
+	if _, err := c.GetEmbedding("hello world"); err == nil {
+		t.Error("GetEmbedding with mismatched dimensions = nil error, want error")
+	}
+	var verr *ValidationError
+	if _, err := c.GetEmbedding(""); !errors.As(err, &verr) {
+		t.Errorf("GetEmbedding with empty content = %v, want ValidationError", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestForgetSimulateDecay — verifies decay simulation predicts victims
+// without deleting and is rejected for other strategies.
+// ---------------------------------------------------------------------------
+
+func TestForgetSimulateDecay(t *testing.T) {
+	var calls int
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		calls++
+		var in ForgetInput
+		_ = json.Unmarshal(args, &in)
+		if !in.SimulateDecay || in.DecaySimulationHours == nil || *in.DecaySimulationHours != 72 {
+			return nil, &jsonRPCError{Code: -32602, Message: "bad arguments " + string(args)}
+		}
+		return ForgetResponse{Forgotten: []string{"mem-stale"}, Status: "simulated"}, nil
+	})
+
+	decay := "decay"
+	hours := 72.0
+	resp, err := c.Forget(ForgetInput{Strategy: &decay, SimulateDecay: true, DecaySimulationHours: &hours})
+	if err != nil {
+		t.Fatalf("Forget: %v", err)
+	}
+	if !reflect.DeepEqual(resp.Forgotten, []string{"mem-stale"}) {
+		t.Errorf("Forgotten = %v, want the predicted victim", resp.Forgotten)
+	}
+
+	hardDelete := "hard_delete"
+	var verr *ValidationError
+	_, err = c.Forget(ForgetInput{MemoryIDs: []string{"mem-1"}, Strategy: &hardDelete, SimulateDecay: true})
+	if !errors.As(err, &verr) || verr.Field != "simulate_decay" {
+		t.Errorf("Forget with hard_delete and SimulateDecay = %v, want ValidationError on simulate_decay", err)
+	}
+	if err := (ForgetInput{SimulateDecay: true}).Validate(); err == nil {
+		t.Error("Validate with SimulateDecay and default strategy = nil, want error")
+	}
+	if calls != 1 {
+		t.Errorf("server calls = %d, want 1", calls)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestMergeDryRun — verifies dry-run and live merges report different
+// statuses and only the live merge changes state.
+// ---------------------------------------------------------------------------
+
+func TestMergeDryRun(t *testing.T) {
+	merged := false
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in MergeInput
+		_ = json.Unmarshal(args, &in)
+		resp := MergeResponse{
+			CheckpointID:      "cp-next",
+			TargetBranch:      "main",
+			MergedMemoryCount: 2,
+			MergedMemoryIDs:   []string{"mem-1", "mem-2"},
+			Conflicts:         []MergeConflict{{MemoryID: "mem-2", Resolution: ConflictResolverSourceWins}},
+			Status:            "merged",
+		}
+		if in.DryRun {
+			resp.Status = "dry_run"
+			return resp, nil
+		}
+		merged = true
+		return resp, nil
+	})
+
+	preview, err := c.Merge(MergeInput{ThreadID: "thread-1", SourceBranch: "experiment", DryRun: true})
+	if err != nil {
+		t.Fatalf("Merge dry run: %v", err)
+	}
+	if preview.Status != "dry_run" {
+		t.Errorf("dry-run Status = %q, want %q", preview.Status, "dry_run")
+	}
+	if merged {
+		t.Error("dry run changed state")
+	}
+	if !reflect.DeepEqual(preview.MergedMemoryIDs, []string{"mem-1", "mem-2"}) || len(preview.Conflicts) != 1 || preview.CheckpointID != "cp-next" {
+		t.Errorf("dry-run response = %+v, want merged IDs, conflicts, and checkpoint", preview)
+	}
+
+	live, err := c.Merge(MergeInput{ThreadID: "thread-1", SourceBranch: "experiment"})
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if live.Status != "merged" || !merged {
+		t.Errorf("live Status = %q, merged = %v; want %q and true", live.Status, merged, "merged")
+	}
+
+	data, _ := json.Marshal(MergeInput{ThreadID: "thread-1", SourceBranch: "experiment"})
+	if strings.Contains(string(data), "dry_run") {
+		t.Errorf("dry_run should be omitted when false, got %s", data)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestProtectedBranches — verifies Checkpoint and Branch refuse protected
+// branches without contacting the server.
+// ---------------------------------------------------------------------------
+
+func TestProtectedBranches(t *testing.T) {
+	var tools []string
+	c := newPipeClientWithOptions(t, ClientOptions{ProtectedBranches: []string{"main", "release"}}, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		tools = append(tools, name)
+		switch name {
+		case "mnemo.checkpoint":
+			return CheckpointResponse{CheckpointID: "cp-1", BranchName: "experiment", Status: "created"}, nil
+		case "mnemo.set_branch_protection":
+			var in setBranchProtectionInput
+			_ = json.Unmarshal(args, &in)
+			return BranchProtectionResponse{ThreadID: in.ThreadID, BranchName: in.BranchName, Protected: in.Protected, Status: "updated"}, nil
+		}
+		return BranchResponse{CheckpointID: "cp-2", BranchName: "experiment", Status: "created"}, nil
+	})
+
+	var perr *ProtectedBranchError
+	if _, err := c.Checkpoint(CheckpointInput{ThreadID: "thread-1", StateSnapshot: map[string]string{}}); !errors.As(err, &perr) || perr.Branch != "main" {
+		t.Errorf("Checkpoint on default branch = %v, want ProtectedBranchError for main", err)
+	}
+	release := "release"
+	if _, err := c.Checkpoint(CheckpointInput{ThreadID: "thread-1", BranchName: &release, StateSnapshot: map[string]string{}}); !errors.As(err, &perr) || perr.Branch != "release" {
+		t.Errorf("Checkpoint on release = %v, want ProtectedBranchError for release", err)
+	}
+	if _, err := c.Branch(BranchInput{ThreadID: "thread-1", NewBranchName: "main"}); !errors.As(err, &perr) {
+		t.Errorf("Branch to main = %v, want ProtectedBranchError", err)
+	}
+	if len(tools) != 0 {
+		t.Fatalf("server called for protected branches: %v", tools)
+	}
+
+	experiment := "experiment"
+	if _, err := c.Checkpoint(CheckpointInput{ThreadID: "thread-1", BranchName: &experiment, StateSnapshot: map[string]string{}}); err != nil {
+		t.Errorf("Checkpoint on experiment: %v", err)
+	}
+	if _, err := c.Branch(BranchInput{ThreadID: "thread-1", NewBranchName: "experiment", SourceBranch: &release}); err != nil {
+		t.Errorf("Branch from a protected branch: %v", err)
+	}
+
+	resp, err := c.SetBranchProtection(context.Background(), "thread-1", "experiment", true)
+	if err != nil {
+		t.Fatalf("SetBranchProtection: %v", err)
+	}
+	if !resp.Protected || resp.BranchName != "experiment" {
+		t.Errorf("SetBranchProtection response = %+v", resp)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestReset — verifies Reset requires the confirm string and reports counts.
+// ---------------------------------------------------------------------------
+
+func TestReset(t *testing.T) {
+	var calls int
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		calls++
+		var in resetAgentInput
+		_ = json.Unmarshal(args, &in)
+		if name != "mnemo.reset_agent" || in.Confirm != "RESET" || in.AgentID == nil || *in.AgentID != "agent-7" {
+			return nil, &jsonRPCError{Code: -32602, Message: "bad call " + name + " " + string(args)}
+		}
+		return ResetResponse{DeletedMemories: 12, DeletedCheckpoints: 3, DeletedDelegations: 1, Status: "reset"}, nil
+	})
+
+	for _, confirm := range []string{"", "reset", "yes"} {
+		var verr *ValidationError
+		if _, err := c.Reset(context.Background(), confirm); !errors.As(err, &verr) || verr.Field != "confirm" {
+			t.Errorf("Reset(%q) = %v, want ValidationError on confirm", confirm, err)
+		}
+	}
+	if calls != 0 {
+		t.Fatalf("server calls = %d, want 0 for unconfirmed resets", calls)
+	}
+
+	resp, err := c.Reset(WithAgentID(context.Background(), "agent-7"), ResetConfirmation)
+	if err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	want := ResetResponse{DeletedMemories: 12, DeletedCheckpoints: 3, DeletedDelegations: 1, Status: "reset"}
+	if *resp != want {
+		t.Errorf("Reset = %+v, want %+v", *resp, want)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCheckpointAndPrepareContext — verifies the checkpoint runs before the
+// recall and a checkpoint failure skips the recall.
+// ---------------------------------------------------------------------------
+
+func TestCheckpointAndPrepareContext(t *testing.T) {
+	var tools []string
+	failCheckpoint := false
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		tools = append(tools, name)
+		switch name {
+		case "mnemo.checkpoint":
+			if failCheckpoint {
+				return nil, &jsonRPCError{Code: -32000, Message: "storage unavailable"}
+			}
+			return CheckpointResponse{CheckpointID: "cp-1", BranchName: "main", Status: "created"}, nil
+		default:
+			var in RecallInput
+			_ = json.Unmarshal(args, &in)
+			if in.Query != "next step" || in.Limit == nil || *in.Limit != 5 {
+				return nil, &jsonRPCError{Code: -32602, Message: "bad arguments " + string(args)}
+			}
+			return RecallResponse{Memories: []RecalledMemory{{ID: "mem-1"}}, Total: 1}, nil
+		}
+	})
+
+	limit := 5
+	input := CheckpointAndPrepareContextInput{
+		CheckpointInput: CheckpointInput{ThreadID: "thread-1", StateSnapshot: map[string]string{"step": "3"}},
+		RecallQuery:     "next step",
+		RecallLimit:     &limit,
+	}
+	resp, err := c.CheckpointAndPrepareContext(context.Background(), input)
+	if err != nil {
+		t.Fatalf("CheckpointAndPrepareContext: %v", err)
+	}
+	if resp.Checkpoint.CheckpointID != "cp-1" || resp.Recall.Total != 1 {
+		t.Errorf("response = checkpoint %+v, recall %+v", resp.Checkpoint, resp.Recall)
+	}
+	if !reflect.DeepEqual(tools, []string{"mnemo.checkpoint", "mnemo.recall"}) {
+		t.Errorf("tools = %v, want checkpoint then recall", tools)
+	}
+
+	tools = nil
+	failCheckpoint = true
+	if _, err := c.CheckpointAndPrepareContext(context.Background(), input); err == nil {
+		t.Error("CheckpointAndPrepareContext with failing checkpoint = nil error")
+	}
+	if !reflect.DeepEqual(tools, []string{"mnemo.checkpoint"}) {
+		t.Errorf("tools = %v, want only the failed checkpoint", tools)
+	}
+}
@@ -0,0 +1,145 @@
+package mnemo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// multiplexer tracks the calls waiting for a response when
+// ClientOptions.Multiplexing is set.
+type multiplexer struct {
+	mu      sync.Mutex
+	pending map[int]chan rpcResult
+
+	// err is why the reader stopped. Once set, no more calls are accepted.
+	err error
+}
+
+// rpcResult is a response handed to the call waiting for it, or err if
+// the response could not be read or decoded.
+type rpcResult struct {
+	resp jsonRPCResponse
+	err  error
+}
+
+func newMultiplexer() *multiplexer {
+	return &multiplexer{pending: make(map[int]chan rpcResult)}
+}
+
+// register returns the channel that will receive the response to id.
+func (m *multiplexer) register(id int) (chan rpcResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	ch := make(chan rpcResult, 1)
+	m.pending[id] = ch
+	return ch, nil
+}
+
+// forget stops waiting for id, for calls abandoned before their response.
+func (m *multiplexer) forget(id int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pending, id)
+}
+
+// deliver hands res to the call waiting for id. Responses nobody is
+// waiting for are dropped.
+func (m *multiplexer) deliver(id int, res rpcResult) {
+	m.mu.Lock()
+	ch := m.pending[id]
+	delete(m.pending, id)
+	m.mu.Unlock()
+	if ch != nil {
+		ch <- res
+	}
+}
+
+// fail records err and wakes every waiting call by closing its channel.
+func (m *multiplexer) fail(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+	for id, ch := range m.pending {
+		close(ch)
+		delete(m.pending, id)
+	}
+}
+
+// failure returns the error recorded by fail.
+func (m *multiplexer) failure() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
+
+// roundTripMultiplexed sends a tools/call request and waits for the reader
+// to deliver its response. mu is held only while sending, so other calls
+// can be sent while this one waits.
+func (c *Client) roundTripMultiplexed(ctx context.Context, params toolCallParams) (jsonRPCResponse, error) {
+	c.mu.Lock()
+	id := c.allocID()
+	ch, err := c.mux.register(id)
+	if err != nil {
+		c.mu.Unlock()
+		return jsonRPCResponse{}, fmt.Errorf("read: %w", err)
+	}
+	err = c.sendRequest(ctx, jsonRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "tools/call",
+		Params:  params,
+		ID:      intPtr(id),
+	})
+	c.mu.Unlock()
+	if err != nil {
+		c.mux.forget(id)
+		return jsonRPCResponse{}, fmt.Errorf("send: %w", err)
+	}
+
+	select {
+	case res, ok := <-ch:
+		if !ok {
+			return jsonRPCResponse{}, fmt.Errorf("read: %w", c.mux.failure())
+		}
+		if res.err != nil {
+			return jsonRPCResponse{}, fmt.Errorf("unmarshal response: %w", res.err)
+		}
+		return res.resp, nil
+	case <-ctx.Done():
+		c.mux.forget(id)
+		return jsonRPCResponse{}, ctx.Err()
+	}
+}
+
+// readLoop reads responses until the transport fails, delivering each to
+// the call that sent the matching request. A response that fails to decode
+// is delivered as an error to its call if its id can still be read, and is
+// otherwise reported to OnWarning.
+func (c *Client) readLoop() {
+	for {
+		raw, err := c.readRawResponse()
+		if err != nil {
+			c.mux.fail(err)
+			return
+		}
+		var resp jsonRPCResponse
+		if err := c.unmarshal(raw, &resp); err != nil {
+			var head struct {
+				ID *int `json:"id"`
+			}
+			if c.unmarshal(raw, &head) == nil && head.ID != nil {
+				c.mux.deliver(*head.ID, rpcResult{err: err})
+				continue
+			}
+			c.warn(fmt.Errorf("mnemo: unmarshal response: %w", err))
+			continue
+		}
+		if resp.ID == nil {
+			continue
+		}
+		c.mux.deliver(*resp.ID, rpcResult{resp: resp})
+	}
+}
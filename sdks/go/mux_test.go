@@ -0,0 +1,261 @@
+package mnemo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// serveReversed is a fake mnemo server that answers initialize at once but
+// holds tools/call requests until n have arrived, then answers them in
+// reverse order. A client that waits for each response before sending the
+// next request deadlocks against it.
+func serveReversed(conn net.Conn, n int) {
+	defer conn.Close()
+
+	type call struct {
+		ID     int `json:"id"`
+		Params struct {
+			Arguments RecallInput `json:"arguments"`
+		} `json:"params"`
+	}
+
+	write := func(v interface{}) {
+		data, _ := json.Marshal(v)
+		_, _ = conn.Write(append(data, '\n'))
+	}
+
+	var held []call
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var req struct {
+			Method string `json:"method"`
+			ID     *int   `json:"id"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil || req.ID == nil {
+			continue
+		}
+		if req.Method == "initialize" {
+			write(map[string]interface{}{"jsonrpc": "2.0", "id": *req.ID,
+				"result": map[string]interface{}{"protocolVersion": "2024-11-05"}})
+			continue
+		}
+
+		var c call
+		_ = json.Unmarshal(scanner.Bytes(), &c)
+		held = append(held, c)
+		if len(held) < n {
+			continue
+		}
+		for i := len(held) - 1; i >= 0; i-- {
+			text, _ := json.Marshal(RecallResponse{
+				Memories: []RecalledMemory{{ID: held[i].Params.Arguments.Query}},
+				Total:    1,
+			})
+			write(map[string]interface{}{"jsonrpc": "2.0", "id": held[i].ID,
+				"result": map[string]interface{}{
+					"content": []map[string]interface{}{{"type": "text", "text": string(text)}},
+				}})
+		}
+		held = nil
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestMultiplexing — verifies concurrent calls are demultiplexed by ID.
+// ---------------------------------------------------------------------------
+
+func TestMultiplexing(t *testing.T) {
+	const calls = 5
+
+	clientConn, serverConn := net.Pipe()
+	go serveReversed(serverConn, calls)
+
+	c, err := NewClient(ClientOptions{
+		DialFunc: func() (io.ReadWriteCloser, error) { return clientConn, nil },
+	}, WithMultiplexing(true), WithSkipSchemaCheck())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer c.Close()
+
+	queries := []string{"alpha", "bravo", "charlie", "delta", "echo"}
+	results := make([]string, calls)
+	errs := make([]error, calls)
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := c.Recall(RecallInput{Query: queries[i]})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			results[i] = resp.Memories[0].ID
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < calls; i++ {
+		if errs[i] != nil {
+			t.Errorf("Recall %d: %v", i, errs[i])
+			continue
+		}
+		if results[i] != queries[i] {
+			t.Errorf("Recall %d got the response for %q, want %q", i, results[i], queries[i])
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestMultiplexingTransportFailure — verifies waiting calls fail when the
+// connection drops.
+// ---------------------------------------------------------------------------
+
+func TestMultiplexingTransportFailure(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	go serveReversed(serverConn, 2)
+
+	c, err := NewClient(ClientOptions{
+		DialFunc: func() (io.ReadWriteCloser, error) { return clientConn, nil },
+	}, WithMultiplexing(true), WithSkipSchemaCheck())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Recall(RecallInput{Query: "never answered"})
+		done <- err
+	}()
+
+	// The server is holding the call for a second one that never comes.
+	_ = serverConn.Close()
+	if err := <-done; err == nil {
+		t.Error("Recall after the connection dropped: expected error, got nil")
+	}
+	if _, err := c.Recall(RecallInput{Query: "later"}); err == nil {
+		t.Error("Recall on a failed multiplexer: expected error, got nil")
+	}
+	_ = c.Close()
+}
+
+// serveMalformed is a fake mnemo server that answers initialize normally
+// and every tools/call with a result that does not decode.
+func serveMalformed(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var req struct {
+			Method string `json:"method"`
+			ID     *int   `json:"id"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil || req.ID == nil {
+			continue
+		}
+		var result interface{} = "not an object"
+		if req.Method == "initialize" {
+			result = map[string]interface{}{"protocolVersion": "2024-11-05"}
+		}
+		data, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": *req.ID, "result": result})
+		_, _ = conn.Write(append(data, '\n'))
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestMultiplexingMalformedResponse — verifies a response that fails to
+// decode fails its call instead of leaving it waiting.
+// ---------------------------------------------------------------------------
+
+func TestMultiplexingMalformedResponse(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	go serveMalformed(serverConn)
+
+	c, err := NewClient(ClientOptions{
+		DialFunc: func() (io.ReadWriteCloser, error) { return clientConn, nil },
+	}, WithMultiplexing(true), WithSkipSchemaCheck())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = c.RecallContext(ctx, RecallInput{Query: "q"})
+	if err == nil || errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("RecallContext() = %v, want a decode error before the deadline", err)
+	}
+	if !strings.Contains(err.Error(), "unmarshal response") {
+		t.Errorf("RecallContext() = %v, want an unmarshal error", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestMultiplexingSetDebug — verifies SetDebug can be called while the read
+// loop is logging responses. Run with -race.
+// ---------------------------------------------------------------------------
+
+func TestMultiplexingSetDebug(t *testing.T) {
+	const calls = 5
+
+	clientConn, serverConn := net.Pipe()
+	go serveReversed(serverConn, calls)
+
+	c, err := NewClient(ClientOptions{
+		DialFunc: func() (io.ReadWriteCloser, error) { return clientConn, nil },
+	}, WithMultiplexing(true), WithSkipSchemaCheck())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer c.Close()
+
+	var a, b bytes.Buffer
+	c.SetDebug(&a)
+	stop := make(chan struct{})
+	toggled := make(chan struct{})
+	go func() {
+		defer close(toggled)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if i%2 == 0 {
+				c.SetDebug(&b)
+			} else {
+				c.SetDebug(&a)
+			}
+		}
+	}()
+
+	for round := 0; round < 20; round++ {
+		var wg sync.WaitGroup
+		for i := 0; i < calls; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := c.Recall(RecallInput{Query: "q"}); err != nil {
+					t.Errorf("Recall: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+	close(stop)
+	<-toggled
+
+	if a.Len()+b.Len() == 0 {
+		t.Error("no traffic was logged")
+	}
+}
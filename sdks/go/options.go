@@ -0,0 +1,95 @@
+package mnemo
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option adjusts ClientOptions. Options passed to NewClient are applied in
+// order after the ClientOptions struct, so they override its fields.
+type Option func(*ClientOptions)
+
+// WithStdinPipeTimeout bounds how long a single write to the mnemo process
+// stdin may block. If the process stops reading for longer than d, it is
+// killed and the call fails with ErrStdinWriteTimeout. Zero disables the
+// timeout.
+func WithStdinPipeTimeout(d time.Duration) Option {
+	return func(o *ClientOptions) {
+		o.StdinWriteTimeout = d
+	}
+}
+
+// CompressLargeContent sets ClientOptions.CompressContentThreshold, storing
+// RememberInput.Content longer than threshold bytes compressed.
+func CompressLargeContent(threshold int) Option {
+	return func(o *ClientOptions) {
+		o.CompressContentThreshold = threshold
+	}
+}
+
+// WithIdempotencyWindow sets how long the server remembers idempotency keys
+// sent with RememberInput.IdempotencyKey. Retries with the same key inside
+// the window return the original response.
+func WithIdempotencyWindow(d time.Duration) Option {
+	return func(o *ClientOptions) {
+		o.IdempotencyWindow = d
+	}
+}
+
+// WithSkipSchemaCheck stops NewClient from failing when the server reports
+// that its storage schema needs migrating. Use it for tools that run the
+// migration themselves.
+func WithSkipSchemaCheck() Option {
+	return func(o *ClientOptions) {
+		o.SkipSchemaCheck = true
+	}
+}
+
+// WithToolAlias sends calls to the SDK tool sdkName under serverName instead,
+// for servers that rename their tools. It may be repeated for several tools.
+func WithToolAlias(sdkName, serverName string) Option {
+	return func(o *ClientOptions) {
+		aliases := make(map[string]string, len(o.ToolAliases)+1)
+		for k, v := range o.ToolAliases {
+			aliases[k] = v
+		}
+		aliases[sdkName] = serverName
+		o.ToolAliases = aliases
+	}
+}
+
+// WithScopeHierarchy sets ClientOptions.ScopeHierarchy to a copy of h.
+func WithScopeHierarchy(h map[Scope][]Scope) Option {
+	return func(o *ClientOptions) {
+		hierarchy := make(map[Scope][]Scope, len(h))
+		for k, v := range h {
+			hierarchy[k] = append([]Scope(nil), v...)
+		}
+		o.ScopeHierarchy = hierarchy
+	}
+}
+
+// WithHTTPTransport connects to a mnemo server listening for JSON-RPC over
+// HTTP at url instead of spawning a child process. A non-empty token is sent
+// as a bearer token.
+func WithHTTPTransport(url, token string) Option {
+	return func(o *ClientOptions) {
+		o.HTTPURL = url
+		o.HTTPToken = token
+	}
+}
+
+// WithHTTPClient sets ClientOptions.HTTPClient, the client used for
+// WithHTTPTransport requests.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *ClientOptions) {
+		o.HTTPClient = client
+	}
+}
+
+// WithMultiplexing enables or disables ClientOptions.Multiplexing.
+func WithMultiplexing(enabled bool) Option {
+	return func(o *ClientOptions) {
+		o.Multiplexing = enabled
+	}
+}
@@ -0,0 +1,143 @@
+package mnemo
+
+import (
+	"errors"
+	"sync"
+)
+
+// PoolStats is a snapshot of a ClientPool's clients.
+type PoolStats struct {
+	// Active is the number of clients handed out by Get and not yet returned.
+	Active int
+
+	// Idle is the number of started clients waiting in the pool.
+	Idle int
+
+	// Failed is the number of client starts by Get that failed.
+	Failed int
+}
+
+// ClientPool keeps started clients ready so callers do not pay the process
+// spawn and handshake on the critical path. NewClientPool starts
+// ClientOptions.WarmPoolSize clients up front; Get hands out an idle client
+// or starts a new one, and Put returns it.
+//
+// ClientPool is safe for concurrent use.
+type ClientPool struct {
+	opts ClientOptions
+
+	mu     sync.Mutex
+	idle   []*Client
+	active int
+	failed int
+	closed bool
+}
+
+// NewClientPool returns a pool whose clients are created from opts. It starts
+// opts.WarmPoolSize clients concurrently and waits for all of them; if any
+// fails, the others are closed and the error is returned.
+func NewClientPool(opts ClientOptions, options ...Option) (*ClientPool, error) {
+	for _, o := range options {
+		o(&opts)
+	}
+	p := &ClientPool{opts: opts}
+
+	n := opts.WarmPoolSize
+	if n <= 0 {
+		return p, nil
+	}
+
+	clients := make([]*Client, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], errs[i] = NewClient(opts)
+		}(i)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		for _, c := range clients {
+			if c != nil {
+				_ = c.Close()
+			}
+		}
+		return nil, err
+	}
+	p.idle = clients
+	return p, nil
+}
+
+// Get returns an idle client, or starts a new one if none is idle. The
+// caller must return it with Put when finished.
+func (p *ClientPool) Get() (*Client, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, ErrClientClosed
+	}
+	if n := len(p.idle); n > 0 {
+		c := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.active++
+		p.mu.Unlock()
+		return c, nil
+	}
+	p.mu.Unlock()
+
+	c, err := NewClient(p.opts)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		p.failed++
+		return nil, err
+	}
+	if p.closed {
+		_ = c.Close()
+		return nil, ErrClientClosed
+	}
+	p.active++
+	return c, nil
+}
+
+// Put returns a client obtained from Get to the pool. Clients returned after
+// Close are closed instead.
+func (p *ClientPool) Put(c *Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active--
+	if p.closed {
+		_ = c.Close()
+		return
+	}
+	p.idle = append(p.idle, c)
+}
+
+// Stats returns the current client counts.
+func (p *ClientPool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PoolStats{Active: p.active, Idle: len(p.idle), Failed: p.failed}
+}
+
+// Close closes every idle client and makes Get fail with ErrClientClosed.
+// Clients still handed out are closed when they are Put back.
+func (p *ClientPool) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+
+	var errs []error
+	for _, c := range idle {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,69 @@
+package mnemo
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestClientPoolWarm — verifies warm clients are idle after construction.
+// ---------------------------------------------------------------------------
+
+func TestClientPoolWarm(t *testing.T) {
+	t.Setenv(fakeServerEnv, "1")
+
+	p, err := NewClientPool(ClientOptions{Command: os.Args[0], WarmPoolSize: 3})
+	if err != nil {
+		t.Fatalf("NewClientPool: %v", err)
+	}
+	defer p.Close()
+
+	if got, want := p.Stats(), (PoolStats{Idle: 3}); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+
+	c, err := p.Get()
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if _, err := c.Recall(RecallInput{Query: "pooled"}); err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if got, want := p.Stats(), (PoolStats{Active: 1, Idle: 2}); got != want {
+		t.Errorf("Stats() after Get = %+v, want %+v", got, want)
+	}
+
+	p.Put(c)
+	if got, want := p.Stats(), (PoolStats{Idle: 3}); got != want {
+		t.Errorf("Stats() after Put = %+v, want %+v", got, want)
+	}
+
+	if err := p.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	if _, err := p.Get(); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Get after Close = %v, want ErrClientClosed", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestClientPoolWarmFailure — verifies a failed warm start fails the pool.
+// ---------------------------------------------------------------------------
+
+func TestClientPoolWarmFailure(t *testing.T) {
+	if _, err := NewClientPool(ClientOptions{Command: "/nonexistent/mnemo", WarmPoolSize: 2}); err == nil {
+		t.Error("NewClientPool with a missing binary: expected error, got nil")
+	}
+
+	p, err := NewClientPool(ClientOptions{Command: "/nonexistent/mnemo"})
+	if err != nil {
+		t.Fatalf("NewClientPool without warm clients: %v", err)
+	}
+	if _, err := p.Get(); err == nil {
+		t.Error("Get with a missing binary: expected error, got nil")
+	}
+	if got, want := p.Stats(), (PoolStats{Failed: 1}); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
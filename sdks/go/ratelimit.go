@@ -0,0 +1,139 @@
+package mnemo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimitOptions configures a RateLimitedClient.
+type RateLimitOptions struct {
+	// RequestsPerSecond is the sustained call rate. Must be positive.
+	RequestsPerSecond float64
+
+	// Burst is the number of calls allowed back to back before the rate
+	// applies. Values below 1 are treated as 1.
+	Burst int
+}
+
+// RateLimitedClient wraps a MemoryClient with a token-bucket rate limiter.
+// Each call waits for a token before it is forwarded; the wait is abandoned
+// with ctx's error if ctx is done first.
+//
+// RateLimitedClient is safe for concurrent use.
+type RateLimitedClient struct {
+	inner MemoryClient
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimitedClient wraps inner with a limiter that starts with a full
+// bucket of Burst tokens.
+func NewRateLimitedClient(inner MemoryClient, opts RateLimitOptions) (*RateLimitedClient, error) {
+	if opts.RequestsPerSecond <= 0 {
+		return nil, fmt.Errorf("mnemo: RequestsPerSecond must be positive, got %v", opts.RequestsPerSecond)
+	}
+	if opts.Burst < 1 {
+		opts.Burst = 1
+	}
+	return &RateLimitedClient{
+		inner:  inner,
+		rate:   opts.RequestsPerSecond,
+		burst:  float64(opts.Burst),
+		tokens: float64(opts.Burst),
+		last:   time.Now(),
+	}, nil
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (rl *RateLimitedClient) Wait(ctx context.Context) error {
+	for {
+		rl.mu.Lock()
+		now := time.Now()
+		rl.tokens += now.Sub(rl.last).Seconds() * rl.rate
+		if rl.tokens > rl.burst {
+			rl.tokens = rl.burst
+		}
+		rl.last = now
+
+		if rl.tokens >= 1 {
+			rl.tokens--
+			rl.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - rl.tokens) / rl.rate * float64(time.Second))
+		rl.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("mnemo: rate limit wait: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// RememberContext waits for a token, then calls the wrapped client.
+func (rl *RateLimitedClient) RememberContext(ctx context.Context, input RememberInput) (*RememberResponse, error) {
+	return limited(ctx, rl, func() (*RememberResponse, error) { return rl.inner.RememberContext(ctx, input) })
+}
+
+// RecallContext waits for a token, then calls the wrapped client.
+func (rl *RateLimitedClient) RecallContext(ctx context.Context, input RecallInput) (*RecallResponse, error) {
+	return limited(ctx, rl, func() (*RecallResponse, error) { return rl.inner.RecallContext(ctx, input) })
+}
+
+// ForgetContext waits for a token, then calls the wrapped client.
+func (rl *RateLimitedClient) ForgetContext(ctx context.Context, input ForgetInput) (*ForgetResponse, error) {
+	return limited(ctx, rl, func() (*ForgetResponse, error) { return rl.inner.ForgetContext(ctx, input) })
+}
+
+// ShareContext waits for a token, then calls the wrapped client.
+func (rl *RateLimitedClient) ShareContext(ctx context.Context, input ShareInput) (*ShareResponse, error) {
+	return limited(ctx, rl, func() (*ShareResponse, error) { return rl.inner.ShareContext(ctx, input) })
+}
+
+// CheckpointContext waits for a token, then calls the wrapped client.
+func (rl *RateLimitedClient) CheckpointContext(ctx context.Context, input CheckpointInput) (*CheckpointResponse, error) {
+	return limited(ctx, rl, func() (*CheckpointResponse, error) { return rl.inner.CheckpointContext(ctx, input) })
+}
+
+// BranchContext waits for a token, then calls the wrapped client.
+func (rl *RateLimitedClient) BranchContext(ctx context.Context, input BranchInput) (*BranchResponse, error) {
+	return limited(ctx, rl, func() (*BranchResponse, error) { return rl.inner.BranchContext(ctx, input) })
+}
+
+// MergeContext waits for a token, then calls the wrapped client.
+func (rl *RateLimitedClient) MergeContext(ctx context.Context, input MergeInput) (*MergeResponse, error) {
+	return limited(ctx, rl, func() (*MergeResponse, error) { return rl.inner.MergeContext(ctx, input) })
+}
+
+// ReplayContext waits for a token, then calls the wrapped client.
+func (rl *RateLimitedClient) ReplayContext(ctx context.Context, input ReplayInput) (*ReplayResponse, error) {
+	return limited(ctx, rl, func() (*ReplayResponse, error) { return rl.inner.ReplayContext(ctx, input) })
+}
+
+// VerifyContext waits for a token, then calls the wrapped client.
+func (rl *RateLimitedClient) VerifyContext(ctx context.Context, input VerifyInput) (*VerifyResponse, error) {
+	return limited(ctx, rl, func() (*VerifyResponse, error) { return rl.inner.VerifyContext(ctx, input) })
+}
+
+// DelegateContext waits for a token, then calls the wrapped client.
+func (rl *RateLimitedClient) DelegateContext(ctx context.Context, input DelegateInput) (*DelegateResponse, error) {
+	return limited(ctx, rl, func() (*DelegateResponse, error) { return rl.inner.DelegateContext(ctx, input) })
+}
+
+// limited runs call once rl grants a token.
+func limited[T any](ctx context.Context, rl *RateLimitedClient, call func() (T, error)) (T, error) {
+	if err := rl.Wait(ctx); err != nil {
+		var zero T
+		return zero, err
+	}
+	return call()
+}
@@ -0,0 +1,81 @@
+package mnemo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var _ MemoryClient = (*RateLimitedClient)(nil)
+
+// ---------------------------------------------------------------------------
+// TestRateLimitBurstThenBlock — verifies burst capacity and refill waiting.
+// ---------------------------------------------------------------------------
+
+func TestRateLimitBurstThenBlock(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeMemoryClient{}
+	rl, err := NewRateLimitedClient(fake, RateLimitOptions{RequestsPerSecond: 10, Burst: 3})
+	if err != nil {
+		t.Fatalf("NewRateLimitedClient: %v", err)
+	}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := rl.RecallContext(ctx, RecallInput{Query: "q"}); err != nil {
+			t.Fatalf("burst call %d: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("burst of 3 took %v, want near-instant", elapsed)
+	}
+
+	start = time.Now()
+	if _, err := rl.RecallContext(ctx, RecallInput{Query: "q"}); err != nil {
+		t.Fatalf("post-burst call: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("post-burst call took %v, want it to wait for a refill (~100ms)", elapsed)
+	}
+	if got := fake.count("recall"); got != 4 {
+		t.Errorf("inner recall calls = %d, want 4", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRateLimitContextCancel — verifies waiting respects ctx cancellation.
+// ---------------------------------------------------------------------------
+
+func TestRateLimitContextCancel(t *testing.T) {
+	fake := &fakeMemoryClient{}
+	rl, err := NewRateLimitedClient(fake, RateLimitOptions{RequestsPerSecond: 0.1, Burst: 1})
+	if err != nil {
+		t.Fatalf("NewRateLimitedClient: %v", err)
+	}
+
+	if _, err := rl.RememberContext(context.Background(), RememberInput{Content: "a"}); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = rl.RememberContext(ctx, RememberInput{Content: "b"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("RememberContext() = %v, want context.DeadlineExceeded", err)
+	}
+	if got := fake.count("remember"); got != 1 {
+		t.Errorf("inner remember calls = %d, want 1", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRateLimitInvalidRate — verifies a non-positive rate is rejected.
+// ---------------------------------------------------------------------------
+
+func TestRateLimitInvalidRate(t *testing.T) {
+	if _, err := NewRateLimitedClient(&fakeMemoryClient{}, RateLimitOptions{}); err == nil {
+		t.Fatal("expected error for zero RequestsPerSecond, got nil")
+	}
+}
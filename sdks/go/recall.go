@@ -0,0 +1,216 @@
+package mnemo
+
+import (
+	"sort"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Client-side recall post-processing
+// ---------------------------------------------------------------------------
+
+// applyRecallFilters runs the client-side post-processing steps requested by
+// input over resp. It mutates resp in place.
+func applyRecallFilters(input RecallInput, resp *RecallResponse) {
+	if len(input.ExcludeIDs) > 0 {
+		kept := excludeMemories(resp.Memories, input.ExcludeIDs)
+		resp.Total -= len(resp.Memories) - len(kept)
+		resp.Memories = kept
+	}
+	if len(input.ExcludeMemoryTypes) > 0 {
+		kept := excludeMemoryTypes(resp.Memories, input.ExcludeMemoryTypes)
+		resp.Total -= len(resp.Memories) - len(kept)
+		resp.Memories = kept
+	}
+	if input.MinConfidence != nil {
+		kept := filterConfidence(resp.Memories, *input.MinConfidence)
+		resp.Total -= len(resp.Memories) - len(kept)
+		resp.Memories = kept
+	}
+	if input.DeduplicateThreshold != nil {
+		kept := dedupeMemories(resp.Memories, *input.DeduplicateThreshold)
+		resp.Total -= len(resp.Memories) - len(kept)
+		resp.Memories = kept
+	}
+	if input.SortBy != nil {
+		desc := input.SortOrder == nil || *input.SortOrder != SortOrderAsc
+		sortMemories(resp.Memories, *input.SortBy, desc)
+	}
+}
+
+// expandScope returns scope followed by the scopes hierarchy lists for it,
+// without duplicates, or nil if hierarchy has no entry for scope.
+func expandScope(hierarchy map[Scope][]Scope, scope Scope) []string {
+	broader, ok := hierarchy[scope]
+	if !ok {
+		return nil
+	}
+	out := []string{string(scope)}
+	seen := map[Scope]struct{}{scope: {}}
+	for _, s := range broader {
+		if _, dup := seen[s]; dup {
+			continue
+		}
+		seen[s] = struct{}{}
+		out = append(out, string(s))
+	}
+	return out
+}
+
+// sortMemories orders memories in place by key. Ties keep their relative
+// order.
+func sortMemories(memories []RecalledMemory, key string, desc bool) {
+	var less func(a, b RecalledMemory) bool
+	switch key {
+	case SortByScore:
+		less = func(a, b RecalledMemory) bool { return a.Score < b.Score }
+	case SortByImportance:
+		less = func(a, b RecalledMemory) bool { return a.Importance < b.Importance }
+	case SortByCreatedAt:
+		less = func(a, b RecalledMemory) bool { return timestampLess(a.CreatedAt, b.CreatedAt) }
+	case SortByUpdatedAt:
+		less = func(a, b RecalledMemory) bool { return timestampLess(a.UpdatedAt, b.UpdatedAt) }
+	default:
+		return
+	}
+
+	sort.SliceStable(memories, func(i, j int) bool {
+		if desc {
+			return less(memories[j], memories[i])
+		}
+		return less(memories[i], memories[j])
+	})
+}
+
+// timestampLess compares two RFC 3339 timestamps chronologically, falling
+// back to string order if either fails to parse.
+func timestampLess(a, b string) bool {
+	ta, errA := time.Parse(time.RFC3339Nano, a)
+	tb, errB := time.Parse(time.RFC3339Nano, b)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return ta.Before(tb)
+}
+
+// excludeMemories drops every memory whose ID appears in ids.
+func excludeMemories(memories []RecalledMemory, ids []string) []RecalledMemory {
+	skip := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		skip[id] = struct{}{}
+	}
+
+	out := make([]RecalledMemory, 0, len(memories))
+	for _, m := range memories {
+		if _, ok := skip[m.ID]; !ok {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// excludeMemoryTypes drops every memory whose MemoryType appears in types.
+func excludeMemoryTypes(memories []RecalledMemory, types []string) []RecalledMemory {
+	skip := make(map[string]struct{}, len(types))
+	for _, t := range types {
+		skip[t] = struct{}{}
+	}
+
+	out := make([]RecalledMemory, 0, len(memories))
+	for _, m := range memories {
+		if _, ok := skip[m.MemoryType]; !ok {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// filterConfidence drops memories whose confidence score is below threshold.
+// Memories without a confidence score are kept.
+func filterConfidence(memories []RecalledMemory, threshold float32) []RecalledMemory {
+	out := make([]RecalledMemory, 0, len(memories))
+	for _, m := range memories {
+		if m.ConfidenceScore == nil || *m.ConfidenceScore >= threshold {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// dedupeMemories drops memories whose content is at least threshold similar
+// to a higher-scoring memory. Surviving memories keep their original order.
+func dedupeMemories(memories []RecalledMemory, threshold float32) []RecalledMemory {
+	if len(memories) < 2 {
+		return memories
+	}
+
+	// Visit memories from highest to lowest score so the best-scoring copy of
+	// each duplicate group is the one that survives.
+	order := make([]int, len(memories))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return memories[order[a]].Score > memories[order[b]].Score
+	})
+
+	keep := make([]bool, len(memories))
+	var kept []int
+	for _, i := range order {
+		duplicate := false
+		for _, k := range kept {
+			if contentSimilarity(memories[i].Content, memories[k].Content) >= threshold {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			keep[i] = true
+			kept = append(kept, i)
+		}
+	}
+
+	out := make([]RecalledMemory, 0, len(kept))
+	for i, m := range memories {
+		if keep[i] {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// contentSimilarity returns 1 minus the Levenshtein distance between a and b
+// normalized by the longer length, so identical strings score 1.0 and
+// completely different strings score 0.0.
+func contentSimilarity(a, b string) float32 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float32(levenshtein(ra, rb))/float32(longest)
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,919 @@
+package mnemo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// TestRecallDeduplicate — verifies near-duplicate results are collapsed.
+// ---------------------------------------------------------------------------
+
+func TestRecallDeduplicate(t *testing.T) {
+	raw := `{
+		"memories": [
+			{"id": "m1", "content": "User prefers dark mode", "score": 0.71},
+			{"id": "m2", "content": "User prefers dark mode", "score": 0.93},
+			{"id": "m3", "content": "Deploys happen on Fridays", "score": 0.50}
+		],
+		"total": 3
+	}`
+
+	var resp RecallResponse
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		t.Fatalf("Unmarshal RecallResponse: %v", err)
+	}
+
+	threshold := float32(0.9)
+	applyRecallFilters(RecallInput{Query: "prefs", DeduplicateThreshold: &threshold}, &resp)
+
+	if len(resp.Memories) != 2 {
+		t.Fatalf("Memories length = %d, want 2", len(resp.Memories))
+	}
+	if resp.Memories[0].ID != "m2" {
+		t.Errorf("Memories[0].ID = %q, want %q (highest-scoring duplicate)", resp.Memories[0].ID, "m2")
+	}
+	if resp.Memories[1].ID != "m3" {
+		t.Errorf("Memories[1].ID = %q, want %q", resp.Memories[1].ID, "m3")
+	}
+	if resp.Total != 2 {
+		t.Errorf("Total = %d, want 2", resp.Total)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallDeduplicateDisabled — verifies nil threshold is a no-op.
+// ---------------------------------------------------------------------------
+
+func TestRecallDeduplicateDisabled(t *testing.T) {
+	resp := RecallResponse{
+		Memories: []RecalledMemory{
+			{ID: "m1", Content: "same"},
+			{ID: "m2", Content: "same"},
+		},
+		Total: 2,
+	}
+
+	applyRecallFilters(RecallInput{Query: "q"}, &resp)
+
+	if len(resp.Memories) != 2 {
+		t.Errorf("Memories length = %d, want 2", len(resp.Memories))
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestContentSimilarity — verifies the normalized Levenshtein score.
+// ---------------------------------------------------------------------------
+
+func TestContentSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float32
+	}{
+		{"", "", 1},
+		{"abc", "abc", 1},
+		{"abc", "xyz", 0},
+		{"kitten", "sitting", 1 - float32(3)/float32(7)},
+	}
+
+	for _, tt := range tests {
+		if got := contentSimilarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("contentSimilarity(%q, %q) = %f, want %f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallIncludeMetadata — verifies the metadata opt-in flag and field.
+// ---------------------------------------------------------------------------
+
+func TestRecallIncludeMetadata(t *testing.T) {
+	data, err := json.Marshal(RecallInput{Query: "q"})
+	if err != nil {
+		t.Fatalf("Marshal RecallInput: %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+	if _, ok := raw["include_metadata"]; ok {
+		t.Error("expected 'include_metadata' to be omitted when false")
+	}
+
+	data, err = json.Marshal(RecallInput{Query: "q", IncludeMetadata: true})
+	if err != nil {
+		t.Fatalf("Marshal RecallInput: %v", err)
+	}
+	raw = nil
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+	if raw["include_metadata"] != true {
+		t.Errorf("include_metadata = %v, want true", raw["include_metadata"])
+	}
+
+	var without RecallResponse
+	if err := json.Unmarshal([]byte(`{"memories": [{"id": "m1"}], "total": 1}`), &without); err != nil {
+		t.Fatalf("Unmarshal RecallResponse: %v", err)
+	}
+	if without.Memories[0].Metadata != nil {
+		t.Errorf("Metadata = %v, want nil", without.Memories[0].Metadata)
+	}
+
+	var with RecallResponse
+	if err := json.Unmarshal([]byte(`{"memories": [{"id": "m1", "metadata": {"source": "docs"}}], "total": 1}`), &with); err != nil {
+		t.Fatalf("Unmarshal RecallResponse: %v", err)
+	}
+	if with.Memories[0].Metadata["source"] != "docs" {
+		t.Errorf("Metadata[source] = %v, want %q", with.Memories[0].Metadata["source"], "docs")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallExcludeIDs — verifies excluded IDs are stripped client-side.
+// ---------------------------------------------------------------------------
+
+func TestRecallExcludeIDs(t *testing.T) {
+	input := RecallInput{Query: "q", ExcludeIDs: []string{"m2"}}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal RecallInput: %v", err)
+	}
+	var decoded RecallInput
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal RecallInput: %v", err)
+	}
+	if len(decoded.ExcludeIDs) != 1 || decoded.ExcludeIDs[0] != "m2" {
+		t.Errorf("ExcludeIDs = %v, want [m2]", decoded.ExcludeIDs)
+	}
+
+	resp := RecallResponse{
+		Memories: []RecalledMemory{
+			{ID: "m1", Score: 0.6},
+			{ID: "m2", Score: 0.9},
+			{ID: "m3", Score: 0.4},
+		},
+		Total: 3,
+	}
+
+	applyRecallFilters(input, &resp)
+
+	if len(resp.Memories) != 2 {
+		t.Fatalf("Memories length = %d, want 2", len(resp.Memories))
+	}
+	for _, m := range resp.Memories {
+		if m.ID == "m2" {
+			t.Error("excluded memory m2 should have been stripped")
+		}
+	}
+	if resp.Total != 2 {
+		t.Errorf("Total = %d, want 2", resp.Total)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallDecaySimulatedAt — verifies RFC 3339 encoding and validation.
+// ---------------------------------------------------------------------------
+
+func TestRecallDecaySimulatedAt(t *testing.T) {
+	at := time.Date(2024, 6, 2, 12, 0, 0, 0, time.UTC)
+	input := RecallInput{Query: "q", DecaySimulatedAt: &at}
+
+	if err := input.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal RecallInput: %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+	if raw["decay_simulated_at"] != "2024-06-02T12:00:00Z" {
+		t.Errorf("decay_simulated_at = %v, want %q", raw["decay_simulated_at"], "2024-06-02T12:00:00Z")
+	}
+
+	var zero time.Time
+	err = RecallInput{Query: "q", DecaySimulatedAt: &zero}.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() = %v, want *ValidationError", err)
+	}
+	if verr.Field != "decay_simulated_at" {
+		t.Errorf("Field = %q, want %q", verr.Field, "decay_simulated_at")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallExcludeMemoryTypes — verifies excluded types are filtered out.
+// ---------------------------------------------------------------------------
+
+func TestRecallExcludeMemoryTypes(t *testing.T) {
+	input := RecallInput{
+		Query:              "q",
+		MemoryTypes:        []string{"semantic", "procedural"},
+		MemoryTypeAnd:      true,
+		ExcludeMemoryTypes: []string{"working"},
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal RecallInput: %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+	if raw["memory_type_and"] != true {
+		t.Errorf("memory_type_and = %v, want true", raw["memory_type_and"])
+	}
+	if excluded, ok := raw["exclude_memory_types"].([]interface{}); !ok || len(excluded) != 1 {
+		t.Errorf("exclude_memory_types = %v, want [working]", raw["exclude_memory_types"])
+	}
+
+	resp := RecallResponse{
+		Memories: []RecalledMemory{
+			{ID: "m1", MemoryType: "semantic"},
+			{ID: "m2", MemoryType: "working"},
+			{ID: "m3", MemoryType: "episodic"},
+		},
+		Total: 3,
+	}
+
+	applyRecallFilters(input, &resp)
+
+	if len(resp.Memories) != 2 {
+		t.Fatalf("Memories length = %d, want 2", len(resp.Memories))
+	}
+	for _, m := range resp.Memories {
+		if m.MemoryType == "working" {
+			t.Errorf("memory %q of excluded type %q should have been filtered", m.ID, m.MemoryType)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestTemporalRangeTimeJSON — verifies typed time bounds encode as RFC 3339.
+// ---------------------------------------------------------------------------
+
+func TestTemporalRangeTimeJSON(t *testing.T) {
+	after := time.Date(2024, 1, 1, 8, 30, 0, 0, time.UTC)
+	before := "2024-02-01T00:00:00Z"
+	ignored := "1999-01-01T00:00:00Z"
+
+	r := TemporalRange{After: &ignored, AfterTime: &after, Before: &before}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("Marshal TemporalRange: %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+
+	got, ok := raw["after"].(string)
+	if !ok {
+		t.Fatalf("after = %v, want string", raw["after"])
+	}
+	parsed, err := time.Parse(time.RFC3339, got)
+	if err != nil {
+		t.Fatalf("after %q is not RFC 3339: %v", got, err)
+	}
+	if !parsed.Equal(after) {
+		t.Errorf("after = %v, want %v", parsed, after)
+	}
+	if raw["before"] != before {
+		t.Errorf("before = %v, want %q", raw["before"], before)
+	}
+
+	var decoded TemporalRange
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal TemporalRange: %v", err)
+	}
+	if decoded.After == nil || *decoded.After != got {
+		t.Errorf("After = %v, want %q", decoded.After, got)
+	}
+	if decoded.AfterTime == nil || !decoded.AfterTime.Equal(after) {
+		t.Errorf("AfterTime = %v, want %v", decoded.AfterTime, after)
+	}
+	if decoded.BeforeTime == nil || decoded.BeforeTime.Format(time.RFC3339) != before {
+		t.Errorf("BeforeTime = %v, want %q", decoded.BeforeTime, before)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallExplainJSON — verifies the explain flag and score breakdown.
+// ---------------------------------------------------------------------------
+
+func TestRecallExplainJSON(t *testing.T) {
+	data, err := json.Marshal(RecallInput{Query: "q", Explain: true})
+	if err != nil {
+		t.Fatalf("Marshal RecallInput: %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+	if raw["explain"] != true {
+		t.Errorf("explain = %v, want true", raw["explain"])
+	}
+
+	explained := `{
+		"memories": [{
+			"id": "m1",
+			"score": 0.63,
+			"score_breakdown": {"vector_score": 0.7, "bm25_score": 0.5, "importance_boost": 0.1, "decay_factor": 0.9}
+		}],
+		"total": 1
+	}`
+	var resp RecallResponse
+	if err := json.Unmarshal([]byte(explained), &resp); err != nil {
+		t.Fatalf("Unmarshal RecallResponse: %v", err)
+	}
+	breakdown := resp.Memories[0].ScoreBreakdown
+	if len(breakdown) != 4 {
+		t.Fatalf("ScoreBreakdown length = %d, want 4", len(breakdown))
+	}
+	if breakdown["vector_score"] != 0.7 {
+		t.Errorf("ScoreBreakdown[vector_score] = %f, want 0.7", breakdown["vector_score"])
+	}
+	if breakdown["decay_factor"] != 0.9 {
+		t.Errorf("ScoreBreakdown[decay_factor] = %f, want 0.9", breakdown["decay_factor"])
+	}
+
+	var plain RecallResponse
+	if err := json.Unmarshal([]byte(`{"memories": [{"id": "m1", "score": 0.63}], "total": 1}`), &plain); err != nil {
+		t.Fatalf("Unmarshal RecallResponse: %v", err)
+	}
+	if plain.Memories[0].ScoreBreakdown != nil {
+		t.Errorf("ScoreBreakdown = %v, want nil without explain", plain.Memories[0].ScoreBreakdown)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallRerankerJSON — verifies reranker selection and score decoding.
+// ---------------------------------------------------------------------------
+
+func TestRecallRerankerJSON(t *testing.T) {
+	model := RerankerModelCrossEncoder
+	data, err := json.Marshal(RecallInput{Query: "q", RerankerModel: &model})
+	if err != nil {
+		t.Fatalf("Marshal RecallInput: %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+	if raw["reranker_model"] != "cross_encoder" {
+		t.Errorf("reranker_model = %v, want %q", raw["reranker_model"], "cross_encoder")
+	}
+
+	data, err = json.Marshal(RecallInput{Query: "q"})
+	if err != nil {
+		t.Fatalf("Marshal RecallInput: %v", err)
+	}
+	raw = nil
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+	if _, ok := raw["reranker_model"]; ok {
+		t.Error("expected 'reranker_model' to be omitted when nil")
+	}
+
+	var resp RecallResponse
+	raw2 := `{"memories": [{"id": "m1", "score": 0.4, "reranker_score": 0.88}, {"id": "m2", "score": 0.3}], "total": 2}`
+	if err := json.Unmarshal([]byte(raw2), &resp); err != nil {
+		t.Fatalf("Unmarshal RecallResponse: %v", err)
+	}
+	if resp.Memories[0].RerankerScore == nil || *resp.Memories[0].RerankerScore != 0.88 {
+		t.Errorf("RerankerScore = %v, want 0.88", resp.Memories[0].RerankerScore)
+	}
+	if resp.Memories[1].RerankerScore != nil {
+		t.Errorf("RerankerScore = %v, want nil", *resp.Memories[1].RerankerScore)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallHybridTuning — verifies hybrid blend fields and validation.
+// ---------------------------------------------------------------------------
+
+func TestRecallHybridTuning(t *testing.T) {
+	alpha := float32(0.3)
+	strategy := HybridStrategyRRF
+	input := RecallInput{Query: "q", HybridAlpha: &alpha, HybridStrategy: &strategy}
+
+	if err := input.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal RecallInput: %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+	if _, ok := raw["hybrid_alpha"]; !ok {
+		t.Error("expected 'hybrid_alpha' in JSON")
+	}
+	if raw["hybrid_strategy"] != "rrf" {
+		t.Errorf("hybrid_strategy = %v, want %q", raw["hybrid_strategy"], "rrf")
+	}
+
+	data, err = json.Marshal(RecallInput{Query: "q"})
+	if err != nil {
+		t.Fatalf("Marshal RecallInput: %v", err)
+	}
+	raw = nil
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+	for _, key := range []string{"hybrid_alpha", "hybrid_strategy"} {
+		if _, ok := raw[key]; ok {
+			t.Errorf("expected key %q to be omitted, but it was present", key)
+		}
+	}
+
+	for _, bad := range []float32{-0.1, 1.5} {
+		bad := bad
+		err := RecallInput{Query: "q", HybridAlpha: &bad}.Validate()
+		var verr *ValidationError
+		if !errors.As(err, &verr) || verr.Field != "hybrid_alpha" {
+			t.Errorf("Validate() with HybridAlpha %v = %v, want hybrid_alpha ValidationError", bad, err)
+		}
+	}
+
+	unknown := "weighted"
+	if err := (RecallInput{Query: "q", HybridStrategy: &unknown}).Validate(); err == nil {
+		t.Error("Validate() with unknown HybridStrategy: expected error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallSortBy — verifies client-side ordering for each sort key.
+// ---------------------------------------------------------------------------
+
+func TestRecallSortBy(t *testing.T) {
+	fixture := []RecalledMemory{
+		{ID: "a", Score: 0.9, Importance: 0.2, CreatedAt: "2024-01-03T00:00:00Z", UpdatedAt: "2024-02-01T00:00:00Z"},
+		{ID: "b", Score: 0.5, Importance: 0.9, CreatedAt: "2024-01-01T00:00:00Z", UpdatedAt: "2024-02-03T00:00:00Z"},
+		{ID: "c", Score: 0.7, Importance: 0.5, CreatedAt: "2024-01-02T05:00:00+05:00", UpdatedAt: "2024-02-02T00:00:00Z"},
+	}
+
+	tests := []struct {
+		key   string
+		order string
+		want  string
+	}{
+		{SortByScore, SortOrderDesc, "acb"},
+		{SortByScore, SortOrderAsc, "bca"},
+		{SortByImportance, SortOrderDesc, "bca"},
+		{SortByCreatedAt, SortOrderDesc, "acb"},
+		{SortByCreatedAt, SortOrderAsc, "bca"},
+		{SortByUpdatedAt, "", "bca"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"_"+tt.order, func(t *testing.T) {
+			key := tt.key
+			input := RecallInput{Query: "q", SortBy: &key}
+			if tt.order != "" {
+				order := tt.order
+				input.SortOrder = &order
+			}
+			if err := input.Validate(); err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+
+			resp := RecallResponse{Memories: append([]RecalledMemory(nil), fixture...), Total: 3}
+			applyRecallFilters(input, &resp)
+
+			got := ""
+			for _, m := range resp.Memories {
+				got += m.ID
+			}
+			if got != tt.want {
+				t.Errorf("order = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	bad := "relevance"
+	if err := (RecallInput{Query: "q", SortBy: &bad}).Validate(); err == nil {
+		t.Error("Validate() with unknown SortBy: expected error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallInputBoostJSON — verifies Boost marshaling and validation.
+// ---------------------------------------------------------------------------
+
+func TestRecallInputBoostJSON(t *testing.T) {
+	input := RecallInput{Query: "incidents", Boost: map[string]float32{"critical": 0.2, "stale": -0.5}}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal RecallInput: %v", err)
+	}
+	var decoded RecallInput
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal RecallInput: %v", err)
+	}
+	if decoded.Boost["critical"] != 0.2 || decoded.Boost["stale"] != -0.5 {
+		t.Errorf("Boost = %v, want critical=0.2 stale=-0.5", decoded.Boost)
+	}
+
+	data, _ = json.Marshal(RecallInput{Query: "q"})
+	var raw map[string]interface{}
+	_ = json.Unmarshal(data, &raw)
+	if _, ok := raw["boost"]; ok {
+		t.Error("boost should be omitted when nil")
+	}
+
+	if err := input.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	var verr *ValidationError
+	for _, b := range []float32{-1.5, 1.01} {
+		bad := RecallInput{Query: "q", Boost: map[string]float32{"critical": b}}
+		if err := bad.Validate(); !errors.As(err, &verr) || verr.Field != "boost" {
+			t.Errorf("Validate() with boost %v = %v, want boost ValidationError", b, err)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallBoostLexicalDebug — verifies a debug log for ignored boosts.
+// ---------------------------------------------------------------------------
+
+func TestRecallBoostLexicalDebug(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer slog.SetDefault(prev)
+
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		return RecallResponse{}, nil
+	})
+
+	lexical := "lexical"
+	input := RecallInput{Query: "q", Strategy: &lexical, Boost: map[string]float32{"critical": 0.2}}
+	if _, err := c.Recall(input); err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if !strings.Contains(buf.String(), "level=DEBUG") || !strings.Contains(buf.String(), "lexical") {
+		t.Errorf("log output = %q, want a DEBUG message about the lexical strategy", buf.String())
+	}
+
+	buf.Reset()
+	input.Strategy = nil
+	if _, err := c.Recall(input); err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("log output = %q, want none for the default strategy", buf.String())
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallFallbackStrategy — verifies an empty recall is retried with the
+// fallback strategy.
+// ---------------------------------------------------------------------------
+
+func TestRecallFallbackStrategy(t *testing.T) {
+	var strategies []string
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var raw map[string]interface{}
+		_ = json.Unmarshal(args, &raw)
+		if _, ok := raw["fallback_strategy"]; ok {
+			t.Error("fallback_strategy sent to the server")
+		}
+		strategy, _ := raw["strategy"].(string)
+		strategies = append(strategies, strategy)
+		if strategy == "hybrid" {
+			return RecallResponse{Memories: []RecalledMemory{}, Total: 0}, nil
+		}
+		return RecallResponse{Memories: []RecalledMemory{{ID: "mem-1", Content: "exact words"}}, Total: 1}, nil
+	})
+
+	hybrid, lexical := "hybrid", "lexical"
+	resp, err := c.Recall(RecallInput{Query: "exact words", Strategy: &hybrid, FallbackStrategy: &lexical})
+	if err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if len(strategies) != 2 || strategies[0] != "hybrid" || strategies[1] != "lexical" {
+		t.Errorf("strategies = %v, want [hybrid lexical]", strategies)
+	}
+	if !resp.FallbackAttempted {
+		t.Error("FallbackAttempted = false, want true")
+	}
+	if resp.FallbackStrategyUsed == nil || *resp.FallbackStrategyUsed != "lexical" {
+		t.Errorf("FallbackStrategyUsed = %v, want lexical", resp.FallbackStrategyUsed)
+	}
+	if resp.Total != 1 || len(resp.Memories) != 1 {
+		t.Errorf("response = %+v, want the lexical results", resp)
+	}
+
+	strategies = nil
+	lexicalFirst, err := c.Recall(RecallInput{Query: "exact words", Strategy: &lexical, FallbackStrategy: &hybrid})
+	if err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if len(strategies) != 1 || lexicalFirst.FallbackAttempted || lexicalFirst.FallbackStrategyUsed != nil {
+		t.Errorf("non-empty recall fell back: strategies = %v, response = %+v", strategies, lexicalFirst)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallSnippet — verifies snippets are requested only when asked for.
+// ---------------------------------------------------------------------------
+
+func TestRecallSnippet(t *testing.T) {
+	content := "The quarterly report is due on Friday; remind the team on Wednesday."
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in RecallInput
+		_ = json.Unmarshal(args, &in)
+		m := RecalledMemory{ID: "mem-1", Content: content}
+		if in.Snippet {
+			limit := 200
+			if in.SnippetMaxChars != nil {
+				limit = *in.SnippetMaxChars
+			}
+			m.Snippet = content[:min(limit, len(content))]
+		}
+		return RecallResponse{Memories: []RecalledMemory{m}, Total: 1}, nil
+	})
+
+	plain, err := c.Recall(RecallInput{Query: "report"})
+	if err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if got := plain.Memories[0].Snippet; got != "" {
+		t.Errorf("Snippet = %q, want empty when Snippet is false", got)
+	}
+
+	maxChars := 20
+	snipped, err := c.Recall(RecallInput{Query: "report", Snippet: true, SnippetMaxChars: &maxChars})
+	if err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	m := snipped.Memories[0]
+	if m.Snippet != content[:20] {
+		t.Errorf("Snippet = %q, want %q", m.Snippet, content[:20])
+	}
+	if m.Content != content {
+		t.Errorf("Content = %q, want full content alongside the snippet", m.Content)
+	}
+
+	zero := 0
+	var verr *ValidationError
+	if _, err := c.Recall(RecallInput{Query: "report", Snippet: true, SnippetMaxChars: &zero}); !errors.As(err, &verr) {
+		t.Errorf("Recall with zero SnippetMaxChars = %v, want ValidationError", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallNearestNeighborsJSON — verifies the raw KNN fields' wire names.
+// ---------------------------------------------------------------------------
+
+func TestRecallNearestNeighborsJSON(t *testing.T) {
+	metric := DistanceMetricDotProduct
+	data, err := json.Marshal(RecallInput{Query: "q", NearestNeighbors: true, NNDistanceMetric: &metric})
+	if err != nil {
+		t.Fatalf("Marshal RecallInput: %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+	if raw["nearest_neighbors"] != true {
+		t.Errorf("nearest_neighbors = %v, want true", raw["nearest_neighbors"])
+	}
+	if raw["distance_metric"] != "dot_product" {
+		t.Errorf("distance_metric = %v, want %q", raw["distance_metric"], "dot_product")
+	}
+
+	data, err = json.Marshal(RecallInput{Query: "q"})
+	if err != nil {
+		t.Fatalf("Marshal RecallInput: %v", err)
+	}
+	raw = nil
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+	for _, key := range []string{"nearest_neighbors", "distance_metric"} {
+		if _, ok := raw[key]; ok {
+			t.Errorf("expected %q to be omitted when unset", key)
+		}
+	}
+
+	bad := "manhattan"
+	var verr *ValidationError
+	if err := (RecallInput{Query: "q", NearestNeighbors: true, NNDistanceMetric: &bad}).Validate(); !errors.As(err, &verr) || verr.Field != "distance_metric" {
+		t.Errorf("Validate with unknown metric = %v, want ValidationError on distance_metric", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallBoostRecent — verifies the recency boost fields and their range.
+// ---------------------------------------------------------------------------
+
+func TestRecallBoostRecent(t *testing.T) {
+	data, err := json.Marshal(RecallInput{Query: "q"})
+	if err != nil {
+		t.Fatalf("Marshal RecallInput: %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+	for _, key := range []string{"boost_recent", "boost_recent_window_hours"} {
+		if _, ok := raw[key]; ok {
+			t.Errorf("expected %q to be omitted when nil", key)
+		}
+	}
+
+	penalty := float32(-0.25)
+	window := float32(48)
+	input := RecallInput{Query: "q", BoostRecent: &penalty, BoostRecentWindowHours: &window}
+	if err := input.Validate(); err != nil {
+		t.Errorf("Validate with a negative boost = %v, want nil", err)
+	}
+	data, err = json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal RecallInput: %v", err)
+	}
+	raw = nil
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal to map: %v", err)
+	}
+	if raw["boost_recent"] != -0.25 {
+		t.Errorf("boost_recent = %v, want -0.25", raw["boost_recent"])
+	}
+	if raw["boost_recent_window_hours"] != float64(48) {
+		t.Errorf("boost_recent_window_hours = %v, want 48", raw["boost_recent_window_hours"])
+	}
+
+	for _, b := range []float32{-1.5, 1.01} {
+		b := b
+		var verr *ValidationError
+		if err := (RecallInput{Query: "q", BoostRecent: &b}).Validate(); !errors.As(err, &verr) || verr.Field != "boost_recent" {
+			t.Errorf("Validate with BoostRecent %v = %v, want ValidationError on boost_recent", b, err)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallPersonalizedBy — verifies PersonalizedBy and preference profiles.
+// ---------------------------------------------------------------------------
+
+func TestRecallPersonalizedBy(t *testing.T) {
+	data, err := json.Marshal(RecallInput{Query: "q"})
+	if err != nil {
+		t.Fatalf("Marshal RecallInput: %v", err)
+	}
+	if strings.Contains(string(data), "personalized_by") {
+		t.Errorf("expected 'personalized_by' to be omitted when nil, got %s", data)
+	}
+
+	var profiles []string
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		if name == "mnemo.create_preference_profile" {
+			var in PreferenceInput
+			_ = json.Unmarshal(args, &in)
+			profiles = append(profiles, in.ProfileID)
+			return PreferenceResponse{ProfileID: in.ProfileID, Status: "created"}, nil
+		}
+		var raw map[string]interface{}
+		_ = json.Unmarshal(args, &raw)
+		if raw["personalized_by"] != "user-42" {
+			return nil, &jsonRPCError{Code: -32602, Message: "personalized_by missing: " + string(args)}
+		}
+		return RecallResponse{Memories: []RecalledMemory{{ID: "mem-1"}}, Total: 1}, nil
+	})
+
+	resp, err := c.CreatePreferenceProfile(PreferenceInput{ProfileID: "user-42", TagWeights: map[string]float32{"vegetarian": 0.3}})
+	if err != nil {
+		t.Fatalf("CreatePreferenceProfile: %v", err)
+	}
+	if resp.ProfileID != "user-42" || len(profiles) != 1 {
+		t.Errorf("CreatePreferenceProfile = %+v, profiles = %v", resp, profiles)
+	}
+
+	profile := "user-42"
+	if _, err := c.Recall(RecallInput{Query: "dinner ideas", PersonalizedBy: &profile}); err != nil {
+		t.Errorf("Recall: %v", err)
+	}
+
+	var verr *ValidationError
+	if _, err := c.CreatePreferenceProfile(PreferenceInput{ProfileID: "user-42", TagWeights: map[string]float32{"spicy": 2}}); !errors.As(err, &verr) {
+		t.Errorf("CreatePreferenceProfile with weight 2 = %v, want ValidationError", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestConfidenceScore — verifies ConfidenceScore is independent of
+// Importance and MinConfidence filters low-confidence memories.
+// ---------------------------------------------------------------------------
+
+func TestConfidenceScore(t *testing.T) {
+	importance := float32(0.9)
+	confidence := float32(0.4)
+	data, err := json.Marshal(RememberInput{Content: "The deploy is on Friday", Importance: &importance, ConfidenceScore: &confidence})
+	if err != nil {
+		t.Fatalf("Marshal RememberInput: %v", err)
+	}
+	var in RememberInput
+	if err := json.Unmarshal(data, &in); err != nil {
+		t.Fatalf("Unmarshal RememberInput: %v", err)
+	}
+	if in.Importance == nil || *in.Importance != 0.9 || in.ConfidenceScore == nil || *in.ConfidenceScore != 0.4 {
+		t.Errorf("round trip = importance %v, confidence %v; want 0.9 and 0.4", in.Importance, in.ConfidenceScore)
+	}
+
+	over := float32(1.5)
+	var verr *ValidationError
+	if err := (RememberInput{Content: "x", ConfidenceScore: &over}).Validate(); !errors.As(err, &verr) || verr.Field != "confidence_score" {
+		t.Errorf("Validate with confidence 1.5 = %v, want ValidationError on confidence_score", err)
+	}
+
+	score := func(v float32) *float32 { return &v }
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		return RecallResponse{Memories: []RecalledMemory{
+			{ID: "sure", Importance: 0.2, ConfidenceScore: score(0.95)},
+			{ID: "unsure", Importance: 0.9, ConfidenceScore: score(0.5)},
+			{ID: "borderline", ConfidenceScore: score(0.9)},
+			{ID: "unscored"},
+		}, Total: 4}, nil
+	})
+
+	minConfidence := float32(0.9)
+	resp, err := c.Recall(RecallInput{Query: "deploy", MinConfidence: &minConfidence})
+	if err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	var ids []string
+	for _, m := range resp.Memories {
+		ids = append(ids, m.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"sure", "borderline", "unscored"}) || resp.Total != 3 {
+		t.Errorf("memories = %v (total %d), want [sure borderline unscored] (total 3)", ids, resp.Total)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRecallScopeHierarchy — verifies a scoped recall is widened to the
+// broader scopes listed in the hierarchy.
+// ---------------------------------------------------------------------------
+
+func TestRecallScopeHierarchy(t *testing.T) {
+	var opts ClientOptions
+	h := map[Scope][]Scope{ScopePrivate: {ScopeShared, ScopePublic}}
+	WithScopeHierarchy(h)(&opts)
+	h[ScopePrivate][0] = ScopeGlobal
+	if got := opts.ScopeHierarchy[ScopePrivate]; !reflect.DeepEqual(got, []Scope{ScopeShared, ScopePublic}) {
+		t.Fatalf("ScopeHierarchy[private] = %v, want a copy unaffected by later changes", got)
+	}
+
+	var sent []string
+	c := newPipeClientWithOptions(t, opts, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in RecallInput
+		_ = json.Unmarshal(args, &in)
+		sent = in.Scopes
+		return RecallResponse{}, nil
+	})
+
+	private := "private"
+	if _, err := c.Recall(RecallInput{Query: "q", Scope: &private}); err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if !reflect.DeepEqual(sent, []string{"private", "shared", "public"}) {
+		t.Errorf("scopes = %v, want [private shared public]", sent)
+	}
+
+	public := "public"
+	if _, err := c.Recall(RecallInput{Query: "q", Scope: &public}); err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if sent != nil {
+		t.Errorf("scopes = %v, want none for a scope without a hierarchy entry", sent)
+	}
+
+	if _, err := c.Recall(RecallInput{Query: "q", Scope: &private, Scopes: []string{"private"}}); err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if !reflect.DeepEqual(sent, []string{"private"}) {
+		t.Errorf("scopes = %v, want explicit Scopes left alone", sent)
+	}
+}
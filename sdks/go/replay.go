@@ -0,0 +1,52 @@
+package mnemo
+
+import (
+	"context"
+	"fmt"
+)
+
+// ReplayOption adjusts ConversationReplay.
+type ReplayOption func(*replayConfig)
+
+// replayConfig holds the settings made by ReplayOptions.
+type replayConfig struct {
+	newestFirst bool
+}
+
+// ReplayNewestFirst makes ConversationReplay visit the most recent memory
+// first instead of the oldest.
+func ReplayNewestFirst() ReplayOption {
+	return func(c *replayConfig) {
+		c.newestFirst = true
+	}
+}
+
+// ConversationReplay replays every checkpoint of threadID on branch (nil
+// for "main") and calls fn once for each memory, oldest first unless
+// ReplayNewestFirst is given. If fn returns an error, iteration stops and
+// ConversationReplay returns it wrapped with the ID of the memory being
+// visited.
+func (c *Client) ConversationReplay(ctx context.Context, threadID string, branch *string, fn func(ReplayMemory) error, options ...ReplayOption) error {
+	var cfg replayConfig
+	for _, o := range options {
+		o(&cfg)
+	}
+
+	mode := ReplayModeAll
+	resp, err := c.ReplayContext(ctx, ReplayInput{ThreadID: threadID, BranchName: branch, ReplayMode: &mode})
+	if err != nil {
+		return err
+	}
+
+	n := len(resp.Memories)
+	for i := 0; i < n; i++ {
+		m := resp.Memories[i]
+		if cfg.newestFirst {
+			m = resp.Memories[n-1-i]
+		}
+		if err := fn(m); err != nil {
+			return fmt.Errorf("mnemo: conversation replay stopped at memory %s: %w", m.ID, err)
+		}
+	}
+	return nil
+}
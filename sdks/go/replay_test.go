@@ -0,0 +1,85 @@
+package mnemo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// replayFixture answers mnemo.replay with three memories, oldest first.
+func replayFixture(t *testing.T) *Client {
+	return newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in ReplayInput
+		_ = json.Unmarshal(args, &in)
+		if in.ReplayMode == nil || *in.ReplayMode != ReplayModeAll {
+			t.Errorf("replay_mode = %v, want %q", in.ReplayMode, ReplayModeAll)
+		}
+		return ReplayResponse{
+			MemoryCount: 3,
+			Memories: []ReplayMemory{
+				{ID: "mem-1", Content: "hello"},
+				{ID: "mem-2", Content: "how are you"},
+				{ID: "mem-3", Content: "goodbye"},
+			},
+			Status: "replayed",
+		}, nil
+	})
+}
+
+// ---------------------------------------------------------------------------
+// TestConversationReplay — verifies fn visits every memory in order.
+// ---------------------------------------------------------------------------
+
+func TestConversationReplay(t *testing.T) {
+	c := replayFixture(t)
+
+	var visited []string
+	err := c.ConversationReplay(context.Background(), "thread-1", nil, func(m ReplayMemory) error {
+		visited = append(visited, m.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ConversationReplay: %v", err)
+	}
+	if want := []string{"mem-1", "mem-2", "mem-3"}; !reflect.DeepEqual(visited, want) {
+		t.Errorf("visited = %v, want %v", visited, want)
+	}
+
+	visited = nil
+	err = c.ConversationReplay(context.Background(), "thread-1", nil, func(m ReplayMemory) error {
+		visited = append(visited, m.ID)
+		return nil
+	}, ReplayNewestFirst())
+	if err != nil {
+		t.Fatalf("ConversationReplay: %v", err)
+	}
+	if want := []string{"mem-3", "mem-2", "mem-1"}; !reflect.DeepEqual(visited, want) {
+		t.Errorf("visited newest first = %v, want %v", visited, want)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestConversationReplayStop — verifies an error from fn stops iteration.
+// ---------------------------------------------------------------------------
+
+func TestConversationReplayStop(t *testing.T) {
+	c := replayFixture(t)
+
+	stop := errors.New("enough")
+	var calls int
+	err := c.ConversationReplay(context.Background(), "thread-1", nil, func(m ReplayMemory) error {
+		calls++
+		if m.ID == "mem-2" {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("ConversationReplay() = %v, want it to wrap the callback error", err)
+	}
+	if calls != 2 {
+		t.Errorf("fn called %d times, want 2", calls)
+	}
+}
@@ -0,0 +1,43 @@
+package mnemo
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrResourceUsageUnsupported is returned by Client.ResourceUsage on
+// platforms where process statistics cannot be read.
+var ErrResourceUsageUnsupported = errors.New("mnemo: resource usage not supported on this platform")
+
+// ResourceUsage is a snapshot of the mnemo child process's resource
+// consumption.
+type ResourceUsage struct {
+	// MemoryBytes is the resident set size of the process.
+	MemoryBytes int64
+
+	// CPUPercent is the average CPU utilization since the process started,
+	// where 100 means one core fully busy.
+	CPUPercent float64
+
+	// Uptime is how long the process has been running.
+	Uptime time.Duration
+}
+
+// ResourceUsage reports the memory and CPU use of the mnemo child process,
+// for example to detect a leak in a long-running deployment. It is
+// implemented on Linux and macOS; elsewhere it returns
+// ErrResourceUsageUnsupported.
+func (c *Client) ResourceUsage() (ResourceUsage, error) {
+	pid := c.PID()
+	if pid == 0 {
+		return ResourceUsage{}, errors.New("mnemo: no child process")
+	}
+
+	uptime := time.Since(c.startedAt)
+	usage, err := processUsage(pid, uptime)
+	if err != nil {
+		return ResourceUsage{}, err
+	}
+	usage.Uptime = uptime
+	return usage, nil
+}
@@ -0,0 +1,95 @@
+//go:build linux
+
+package mnemo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks is the kernel USER_HZ used for the CPU times in /proc/<pid>/stat.
+// It is 100 on every mainstream Linux architecture.
+const clockTicks = 100
+
+// processUsage reads the resident set size from /proc/<pid>/status and the
+// CPU time from /proc/<pid>/stat.
+func processUsage(pid int, uptime time.Duration) (ResourceUsage, error) {
+	rss, err := readVmRSS(pid)
+	if err != nil {
+		return ResourceUsage{}, err
+	}
+	cpu, err := readCPUTime(pid)
+	if err != nil {
+		return ResourceUsage{}, err
+	}
+
+	usage := ResourceUsage{MemoryBytes: rss}
+	if uptime > 0 {
+		usage.CPUPercent = cpu.Seconds() / uptime.Seconds() * 100
+	}
+	return usage, nil
+}
+
+// readVmRSS returns the VmRSS line of /proc/<pid>/status in bytes.
+func readVmRSS(pid int) (int64, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return 0, fmt.Errorf("mnemo: resource usage: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), "VmRSS:")
+		if !ok {
+			continue
+		}
+		// The value is reported as "<n> kB".
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			break
+		}
+		kb, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("mnemo: resource usage: parse VmRSS: %w", err)
+		}
+		return kb * 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("mnemo: resource usage: %w", err)
+	}
+	return 0, fmt.Errorf("mnemo: resource usage: VmRSS not found for pid %d", pid)
+}
+
+// readCPUTime returns the user plus system CPU time from /proc/<pid>/stat.
+func readCPUTime(pid int) (time.Duration, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, fmt.Errorf("mnemo: resource usage: %w", err)
+	}
+
+	// The command name in field 2 may contain spaces, so count fields from
+	// the closing parenthesis. utime and stime are fields 14 and 15.
+	end := bytes.LastIndexByte(data, ')')
+	if end < 0 {
+		return 0, fmt.Errorf("mnemo: resource usage: malformed stat for pid %d", pid)
+	}
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 13 {
+		return 0, fmt.Errorf("mnemo: resource usage: malformed stat for pid %d", pid)
+	}
+	var ticks int64
+	for _, f := range fields[11:13] {
+		n, err := strconv.ParseInt(f, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("mnemo: resource usage: parse stat: %w", err)
+		}
+		ticks += n
+	}
+	return time.Duration(ticks) * time.Second / clockTicks, nil
+}
@@ -0,0 +1,39 @@
+//go:build !linux
+
+package mnemo
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// processUsage asks ps for the resident set size and CPU utilization on
+// macOS. Other platforms are not supported.
+func processUsage(pid int, uptime time.Duration) (ResourceUsage, error) {
+	if runtime.GOOS != "darwin" {
+		return ResourceUsage{}, ErrResourceUsageUnsupported
+	}
+
+	out, err := exec.Command("ps", "-o", "rss=,%cpu=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return ResourceUsage{}, fmt.Errorf("mnemo: resource usage: ps: %w", err)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) < 2 {
+		return ResourceUsage{}, fmt.Errorf("mnemo: resource usage: unexpected ps output %q", out)
+	}
+
+	kb, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return ResourceUsage{}, fmt.Errorf("mnemo: resource usage: parse rss: %w", err)
+	}
+	cpu, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return ResourceUsage{}, fmt.Errorf("mnemo: resource usage: parse cpu: %w", err)
+	}
+	return ResourceUsage{MemoryBytes: kb * 1024, CPUPercent: cpu}, nil
+}
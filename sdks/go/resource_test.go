@@ -0,0 +1,37 @@
+package mnemo
+
+import (
+	"errors"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestResourceUsage — verifies statistics are read from a real process.
+// ---------------------------------------------------------------------------
+
+func TestResourceUsage(t *testing.T) {
+	c := newProcessClient(t)
+	defer c.Close()
+
+	usage, err := c.ResourceUsage()
+	if errors.Is(err, ErrResourceUsageUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("ResourceUsage: %v", err)
+	}
+	if usage.MemoryBytes <= 0 {
+		t.Errorf("MemoryBytes = %d, want > 0", usage.MemoryBytes)
+	}
+	if usage.CPUPercent < 0 {
+		t.Errorf("CPUPercent = %v, want >= 0", usage.CPUPercent)
+	}
+	if usage.Uptime <= 0 {
+		t.Errorf("Uptime = %v, want > 0", usage.Uptime)
+	}
+
+	var none Client
+	if _, err := none.ResourceUsage(); err == nil {
+		t.Error("ResourceUsage without a process: expected error, got nil")
+	}
+}
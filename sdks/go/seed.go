@@ -0,0 +1,58 @@
+package mnemo
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultSeedParallelism is used when ClientOptions.SeedParallelism is zero.
+const defaultSeedParallelism = 4
+
+// SeedMemories stores each corpus string as a memory carrying tags, for
+// example to initialize a fresh agent. Up to ClientOptions.SeedParallelism
+// Remember calls run at once.
+//
+// The returned IDs line up with corpus. If any entry fails, its ID is empty
+// and the error is a *SeedError listing every failure; the other entries are
+// still stored.
+func (c *Client) SeedMemories(ctx context.Context, corpus []string, tags []string) ([]string, error) {
+	parallelism := c.opts.SeedParallelism
+	if parallelism <= 0 {
+		parallelism = defaultSeedParallelism
+	}
+
+	ids := make([]string, len(corpus))
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures map[int]error
+	)
+	sem := make(chan struct{}, parallelism)
+	for i, content := range corpus {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, content string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			resp, err := c.RememberContext(ctx, RememberInput{Content: content, Tags: tags})
+			if err != nil {
+				mu.Lock()
+				if failures == nil {
+					failures = make(map[int]error)
+				}
+				failures[i] = err
+				mu.Unlock()
+				return
+			}
+			ids[i] = resp.ID
+		}(i, content)
+	}
+	wg.Wait()
+
+	if failures != nil {
+		return ids, &SeedError{Failures: failures}
+	}
+	return ids, nil
+}
@@ -0,0 +1,86 @@
+package mnemo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestSeedMemories — verifies every corpus entry is stored with the tags and
+// IDs line up with the corpus.
+// ---------------------------------------------------------------------------
+
+func TestSeedMemories(t *testing.T) {
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in RememberInput
+		_ = json.Unmarshal(args, &in)
+		if !reflect.DeepEqual(in.Tags, []string{"seed", "faq"}) {
+			return nil, &jsonRPCError{Code: -32602, Message: fmt.Sprintf("tags = %v", in.Tags)}
+		}
+		return RememberResponse{ID: "id-" + in.Content, Status: "remembered"}, nil
+	})
+
+	corpus := make([]string, 10)
+	for i := range corpus {
+		corpus[i] = fmt.Sprintf("fact-%d", i)
+	}
+	ids, err := c.SeedMemories(context.Background(), corpus, []string{"seed", "faq"})
+	if err != nil {
+		t.Fatalf("SeedMemories: %v", err)
+	}
+	if len(ids) != len(corpus) {
+		t.Fatalf("len(ids) = %d, want %d", len(ids), len(corpus))
+	}
+	for i, id := range ids {
+		if want := "id-" + corpus[i]; id != want {
+			t.Errorf("ids[%d] = %q, want %q", i, id, want)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestSeedMemoriesPartialFailure — verifies failures are collected in a
+// SeedError while the other entries are still stored.
+// ---------------------------------------------------------------------------
+
+func TestSeedMemoriesPartialFailure(t *testing.T) {
+	c := newPipeClientWithOptions(t, ClientOptions{SeedParallelism: 2}, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in RememberInput
+		_ = json.Unmarshal(args, &in)
+		if strings.HasPrefix(in.Content, "bad") {
+			return nil, &jsonRPCError{Code: -32602, Message: "rejected"}
+		}
+		return RememberResponse{ID: "id-" + in.Content, Status: "remembered"}, nil
+	})
+
+	ids, err := c.SeedMemories(context.Background(), []string{"good-0", "bad-1", "good-2", "bad-3"}, nil)
+	var serr *SeedError
+	if !errors.As(err, &serr) {
+		t.Fatalf("SeedMemories error = %v, want *SeedError", err)
+	}
+	if len(serr.Failures) != 2 || serr.Failures[1] == nil || serr.Failures[3] == nil {
+		t.Errorf("Failures = %v, want entries 1 and 3", serr.Failures)
+	}
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		t.Errorf("errors.As(*RPCError) = false for %v", err)
+	}
+	if !reflect.DeepEqual(ids, []string{"id-good-0", "", "id-good-2", ""}) {
+		t.Errorf("ids = %q, want successes in place and failures empty", ids)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestSeedErrorEmpty — verifies a SeedError without failures still formats.
+// ---------------------------------------------------------------------------
+
+func TestSeedErrorEmpty(t *testing.T) {
+	if got := (&SeedError{}).Error(); got != "mnemo: seeding failed" {
+		t.Errorf("Error() = %q, want %q", got, "mnemo: seeding failed")
+	}
+}
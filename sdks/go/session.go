@@ -0,0 +1,194 @@
+package mnemo
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// sessionKey identifies a session by agent and thread.
+type sessionKey struct {
+	agentID  string
+	threadID string
+}
+
+// session is a client together with the time it was last handed out and
+// the number of outstanding Acquire leases on it.
+type session struct {
+	client   *Client
+	lastUsed time.Time
+	leases   int
+}
+
+// SessionManager hands out one Client per (agent, thread) pair, for services
+// that run many agent conversations at once. Each client is created from
+// the manager's base options with AgentID and ThreadID set to the pair, so
+// calls need not repeat them. Sessions not requested for longer than the TTL
+// are closed and removed the next time the manager is used, unless they are
+// held by Acquire.
+//
+// SessionManager is safe for concurrent use.
+type SessionManager struct {
+	opts      ClientOptions
+	ttl       time.Duration
+	newClient func(ClientOptions) (*Client, error)
+	now       func() time.Time
+
+	mu       sync.Mutex
+	sessions map[sessionKey]*session
+}
+
+// NewSessionManager returns a SessionManager that creates clients from opts
+// and reaps sessions idle for longer than ttl. A ttl of zero keeps sessions
+// until they are closed explicitly.
+func NewSessionManager(opts ClientOptions, ttl time.Duration) *SessionManager {
+	return &SessionManager{
+		opts:      opts,
+		ttl:       ttl,
+		newClient: func(o ClientOptions) (*Client, error) { return NewClient(o) },
+		now:       time.Now,
+		sessions:  make(map[sessionKey]*session),
+	}
+}
+
+// Session returns the client for agentID and threadID, creating it if the
+// pair has no live session. Repeated calls with the same pair return the
+// same *Client until it is closed or expires. A session that stays idle past
+// the TTL may be closed while the caller still holds the client; use Acquire
+// for work that can outlast the TTL.
+func (m *SessionManager) Session(agentID, threadID string) (*Client, error) {
+	s, err := m.session(agentID, threadID, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.client, nil
+}
+
+// Acquire is like Session but leases the session: it is not reaped until
+// release is called, however long the lease is held. release may be called
+// more than once; only the first call has an effect.
+func (m *SessionManager) Acquire(agentID, threadID string) (c *Client, release func(), err error) {
+	s, err := m.session(agentID, threadID, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			s.leases--
+			s.lastUsed = m.now()
+		})
+	}
+	return s.client, release, nil
+}
+
+// session returns the live session for the pair, creating it if needed, and
+// takes a lease on it if lease is set. New clients are created without
+// holding m.mu, so a slow start does not block other sessions; if another
+// caller created the same session meanwhile, its client wins and ours is
+// closed.
+func (m *SessionManager) session(agentID, threadID string, lease bool) (*session, error) {
+	key := sessionKey{agentID, threadID}
+
+	m.mu.Lock()
+	m.reapLocked(m.now())
+	if s, ok := m.sessions[key]; ok {
+		m.touchLocked(s, lease)
+		m.mu.Unlock()
+		return s, nil
+	}
+	m.mu.Unlock()
+
+	opts := m.opts
+	opts.AgentID = agentID
+	opts.ThreadID = threadID
+	c, err := m.newClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	if s, ok := m.sessions[key]; ok {
+		m.touchLocked(s, lease)
+		m.mu.Unlock()
+		closeSession(key, c)
+		return s, nil
+	}
+	s := &session{client: c}
+	m.sessions[key] = s
+	m.touchLocked(s, lease)
+	m.mu.Unlock()
+	return s, nil
+}
+
+// touchLocked marks s as used now and takes a lease on it if lease is set.
+// m.mu must be held.
+func (m *SessionManager) touchLocked(s *session, lease bool) {
+	s.lastUsed = m.now()
+	if lease {
+		s.leases++
+	}
+}
+
+// Close terminates the session for agentID and threadID, if any.
+func (m *SessionManager) Close(agentID, threadID string) error {
+	m.mu.Lock()
+	key := sessionKey{agentID, threadID}
+	s, ok := m.sessions[key]
+	delete(m.sessions, key)
+	m.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return s.client.Close()
+}
+
+// CloseAll terminates every session.
+func (m *SessionManager) CloseAll() error {
+	m.mu.Lock()
+	sessions := m.sessions
+	m.sessions = make(map[sessionKey]*session)
+	m.mu.Unlock()
+
+	var firstErr error
+	for _, s := range sessions {
+		if err := s.client.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Len returns the number of live sessions.
+func (m *SessionManager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reapLocked(m.now())
+	return len(m.sessions)
+}
+
+// reapLocked closes unleased sessions idle for longer than the TTL. m.mu
+// must be held.
+func (m *SessionManager) reapLocked(now time.Time) {
+	if m.ttl <= 0 {
+		return
+	}
+	for key, s := range m.sessions {
+		if s.leases == 0 && now.Sub(s.lastUsed) > m.ttl {
+			delete(m.sessions, key)
+			// Close waits for the process to exit, so do it off the lock.
+			go closeSession(key, s.client)
+		}
+	}
+}
+
+// closeSession closes a client the caller no longer hands out, reporting
+// any error through the client's warning handler.
+func closeSession(key sessionKey, c *Client) {
+	if err := c.Close(); err != nil {
+		c.warn(fmt.Errorf("mnemo: close session %s/%s: %w", key.agentID, key.threadID, err))
+	}
+}
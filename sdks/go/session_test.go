@@ -0,0 +1,193 @@
+package mnemo
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestSessionManager returns a SessionManager backed by pipe clients and
+// a controllable clock.
+func newTestSessionManager(t *testing.T, ttl time.Duration, now *time.Time) (*SessionManager, *int) {
+	t.Helper()
+	created := 0
+	m := NewSessionManager(ClientOptions{}, ttl)
+	m.now = func() time.Time { return *now }
+	m.newClient = func(opts ClientOptions) (*Client, error) {
+		created++
+		return newPipeClientWithOptions(t, opts, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+			return map[string]interface{}{"status": "ok"}, nil
+		}), nil
+	}
+	return m, &created
+}
+
+// ---------------------------------------------------------------------------
+// TestSessionManagerReuse — verifies one client per (agent, thread) pair.
+// ---------------------------------------------------------------------------
+
+func TestSessionManagerReuse(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	m, created := newTestSessionManager(t, time.Minute, &now)
+
+	a, err := m.Session("agent-1", "thread-1")
+	if err != nil {
+		t.Fatalf("Session: %v", err)
+	}
+	b, err := m.Session("agent-1", "thread-1")
+	if err != nil {
+		t.Fatalf("Session: %v", err)
+	}
+	if a != b {
+		t.Error("same (agent, thread) returned different clients")
+	}
+	if a.opts.AgentID != "agent-1" || a.opts.ThreadID != "thread-1" {
+		t.Errorf("client scope = %q/%q, want agent-1/thread-1", a.opts.AgentID, a.opts.ThreadID)
+	}
+
+	other, _ := m.Session("agent-1", "thread-2")
+	if other == a {
+		t.Error("different thread returned the same client")
+	}
+	if *created != 2 || m.Len() != 2 {
+		t.Errorf("created = %d, Len() = %d, want 2 and 2", *created, m.Len())
+	}
+
+	if err := m.Close("agent-1", "thread-1"); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if m.Len() != 1 {
+		t.Errorf("Len() after Close = %d, want 1", m.Len())
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestSessionManagerTTL — verifies idle sessions expire after the TTL.
+// ---------------------------------------------------------------------------
+
+func TestSessionManagerTTL(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	m, created := newTestSessionManager(t, time.Minute, &now)
+
+	first, _ := m.Session("agent-1", "thread-1")
+
+	now = now.Add(30 * time.Second)
+	if again, _ := m.Session("agent-1", "thread-1"); again != first {
+		t.Error("session expired before the TTL")
+	}
+
+	now = now.Add(61 * time.Second)
+	if m.Len() != 0 {
+		t.Errorf("Len() after TTL = %d, want 0", m.Len())
+	}
+	fresh, _ := m.Session("agent-1", "thread-1")
+	if fresh == first {
+		t.Error("expired session was reused")
+	}
+	if *created != 2 {
+		t.Errorf("created = %d, want 2", *created)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestSessionManagerLease — verifies leased sessions outlive the TTL until
+// released.
+// ---------------------------------------------------------------------------
+
+func TestSessionManagerLease(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	m, _ := newTestSessionManager(t, time.Minute, &now)
+
+	leased, release, err := m.Acquire("agent-1", "thread-1")
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	now = now.Add(5 * time.Minute)
+	if m.Len() != 1 {
+		t.Fatalf("Len() with a lease held past the TTL = %d, want 1", m.Len())
+	}
+	if again, _ := m.Session("agent-1", "thread-1"); again != leased {
+		t.Error("leased session was replaced")
+	}
+
+	release()
+	release()
+	now = now.Add(61 * time.Second)
+	if m.Len() != 0 {
+		t.Errorf("Len() after release and TTL = %d, want 0", m.Len())
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestSessionManagerConcurrentCreate — verifies clients are created without
+// holding the manager's lock and that concurrent creators share one session.
+// ---------------------------------------------------------------------------
+
+func TestSessionManagerConcurrentCreate(t *testing.T) {
+	var mu sync.Mutex
+	created := 0
+	var started sync.WaitGroup
+	started.Add(2)
+	unblock := make(chan struct{})
+
+	m := NewSessionManager(ClientOptions{}, time.Minute)
+	m.newClient = func(opts ClientOptions) (*Client, error) {
+		mu.Lock()
+		created++
+		mu.Unlock()
+		if opts.ThreadID == "slow" {
+			started.Done()
+			<-unblock
+		}
+		return newPipeClientWithOptions(t, opts, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+			return map[string]interface{}{}, nil
+		}), nil
+	}
+
+	clients := make([]*Client, 2)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], _ = m.Session("agent-1", "slow")
+		}(i)
+	}
+
+	// Both creators are inside newClient; other sessions must not wait.
+	started.Wait()
+	if _, err := m.Session("agent-1", "fast"); err != nil {
+		t.Fatalf("Session: %v", err)
+	}
+	close(unblock)
+	wg.Wait()
+
+	if clients[0] == nil || clients[0] != clients[1] {
+		t.Error("concurrent Session calls for one pair returned different clients")
+	}
+	if created != 3 || m.Len() != 2 {
+		t.Errorf("created = %d, Len() = %d, want 3 and 2", created, m.Len())
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestClientOptionsThreadID — verifies the client default thread is applied.
+// ---------------------------------------------------------------------------
+
+func TestClientOptionsThreadID(t *testing.T) {
+	var gotArgs map[string]interface{}
+	c := newPipeClientWithOptions(t, ClientOptions{ThreadID: "thread-default"}, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		gotArgs = nil
+		_ = json.Unmarshal(args, &gotArgs)
+		return RememberResponse{ID: "mem-1"}, nil
+	})
+
+	if _, err := c.Remember(RememberInput{Content: "x"}); err != nil {
+		t.Fatalf("Remember: %v", err)
+	}
+	if gotArgs["thread_id"] != "thread-default" {
+		t.Errorf("thread_id = %v, want %q", gotArgs["thread_id"], "thread-default")
+	}
+}
@@ -0,0 +1,71 @@
+package mnemo
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestUnixSocket — verifies the client dials ClientOptions.UnixSocket and
+// runs calls over it.
+// ---------------------------------------------------------------------------
+
+func TestUnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets are not available on windows")
+	}
+
+	// Keep the path short: socket paths are limited to about 100 bytes.
+	dir, err := os.MkdirTemp("", "mnemo")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "mnemo.sock")
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer ln.Close()
+
+	accepted := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		accepted <- conn.LocalAddr().String()
+		serveFake(conn, conn, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+			return RecallResponse{Memories: []RecalledMemory{{ID: "mem-sock"}}, Total: 1}, nil
+		})
+	}()
+
+	c, err := NewClient(ClientOptions{Command: "/nonexistent/mnemo", UnixSocket: path})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer c.Close()
+
+	if got := <-accepted; got != path {
+		t.Errorf("server accepted on %q, want %q", got, path)
+	}
+	resp, err := c.Recall(RecallInput{Query: "socket"})
+	if err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+	if len(resp.Memories) != 1 || resp.Memories[0].ID != "mem-sock" {
+		t.Errorf("Recall() = %+v, want mem-sock", resp)
+	}
+
+	missing := filepath.Join(dir, "missing.sock")
+	_, err = NewClient(ClientOptions{UnixSocket: missing})
+	if err == nil || !strings.Contains(err.Error(), missing) {
+		t.Errorf("NewClient with missing socket = %v, want an error naming %s", err, missing)
+	}
+}
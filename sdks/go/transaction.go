@@ -0,0 +1,47 @@
+package mnemo
+
+// TransactionBuilder assembles a TransactionInput with chainable calls:
+//
+//	input := mnemo.BuildTransaction().
+//		AddForget(mnemo.ForgetInput{MemoryIDs: []string{oldID}}).
+//		AddRemember(mnemo.RememberInput{Content: "replacement"}).
+//		Build()
+//
+// A TransactionBuilder is not safe for concurrent use.
+type TransactionBuilder struct {
+	ops []TransactionOp
+}
+
+// BuildTransaction returns an empty TransactionBuilder.
+func BuildTransaction() *TransactionBuilder {
+	return &TransactionBuilder{}
+}
+
+// AddRemember appends a remember operation.
+func (b *TransactionBuilder) AddRemember(input RememberInput) *TransactionBuilder {
+	return b.add(TransactionOpRemember, input)
+}
+
+// AddForget appends a forget operation.
+func (b *TransactionBuilder) AddForget(input ForgetInput) *TransactionBuilder {
+	return b.add(TransactionOpForget, input)
+}
+
+// AddUpdate appends an update operation. payload is sent to the server
+// unchanged and must identify the memory to update.
+func (b *TransactionBuilder) AddUpdate(payload interface{}) *TransactionBuilder {
+	return b.add(TransactionOpUpdate, payload)
+}
+
+// Build returns the assembled TransactionInput. The builder may be reused;
+// later Add calls do not affect inputs already returned.
+func (b *TransactionBuilder) Build() TransactionInput {
+	ops := make([]TransactionOp, len(b.ops))
+	copy(ops, b.ops)
+	return TransactionInput{Operations: ops}
+}
+
+func (b *TransactionBuilder) add(opType string, payload interface{}) *TransactionBuilder {
+	b.ops = append(b.ops, TransactionOp{Type: opType, Payload: payload})
+	return b
+}
@@ -0,0 +1,101 @@
+package mnemo
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestTransactionBuilder — verifies operations are recorded in order.
+// ---------------------------------------------------------------------------
+
+func TestTransactionBuilder(t *testing.T) {
+	b := BuildTransaction().
+		AddForget(ForgetInput{MemoryIDs: []string{"mem-old"}}).
+		AddRemember(RememberInput{Content: "replacement"}).
+		AddUpdate(map[string]interface{}{"memory_id": "mem-2", "importance": 0.9})
+
+	input := b.Build()
+	want := []string{TransactionOpForget, TransactionOpRemember, TransactionOpUpdate}
+	if len(input.Operations) != len(want) {
+		t.Fatalf("len(Operations) = %d, want %d", len(input.Operations), len(want))
+	}
+	for i, op := range input.Operations {
+		if op.Type != want[i] {
+			t.Errorf("Operations[%d].Type = %q, want %q", i, op.Type, want[i])
+		}
+	}
+
+	b.AddForget(ForgetInput{})
+	if len(input.Operations) != 3 {
+		t.Errorf("Build result changed after later Add: len = %d", len(input.Operations))
+	}
+
+	if err := input.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	var verr *ValidationError
+	if err := BuildTransaction().Build().Validate(); !errors.As(err, &verr) {
+		t.Errorf("empty Validate() = %v, want ValidationError", err)
+	}
+	bad := TransactionInput{Operations: []TransactionOp{{Type: "merge"}}}
+	if err := bad.Validate(); !errors.As(err, &verr) {
+		t.Errorf("unknown type Validate() = %v, want ValidationError", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestTransactionRolledBack — verifies a partial failure reports rollback.
+// ---------------------------------------------------------------------------
+
+func TestTransactionRolledBack(t *testing.T) {
+	var gotOps []struct {
+		Type    string          `json:"type"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in struct {
+			Operations json.RawMessage `json:"operations"`
+		}
+		_ = json.Unmarshal(args, &in)
+		_ = json.Unmarshal(in.Operations, &gotOps)
+
+		id := "mem-new"
+		msg := "memory not found"
+		return TransactionResponse{
+			Results: []TransactionResult{
+				{Index: 0, Type: TransactionOpRemember, Status: "rolled_back", ID: &id},
+				{Index: 1, Type: TransactionOpForget, Status: "failed", Error: &msg},
+			},
+			Status: "rolled_back",
+		}, nil
+	})
+
+	input := BuildTransaction().
+		AddRemember(RememberInput{Content: "replacement"}).
+		AddForget(ForgetInput{MemoryIDs: []string{"missing"}}).
+		Build()
+	resp, err := c.Transaction(input)
+	if err != nil {
+		t.Fatalf("Transaction: %v", err)
+	}
+	if resp.Status != "rolled_back" {
+		t.Errorf("Status = %q, want %q", resp.Status, "rolled_back")
+	}
+	if len(resp.Results) != 2 || resp.Results[1].Error == nil {
+		t.Errorf("Results = %+v, want failing second operation", resp.Results)
+	}
+
+	if len(gotOps) != 2 || gotOps[0].Type != "remember" || gotOps[1].Type != "forget" {
+		t.Fatalf("server operations = %+v", gotOps)
+	}
+	var payload RememberInput
+	if err := json.Unmarshal(gotOps[0].Payload, &payload); err != nil || payload.Content != "replacement" {
+		t.Errorf("remember payload = %s", gotOps[0].Payload)
+	}
+
+	if _, err := c.Transaction(TransactionInput{}); err == nil {
+		t.Error("Transaction with no operations: expected error, got nil")
+	}
+}
@@ -8,10 +8,28 @@
 // JSON-RPC 2.0 messages.
 package mnemo
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
 // ---------------------------------------------------------------------------
 // Remember
 // ---------------------------------------------------------------------------
 
+// Scope is a memory visibility scope, as used by ClientOptions.ScopeHierarchy.
+type Scope string
+
+// Visibility scopes accepted by RememberInput.Scope and RecallInput.Scope.
+const (
+	ScopePrivate Scope = "private"
+	ScopeShared  Scope = "shared"
+	ScopePublic  Scope = "public"
+	ScopeGlobal  Scope = "global"
+)
+
 // RememberInput contains parameters for storing a new memory.
 type RememberInput struct {
 	// Content is the text to remember. Required.
@@ -21,7 +39,8 @@ type RememberInput struct {
 	AgentID *string `json:"agent_id,omitempty"`
 
 	// MemoryType classifies the memory: "episodic", "semantic", "procedural",
-	// or "working". Defaults to "episodic".
+	// "working", or a custom type registered in DefaultMemoryTypes. Defaults
+	// to "episodic".
 	MemoryType *string `json:"memory_type,omitempty"`
 
 	// Scope controls visibility: "private", "shared", "public", or "global".
@@ -63,13 +82,61 @@ type RememberInput struct {
 
 	// CreatedBy records which agent originally created this memory.
 	CreatedBy *string `json:"created_by,omitempty"`
+
+	// AllowDuplicate stores the memory even if one with identical content
+	// already exists. By default the existing memory is returned instead.
+	AllowDuplicate bool `json:"allow_duplicate,omitempty"`
+
+	// IdempotencyKey makes retries safe: if the server has already stored a
+	// memory under this key within the idempotency window, it returns the
+	// original response with Status "deduplicated" instead of storing again.
+	IdempotencyKey *string `json:"idempotency_key,omitempty"`
+
+	// PersonaID tags the memory with the persona the agent is acting as, so
+	// recalls under that persona find it. See WithPersona.
+	PersonaID *string `json:"persona_id,omitempty"`
+
+	// ConfidenceScore is how certain the agent is that the memory is true,
+	// from 0.0 to 1.0. Unlike Importance, which reflects how significant
+	// the fact is, it does not affect ranking; see RecallInput.MinConfidence.
+	ConfidenceScore *float32 `json:"confidence_score,omitempty"`
+}
+
+// Validate checks RememberInput for values the server would reject. A
+// MemoryType that is neither built in nor registered in DefaultMemoryTypes
+// is logged as a warning rather than rejected.
+func (in RememberInput) Validate() error {
+	if in.MemoryType != nil {
+		warnUnknownMemoryType(*in.MemoryType)
+	}
+	if in.ConfidenceScore != nil && (*in.ConfidenceScore < 0 || *in.ConfidenceScore > 1) {
+		return &ValidationError{Field: "confidence_score", Message: fmt.Sprintf("must be within [0, 1], got %v", *in.ConfidenceScore)}
+	}
+	return nil
 }
 
 // RememberResponse is returned after successfully storing a memory.
 type RememberResponse struct {
 	ID          string `json:"id"`
 	ContentHash string `json:"content_hash"`
-	Status      string `json:"status"`
+
+	// Status is "remembered" for a new memory, or "deduplicated" when an
+	// existing memory with identical content or the same idempotency key was
+	// returned instead.
+	Status string `json:"status"`
+
+	// Duplicate reports that no new memory was created.
+	Duplicate bool `json:"duplicate,omitempty"`
+
+	// ExistingID is the ID of the pre-existing memory when Duplicate is set.
+	ExistingID *string `json:"existing_id,omitempty"`
+}
+
+// rememberIdempotentArgs adds the client's idempotency window to a remember
+// request.
+type rememberIdempotentArgs struct {
+	RememberInput
+	IdempotencyWindowSeconds uint64 `json:"idempotency_window_seconds"`
 }
 
 // ---------------------------------------------------------------------------
@@ -83,8 +150,92 @@ type TemporalRange struct {
 
 	// Before returns only memories created before this RFC 3339 timestamp.
 	Before *string `json:"before,omitempty"`
+
+	// AfterTime is a typed alternative to After. When set it takes precedence
+	// and is serialized as RFC 3339 in the "after" field.
+	AfterTime *time.Time `json:"-"`
+
+	// BeforeTime is a typed alternative to Before. When set it takes
+	// precedence and is serialized as RFC 3339 in the "before" field.
+	BeforeTime *time.Time `json:"-"`
+}
+
+// temporalRangeWire is the on-the-wire shape of TemporalRange.
+type temporalRangeWire struct {
+	After  *string `json:"after,omitempty"`
+	Before *string `json:"before,omitempty"`
+}
+
+// MarshalJSON encodes the range, preferring AfterTime and BeforeTime over
+// their string counterparts when both are set.
+func (r TemporalRange) MarshalJSON() ([]byte, error) {
+	w := temporalRangeWire{After: r.After, Before: r.Before}
+	if r.AfterTime != nil {
+		s := r.AfterTime.Format(time.RFC3339Nano)
+		w.After = &s
+	}
+	if r.BeforeTime != nil {
+		s := r.BeforeTime.Format(time.RFC3339Nano)
+		w.Before = &s
+	}
+	return json.Marshal(w)
+}
+
+// UnmarshalJSON decodes the range, populating both the string fields and,
+// when they parse as RFC 3339, the typed time fields.
+func (r *TemporalRange) UnmarshalJSON(data []byte) error {
+	var w temporalRangeWire
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	*r = TemporalRange{After: w.After, Before: w.Before}
+	if w.After != nil {
+		if t, err := time.Parse(time.RFC3339Nano, *w.After); err == nil {
+			r.AfterTime = &t
+		}
+	}
+	if w.Before != nil {
+		if t, err := time.Parse(time.RFC3339Nano, *w.Before); err == nil {
+			r.BeforeTime = &t
+		}
+	}
+	return nil
 }
 
+// Reranker models accepted by RecallInput.RerankerModel.
+const (
+	RerankerModelCrossEncoder = "cross_encoder"
+	RerankerModelBM25         = "bm25"
+	RerankerModelNone         = "none"
+)
+
+// Score-combination methods accepted by RecallInput.HybridStrategy.
+const (
+	HybridStrategyRRF    = "rrf"
+	HybridStrategyLinear = "linear"
+)
+
+// Result orderings accepted by RecallInput.SortBy.
+const (
+	SortByScore      = "score"
+	SortByCreatedAt  = "created_at"
+	SortByImportance = "importance"
+	SortByUpdatedAt  = "updated_at"
+)
+
+// Sort directions accepted by RecallInput.SortOrder.
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// Vector distance metrics accepted by RecallInput.NNDistanceMetric.
+const (
+	DistanceMetricCosine     = "cosine"
+	DistanceMetricDotProduct = "dot_product"
+	DistanceMetricL2         = "l2"
+)
+
 // RecallInput contains parameters for searching and retrieving memories.
 type RecallInput struct {
 	// Query is a natural language search string. Required.
@@ -103,24 +254,188 @@ type RecallInput struct {
 	// precedence over MemoryType if both are set.
 	MemoryTypes []string `json:"memory_types,omitempty"`
 
+	// MemoryTypeAnd switches MemoryTypes from "any of" to "all of": only
+	// memories carrying every listed type are returned.
+	MemoryTypeAnd bool `json:"memory_type_and,omitempty"`
+
+	// ExcludeMemoryTypes drops memories of these types. The client also
+	// filters them from the response in case the server does not honor the
+	// field.
+	ExcludeMemoryTypes []string `json:"exclude_memory_types,omitempty"`
+
 	// Scope filters by visibility scope.
 	Scope *string `json:"scope,omitempty"`
 
+	// Scopes filters by multiple visibility scopes simultaneously. Takes
+	// precedence over Scope if both are set. See
+	// ClientOptions.ScopeHierarchy.
+	Scopes []string `json:"scopes,omitempty"`
+
 	// MinImportance filters by minimum importance score (0.0 to 1.0).
 	MinImportance *float32 `json:"min_importance,omitempty"`
 
+	// MinConfidence filters out memories whose ConfidenceScore is below
+	// this value (0.0 to 1.0). Memories stored without a confidence score
+	// are kept. The client also filters the response in case the server
+	// does not honor the field.
+	MinConfidence *float32 `json:"min_confidence,omitempty"`
+
 	// Tags filters by tag, returning memories matching any specified tag.
 	Tags []string `json:"tags,omitempty"`
 
 	// OrgID overrides the default organization identifier.
 	OrgID *string `json:"org_id,omitempty"`
 
+	// ThreadID scopes the search to memories of one conversation thread.
+	ThreadID *string `json:"thread_id,omitempty"`
+
+	// PersonaID restricts the search to memories tagged with this persona.
+	// See WithPersona.
+	PersonaID *string `json:"persona_id,omitempty"`
+
 	// Strategy selects the retrieval algorithm: "semantic", "lexical",
 	// "hybrid", "graph", "exact", or "auto". Defaults to "auto".
 	Strategy *string `json:"strategy,omitempty"`
 
 	// TemporalRange constrains results by creation time.
 	TemporalRange *TemporalRange `json:"temporal_range,omitempty"`
+
+	// IncludeMetadata asks the server to return each memory's metadata map.
+	// When false, metadata is omitted to keep high-frequency recalls small.
+	IncludeMetadata bool `json:"include_metadata,omitempty"`
+
+	// ExcludeIDs lists memory IDs the caller already holds. The server skips
+	// them even if they score highly; the client also strips them from the
+	// response in case the server does not honor the field.
+	ExcludeIDs []string `json:"exclude_ids,omitempty"`
+
+	// DecaySimulatedAt asks the server to apply the Ebbinghaus decay curve as
+	// if the current time were this instant, so callers can preview what
+	// would be recalled in the future. Serialized as RFC 3339.
+	DecaySimulatedAt *time.Time `json:"decay_simulated_at,omitempty"`
+
+	// HybridAlpha weights the "hybrid" strategy between BM25 (0.0) and
+	// vector similarity (1.0). Must be within [0, 1].
+	HybridAlpha *float32 `json:"hybrid_alpha,omitempty"`
+
+	// HybridStrategy selects how the "hybrid" strategy combines its rankings:
+	// "rrf" (Reciprocal Rank Fusion) or "linear".
+	HybridStrategy *string `json:"hybrid_strategy,omitempty"`
+
+	// RerankerModel re-scores the initial candidates with the given model
+	// before results are returned. See the RerankerModel* constants.
+	RerankerModel *string `json:"reranker_model,omitempty"`
+
+	// SortBy orders results by a field other than the default score ranking.
+	// See the SortBy* constants. The client re-sorts the response in case the
+	// server does not honor the field.
+	SortBy *string `json:"sort_by,omitempty"`
+
+	// SortOrder is SortOrderAsc or SortOrderDesc. Defaults to descending.
+	SortOrder *string `json:"sort_order,omitempty"`
+
+	// Boost adds a per-tag amount to the score of memories carrying that
+	// tag, for example {"critical": 0.2}, so they rank higher regardless of
+	// similarity. Values must be within [-1, 1]. The "lexical" strategy
+	// ignores boosts.
+	Boost map[string]float32 `json:"boost,omitempty"`
+
+	// BoostRecent adds this amount to the score of memories created within
+	// the last BoostRecentWindowHours, so recent memories outrank older ones
+	// of similar relevance. Negative values penalize recent memories
+	// instead. Must be within [-1, 1].
+	BoostRecent *float32 `json:"boost_recent,omitempty"`
+
+	// BoostRecentWindowHours is the window BoostRecent applies to. Nil uses
+	// the server default.
+	BoostRecentWindowHours *float32 `json:"boost_recent_window_hours,omitempty"`
+
+	// Explain asks the server to populate RecalledMemory.ScoreBreakdown with
+	// the components that produced each score.
+	Explain bool `json:"explain,omitempty"`
+
+	// Snippet asks the server to populate RecalledMemory.Snippet with a short
+	// excerpt around the query match. Content is still returned in full.
+	Snippet bool `json:"snippet,omitempty"`
+
+	// SnippetMaxChars caps the snippet length. Nil uses the server default
+	// of 200 characters.
+	SnippetMaxChars *int `json:"snippet_max_chars,omitempty"`
+
+	// NearestNeighbors returns raw vector k-nearest-neighbor results, ranked
+	// by embedding distance alone. Importance boosts, decay, and BM25 are
+	// not applied.
+	NearestNeighbors bool `json:"nearest_neighbors,omitempty"`
+
+	// NNDistanceMetric selects the distance used by NearestNeighbors. See
+	// the DistanceMetric* constants. Nil uses the server default, cosine.
+	NNDistanceMetric *string `json:"distance_metric,omitempty"`
+
+	// PersonalizedBy names a user ID or a profile created with
+	// CreatePreferenceProfile. The server re-weights candidates by that
+	// profile's preferences.
+	PersonalizedBy *string `json:"personalized_by,omitempty"`
+
+	// DeduplicateThreshold collapses near-duplicate results client-side. Two
+	// memories whose content similarity (normalized Levenshtein, 0.0 to 1.0)
+	// is at or above the threshold are treated as duplicates and only the
+	// higher-scoring one is kept. Nil disables deduplication. Not sent to the
+	// server.
+	DeduplicateThreshold *float32 `json:"-"`
+
+	// FallbackStrategy is retried when the primary Strategy finds nothing,
+	// for example "lexical" after an empty "hybrid" recall. The client makes
+	// the second call and records it in RecallResponse.FallbackAttempted.
+	// Not sent to the server.
+	FallbackStrategy *string `json:"-"`
+}
+
+// Validate checks RecallInput for values the server would reject.
+func (in RecallInput) Validate() error {
+	if in.DecaySimulatedAt != nil && !in.DecaySimulatedAt.After(time.Time{}) {
+		return &ValidationError{Field: "decay_simulated_at", Message: "must be after the zero time"}
+	}
+	if in.HybridAlpha != nil && (*in.HybridAlpha < 0 || *in.HybridAlpha > 1) {
+		return &ValidationError{Field: "hybrid_alpha", Message: fmt.Sprintf("must be within [0, 1], got %v", *in.HybridAlpha)}
+	}
+	if in.HybridStrategy != nil && *in.HybridStrategy != HybridStrategyRRF && *in.HybridStrategy != HybridStrategyLinear {
+		return &ValidationError{Field: "hybrid_strategy", Message: fmt.Sprintf("must be %q or %q, got %q", HybridStrategyRRF, HybridStrategyLinear, *in.HybridStrategy)}
+	}
+	if in.SortBy != nil {
+		switch *in.SortBy {
+		case SortByScore, SortByCreatedAt, SortByImportance, SortByUpdatedAt:
+		default:
+			return &ValidationError{Field: "sort_by", Message: fmt.Sprintf("unknown sort key %q", *in.SortBy)}
+		}
+	}
+	if in.SortOrder != nil && *in.SortOrder != SortOrderAsc && *in.SortOrder != SortOrderDesc {
+		return &ValidationError{Field: "sort_order", Message: fmt.Sprintf("must be %q or %q, got %q", SortOrderAsc, SortOrderDesc, *in.SortOrder)}
+	}
+	if in.MinConfidence != nil && (*in.MinConfidence < 0 || *in.MinConfidence > 1) {
+		return &ValidationError{Field: "min_confidence", Message: fmt.Sprintf("must be within [0, 1], got %v", *in.MinConfidence)}
+	}
+	if in.SnippetMaxChars != nil && *in.SnippetMaxChars <= 0 {
+		return &ValidationError{Field: "snippet_max_chars", Message: fmt.Sprintf("must be positive, got %d", *in.SnippetMaxChars)}
+	}
+	if in.NNDistanceMetric != nil {
+		switch *in.NNDistanceMetric {
+		case DistanceMetricCosine, DistanceMetricDotProduct, DistanceMetricL2:
+		default:
+			return &ValidationError{Field: "distance_metric", Message: fmt.Sprintf("unknown distance metric %q", *in.NNDistanceMetric)}
+		}
+	}
+	if in.BoostRecent != nil && (*in.BoostRecent < -1 || *in.BoostRecent > 1) {
+		return &ValidationError{Field: "boost_recent", Message: fmt.Sprintf("must be within [-1, 1], got %v", *in.BoostRecent)}
+	}
+	if in.BoostRecentWindowHours != nil && *in.BoostRecentWindowHours <= 0 {
+		return &ValidationError{Field: "boost_recent_window_hours", Message: fmt.Sprintf("must be positive, got %v", *in.BoostRecentWindowHours)}
+	}
+	for tag, b := range in.Boost {
+		if b < -1 || b > 1 {
+			return &ValidationError{Field: "boost", Message: fmt.Sprintf("value for tag %q must be within [-1, 1], got %v", tag, b)}
+		}
+	}
+	return nil
 }
 
 // RecalledMemory represents a single memory returned by a recall query.
@@ -135,12 +450,70 @@ type RecalledMemory struct {
 	Score      float32  `json:"score"`
 	CreatedAt  string   `json:"created_at"`
 	UpdatedAt  string   `json:"updated_at"`
+
+	// Metadata holds the key-value pairs stored with the memory. Servers that
+	// honor RecallInput.IncludeMetadata populate it only when that flag is set.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// TTLSeconds, CreatedBy, SourceType, and SourceID echo the values given
+	// in RememberInput so a recalled memory can be reconstructed in full.
+	TTLSeconds *uint64 `json:"ttl_seconds,omitempty"`
+	CreatedBy  *string `json:"created_by,omitempty"`
+	SourceType *string `json:"source_type,omitempty"`
+	SourceID   *string `json:"source_id,omitempty"`
+
+	// ScoreBreakdown maps score components (for example "vector_score",
+	// "bm25_score", "importance_boost", "decay_factor") to their values. Set
+	// only when RecallInput.Explain is true.
+	ScoreBreakdown map[string]float32 `json:"score_breakdown,omitempty"`
+
+	// RerankerScore is the score assigned by RecallInput.RerankerModel. Nil
+	// when no reranker ran.
+	RerankerScore *float32 `json:"reranker_score,omitempty"`
+
+	// Status is "archived" for memories returned from the archive by
+	// ArchiveRecall, and "active" or empty otherwise.
+	Status string `json:"status,omitempty"`
+
+	// Snippet is an excerpt of Content around the query match. It is empty
+	// unless RecallInput.Snippet was set.
+	Snippet string `json:"snippet,omitempty"`
+
+	// ConfidenceScore echoes RememberInput.ConfidenceScore. Nil if none was
+	// given.
+	ConfidenceScore *float32 `json:"confidence_score,omitempty"`
+}
+
+// SearchMetadata describes how a recall was executed, for tuning recall
+// quality.
+type SearchMetadata struct {
+	// StrategyUsed is the retrieval strategy that ran, which may differ from
+	// the requested one when "auto" was chosen.
+	StrategyUsed string `json:"strategy_used"`
+
+	// CandidateCount is the number of candidates generated before filtering.
+	CandidateCount int `json:"candidate_count"`
+
+	// FilteredCount is the number of candidates removed by filters.
+	FilteredCount int `json:"filtered_count"`
+
+	EmbeddingLatencyMs int64 `json:"embedding_latency_ms"`
+	SearchLatencyMs    int64 `json:"search_latency_ms"`
 }
 
 // RecallResponse is returned after searching for memories.
 type RecallResponse struct {
 	Memories []RecalledMemory `json:"memories"`
 	Total    int              `json:"total"`
+
+	// SearchMetadata is set by servers that report search statistics.
+	SearchMetadata *SearchMetadata `json:"search_metadata,omitempty"`
+
+	// FallbackAttempted reports that the primary strategy returned nothing
+	// and RecallInput.FallbackStrategy was tried. FallbackStrategyUsed names
+	// it; the response holds the fallback's results, which may also be empty.
+	FallbackAttempted    bool    `json:"fallback_attempted,omitempty"`
+	FallbackStrategyUsed *string `json:"fallback_strategy_used,omitempty"`
 }
 
 // ---------------------------------------------------------------------------
@@ -178,6 +551,46 @@ type ForgetInput struct {
 
 	// Criteria enables filter-based forget when MemoryIDs is empty.
 	Criteria *ForgetCriteria `json:"criteria,omitempty"`
+
+	// ThreadID restricts the forget to memories of one conversation thread.
+	ThreadID *string `json:"thread_id,omitempty"`
+
+	// Cascade also forgets every memory that lists a forgotten memory in
+	// RelatedTo, following the relation graph transitively. The extra
+	// memories are counted in ForgetResponse.CascadeCount.
+	Cascade bool `json:"cascade,omitempty"`
+
+	// DryRun asks the server to report which memories would be forgotten
+	// without deleting anything. The IDs are returned in
+	// ForgetResponse.Forgotten and Status is "dry_run" rather than
+	// "forgotten", so check Status before treating them as gone.
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// SimulateDecay previews the "decay" strategy: nothing is deleted, and
+	// ForgetResponse.Forgotten lists the memories whose decayed importance
+	// would fall below the forget threshold within DecaySimulationHours.
+	// Requires Strategy "decay".
+	SimulateDecay bool `json:"simulate_decay,omitempty"`
+
+	// DecaySimulationHours is how far ahead SimulateDecay looks. Nil uses
+	// the current time.
+	DecaySimulationHours *float64 `json:"decay_simulation_hours,omitempty"`
+}
+
+// Validate checks ForgetInput for values the server would reject.
+func (in ForgetInput) Validate() error {
+	if in.SimulateDecay && (in.Strategy == nil || *in.Strategy != "decay") {
+		return &ValidationError{Field: "simulate_decay", Message: `requires strategy "decay"`}
+	}
+	if in.DecaySimulationHours != nil {
+		if !in.SimulateDecay {
+			return &ValidationError{Field: "decay_simulation_hours", Message: "requires simulate_decay"}
+		}
+		if *in.DecaySimulationHours < 0 {
+			return &ValidationError{Field: "decay_simulation_hours", Message: fmt.Sprintf("must not be negative, got %v", *in.DecaySimulationHours)}
+		}
+	}
+	return nil
 }
 
 // ForgetError describes a failure to forget a specific memory.
@@ -188,9 +601,23 @@ type ForgetError struct {
 
 // ForgetResponse is returned after a forget operation.
 type ForgetResponse struct {
-	Forgotten []string      `json:"forgotten"`
-	Errors    []ForgetError `json:"errors"`
-	Status    string        `json:"status"`
+	// Forgotten lists the affected memory IDs. For a dry run or a decay
+	// simulation these are the memories that would have been forgotten;
+	// nothing was deleted.
+	Forgotten []string `json:"forgotten"`
+
+	// ArchivedIDs lists the memories moved to cold storage by the "archive"
+	// strategy. They are not included in Forgotten and can be restored.
+	ArchivedIDs []string `json:"archived_ids,omitempty"`
+
+	// CascadeCount is the number of related memories forgotten in addition
+	// to the requested ones when ForgetInput.Cascade is set.
+	CascadeCount int `json:"cascade_count,omitempty"`
+
+	Errors []ForgetError `json:"errors"`
+
+	// Status is "forgotten" for a live run and "dry_run" for a dry run.
+	Status string `json:"status"`
 }
 
 // ---------------------------------------------------------------------------
@@ -219,6 +646,11 @@ type ShareInput struct {
 
 	// ExpiresInHours sets a TTL on the share. Nil means no expiration.
 	ExpiresInHours *float64 `json:"expires_in_hours,omitempty"`
+
+	// GroupID shares with every agent in a group created by CreateGroup.
+	// When set, TargetAgentID and TargetAgentIDs are ignored and the server
+	// expands the group into ShareResponse.SharedWith.
+	GroupID *string `json:"group_id,omitempty"`
 }
 
 // ShareResponse is returned after sharing a memory.
@@ -250,16 +682,79 @@ type CheckpointInput struct {
 	// Label is a human-readable label for this checkpoint.
 	Label *string `json:"label,omitempty"`
 
-	// Metadata holds additional key-value pairs.
+	// AutoLabel asks the server to generate a label from the snapshot, such
+	// as "2024-06-01T12:00:00Z – 14 memories". Mutually exclusive with Label.
+	AutoLabel bool `json:"auto_label,omitempty"`
+
+	// AutoLabelPrefix is prepended to the generated label. Requires
+	// AutoLabel.
+	AutoLabelPrefix *string `json:"auto_label_prefix,omitempty"`
+
+	// Tags categorize the checkpoint so it can be found by tag later.
+	Tags []string `json:"tags,omitempty"`
+
+	// Metadata holds additional key-value pairs stored alongside the state
+	// snapshot.
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// Compress gzip-compresses and base64-encodes StateSnapshot before it is
+	// sent, to shrink large snapshots such as full conversation histories.
+	// Replay decompresses it transparently; see DecompressSnapshot.
+	Compress bool `json:"compress,omitempty"`
+}
+
+// Validate checks CheckpointInput for values the server would reject.
+func (in CheckpointInput) Validate() error {
+	if in.AutoLabel && in.Label != nil {
+		return &ValidationError{Field: "auto_label", Message: "cannot be combined with label"}
+	}
+	if in.AutoLabelPrefix != nil && !in.AutoLabel {
+		return &ValidationError{Field: "auto_label_prefix", Message: "requires auto_label"}
+	}
+	return nil
 }
 
 // CheckpointResponse is returned after creating a checkpoint.
 type CheckpointResponse struct {
-	CheckpointID string  `json:"checkpoint_id"`
-	ParentID     *string `json:"parent_id"`
-	BranchName   string  `json:"branch_name"`
-	Status       string  `json:"status"`
+	CheckpointID string   `json:"checkpoint_id"`
+	ParentID     *string  `json:"parent_id"`
+	BranchName   string   `json:"branch_name"`
+	Tags         []string `json:"tags,omitempty"`
+	Status       string   `json:"status"`
+
+	// Compressed reports whether the snapshot was stored compressed.
+	Compressed bool `json:"compressed,omitempty"`
+}
+
+// CheckpointAndPrepareContextInput contains parameters for checkpointing and
+// then recalling the memories the next step needs. It accepts every
+// CheckpointInput field.
+type CheckpointAndPrepareContextInput struct {
+	CheckpointInput
+
+	// RecallQuery is the query used to retrieve relevant memories once the
+	// checkpoint is created. Required.
+	RecallQuery string
+
+	// RecallLimit caps the number of recalled memories. Nil uses the
+	// Recall default.
+	RecallLimit *int
+}
+
+// Validate checks CheckpointAndPrepareContextInput for values the server
+// would reject.
+func (in CheckpointAndPrepareContextInput) Validate() error {
+	if in.RecallQuery == "" {
+		return &ValidationError{Field: "recall_query", Message: "is required"}
+	}
+	return in.CheckpointInput.Validate()
+}
+
+// CheckpointAndPrepareContextResponse holds the results of
+// CheckpointAndPrepareContext.
+type CheckpointAndPrepareContextResponse struct {
+	Checkpoint *CheckpointResponse
+	Recall     *RecallResponse
 }
 
 // ---------------------------------------------------------------------------
@@ -280,6 +775,36 @@ type BranchInput struct {
 
 	// SourceBranch is the branch to fork from. Defaults to "main".
 	SourceBranch *string `json:"source_branch,omitempty"`
+
+	// AutoLabel asks the server to generate a description of the branch
+	// from its source checkpoint.
+	AutoLabel bool `json:"auto_label,omitempty"`
+
+	// CopyMemories gives the branch its own copies of the source branch's
+	// memories instead of sharing them copy-on-write, so later changes on
+	// either branch never affect the other.
+	CopyMemories bool `json:"copy_memories,omitempty"`
+
+	// CopyDepth limits CopyMemories to memories from this many checkpoints
+	// back. Nil copies the whole history.
+	CopyDepth *int `json:"copy_depth,omitempty"`
+
+	// ProtectBranch marks the new branch read-only once it is created, so
+	// later checkpoints on it are rejected. See SetBranchProtection.
+	ProtectBranch bool `json:"protect_branch,omitempty"`
+}
+
+// Validate checks BranchInput for values the server would reject.
+func (in BranchInput) Validate() error {
+	if in.CopyDepth != nil {
+		if !in.CopyMemories {
+			return &ValidationError{Field: "copy_depth", Message: "requires copy_memories"}
+		}
+		if *in.CopyDepth <= 0 {
+			return &ValidationError{Field: "copy_depth", Message: fmt.Sprintf("must be positive, got %d", *in.CopyDepth)}
+		}
+	}
+	return nil
 }
 
 // BranchResponse is returned after creating a branch.
@@ -288,12 +813,40 @@ type BranchResponse struct {
 	BranchName         string `json:"branch_name"`
 	SourceCheckpointID string `json:"source_checkpoint_id"`
 	Status             string `json:"status"`
+
+	// CopiedMemoryCount is the number of memories copied when
+	// BranchInput.CopyMemories was set, and zero otherwise.
+	CopiedMemoryCount int `json:"copied_memory_count,omitempty"`
+}
+
+// setBranchProtectionInput is the argument of "mnemo.set_branch_protection".
+type setBranchProtectionInput struct {
+	ThreadID   string `json:"thread_id"`
+	BranchName string `json:"branch_name"`
+	Protected  bool   `json:"protected"`
+}
+
+// BranchProtectionResponse is returned after changing a branch's
+// protection.
+type BranchProtectionResponse struct {
+	ThreadID   string `json:"thread_id"`
+	BranchName string `json:"branch_name"`
+	Protected  bool   `json:"protected"`
+	Status     string `json:"status"`
 }
 
 // ---------------------------------------------------------------------------
 // Merge
 // ---------------------------------------------------------------------------
 
+// Conflict resolution rules accepted by MergeInput.ConflictResolver.
+const (
+	ConflictResolverSourceWins           = "source_wins"
+	ConflictResolverTargetWins           = "target_wins"
+	ConflictResolverNewerWins            = "newer_wins"
+	ConflictResolverHigherImportanceWins = "higher_importance_wins"
+)
+
 // MergeInput contains parameters for merging branches.
 type MergeInput struct {
 	// ThreadID identifies the conversation thread. Required.
@@ -312,20 +865,82 @@ type MergeInput struct {
 	// CherryPickIDs lists specific memory UUIDs for the "cherry_pick"
 	// strategy.
 	CherryPickIDs []string `json:"cherry_pick_ids,omitempty"`
+
+	// SquashMessage becomes the content of the consolidated memory produced
+	// by the "squash" strategy instead of an auto-generated summary. Ignored
+	// for other strategies.
+	SquashMessage *string `json:"squash_message,omitempty"`
+
+	// SquashLabel is an optional human-readable label for the squashed
+	// memory.
+	SquashLabel *string `json:"squash_label,omitempty"`
+
+	// ConflictResolver decides which version wins when both branches
+	// modified the same memory. See the ConflictResolver* constants. Each
+	// conflict is reported in MergeResponse.Conflicts.
+	ConflictResolver *string `json:"conflict_resolver,omitempty"`
+
+	// DryRun asks the server to report what the merge would do without
+	// changing any state. The response lists the memories that would be
+	// merged, the conflicts, and the checkpoint ID that would be created,
+	// and Status is "dry_run" rather than "merged".
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// Validate checks MergeInput for values the server would reject.
+func (in MergeInput) Validate() error {
+	if in.ConflictResolver != nil {
+		switch *in.ConflictResolver {
+		case ConflictResolverSourceWins, ConflictResolverTargetWins,
+			ConflictResolverNewerWins, ConflictResolverHigherImportanceWins:
+		default:
+			return &ValidationError{Field: "conflict_resolver", Message: fmt.Sprintf("unknown resolver %q", *in.ConflictResolver)}
+		}
+	}
+	return nil
 }
 
-// MergeResponse is returned after merging branches.
+// MergeConflict reports how a memory modified on both branches was
+// resolved.
+type MergeConflict struct {
+	MemoryID string `json:"memory_id"`
+
+	// Resolution is the ConflictResolver* rule that picked the winner.
+	Resolution string `json:"resolution"`
+}
+
+// MergeResponse is returned after merging branches. For a dry run,
+// CheckpointID is the checkpoint the merge would create.
 type MergeResponse struct {
-	CheckpointID     string `json:"checkpoint_id"`
-	TargetBranch     string `json:"target_branch"`
-	MergedMemoryCount int   `json:"merged_memory_count"`
-	Status           string `json:"status"`
+	CheckpointID      string `json:"checkpoint_id"`
+	TargetBranch      string `json:"target_branch"`
+	MergedMemoryCount int    `json:"merged_memory_count"`
+	Status            string `json:"status"`
+
+	// SquashedMemoryID is the ID of the consolidated memory created by a
+	// squash merge. Nil for other strategies.
+	SquashedMemoryID *string `json:"squashed_memory_id,omitempty"`
+
+	// Conflicts lists the memories modified on both branches and how each
+	// was resolved.
+	Conflicts []MergeConflict `json:"conflicts,omitempty"`
+
+	// MergedMemoryIDs lists the memories merged into the target branch, or
+	// for a dry run the memories that would be.
+	MergedMemoryIDs []string `json:"merged_memory_ids,omitempty"`
 }
 
 // ---------------------------------------------------------------------------
 // Replay
 // ---------------------------------------------------------------------------
 
+// Replay modes accepted by ReplayInput.ReplayMode.
+const (
+	ReplayModeLatest = "latest"
+	ReplayModeAll    = "all"
+	ReplayModeRange  = "range"
+)
+
 // ReplayInput contains parameters for replaying state from a checkpoint.
 type ReplayInput struct {
 	// ThreadID identifies the conversation thread. Required.
@@ -337,6 +952,40 @@ type ReplayInput struct {
 
 	// BranchName is the branch to replay from. Defaults to "main".
 	BranchName *string `json:"branch_name,omitempty"`
+
+	// ReplayMode selects which checkpoints to replay: ReplayModeLatest (the
+	// default) replays one checkpoint, ReplayModeAll every checkpoint in
+	// order, and ReplayModeRange those from FromCheckpointID to
+	// ToCheckpointID inclusive.
+	ReplayMode *string `json:"replay_mode,omitempty"`
+
+	// FromCheckpointID and ToCheckpointID bound a ReplayModeRange replay.
+	FromCheckpointID *string `json:"from_checkpoint_id,omitempty"`
+	ToCheckpointID   *string `json:"to_checkpoint_id,omitempty"`
+}
+
+// Validate checks ReplayInput for values the server would reject.
+func (in ReplayInput) Validate() error {
+	mode := ReplayModeLatest
+	if in.ReplayMode != nil {
+		mode = *in.ReplayMode
+	}
+	switch mode {
+	case ReplayModeLatest, ReplayModeAll:
+		if in.FromCheckpointID != nil || in.ToCheckpointID != nil {
+			return &ValidationError{Field: "from_checkpoint_id", Message: fmt.Sprintf("requires replay_mode %q", ReplayModeRange)}
+		}
+	case ReplayModeRange:
+		if in.FromCheckpointID == nil {
+			return &ValidationError{Field: "from_checkpoint_id", Message: "is required in range mode"}
+		}
+		if in.ToCheckpointID == nil {
+			return &ValidationError{Field: "to_checkpoint_id", Message: "is required in range mode"}
+		}
+	default:
+		return &ValidationError{Field: "replay_mode", Message: fmt.Sprintf("unknown replay mode %q", mode)}
+	}
+	return nil
 }
 
 // ReplayCheckpoint holds checkpoint details within a replay response.
@@ -350,13 +999,20 @@ type ReplayCheckpoint struct {
 
 // ReplayMemory holds a summarized memory within a replay response.
 type ReplayMemory struct {
-	ID         string `json:"id"`
-	Content    string `json:"content"`
-	MemoryType string `json:"memory_type"`
-	CreatedAt  string `json:"created_at"`
+	ID         string                 `json:"id"`
+	Content    string                 `json:"content"`
+	MemoryType string                 `json:"memory_type"`
+	CreatedAt  string                 `json:"created_at"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	TTLSeconds *uint64                `json:"ttl_seconds,omitempty"`
+	CreatedBy  *string                `json:"created_by,omitempty"`
+	SourceType *string                `json:"source_type,omitempty"`
+	SourceID   *string                `json:"source_id,omitempty"`
 }
 
-// ReplayResponse is returned after replaying a checkpoint.
+// ReplayResponse is returned after replaying a checkpoint. In ReplayModeAll
+// and ReplayModeRange, Checkpoint is the last checkpoint replayed and
+// Memories holds the memories accumulated across every replayed checkpoint.
 type ReplayResponse struct {
 	Checkpoint  ReplayCheckpoint `json:"checkpoint"`
 	MemoryCount int              `json:"memory_count"`
@@ -415,6 +1071,19 @@ type DelegateInput struct {
 
 	// ExpiresInHours sets a TTL on the delegation. Nil means permanent.
 	ExpiresInHours *float64 `json:"expires_in_hours,omitempty"`
+
+	// ScopeQuery scopes the delegation to the memories a Recall with this
+	// query matches when the delegation is created. The server evaluates the
+	// query. Mutually exclusive with MemoryIDs.
+	ScopeQuery *string `json:"scope_query,omitempty"`
+}
+
+// Validate checks DelegateInput for values the server would reject.
+func (in DelegateInput) Validate() error {
+	if in.ScopeQuery != nil && len(in.MemoryIDs) > 0 {
+		return &ValidationError{Field: "scope_query", Message: "cannot be combined with memory_ids"}
+	}
+	return nil
 }
 
 // DelegateResponse is returned after creating a delegation.
@@ -424,6 +1093,972 @@ type DelegateResponse struct {
 	Delegate     string `json:"delegate"`
 	Permission   string `json:"permission"`
 	Status       string `json:"status"`
+
+	// ScopeQueryCount is the number of memories DelegateInput.ScopeQuery
+	// matched.
+	ScopeQueryCount int `json:"scope_query_count,omitempty"`
+}
+
+// ---------------------------------------------------------------------------
+// Pin
+// ---------------------------------------------------------------------------
+
+// PinMemoryInput contains parameters for pinning a memory. A pinned memory is
+// immune to TTL expiry, decay-based forget, and criteria-based forget until
+// it is unpinned or explicitly hard-deleted.
+type PinMemoryInput struct {
+	// MemoryID is the UUID of the memory to pin. Required.
+	MemoryID string `json:"memory_id"`
+
+	// PinReason records why the memory was pinned.
+	PinReason *string `json:"pin_reason,omitempty"`
+}
+
+// Validate checks PinMemoryInput for values the server would reject.
+func (in PinMemoryInput) Validate() error {
+	if in.MemoryID == "" {
+		return &ValidationError{Field: "memory_id", Message: "is required"}
+	}
+	return nil
+}
+
+// PinMemoryResponse is returned after pinning a memory.
+type PinMemoryResponse struct {
+	MemoryID string `json:"memory_id"`
+	Status   string `json:"status"`
+}
+
+// UnpinMemoryInput contains parameters for unpinning a memory.
+type UnpinMemoryInput struct {
+	// MemoryID is the UUID of the memory to unpin. Required.
+	MemoryID string `json:"memory_id"`
+}
+
+// Validate checks UnpinMemoryInput for values the server would reject.
+func (in UnpinMemoryInput) Validate() error {
+	if in.MemoryID == "" {
+		return &ValidationError{Field: "memory_id", Message: "is required"}
+	}
+	return nil
+}
+
+// UnpinMemoryResponse is returned after unpinning a memory.
+type UnpinMemoryResponse struct {
+	MemoryID string `json:"memory_id"`
+	Status   string `json:"status"`
+}
+
+// ---------------------------------------------------------------------------
+// Tags
+// ---------------------------------------------------------------------------
+
+// TagMemoriesInput contains parameters for adding and removing tags across
+// many memories at once.
+type TagMemoriesInput struct {
+	// MemoryIDs lists the memory UUIDs to re-tag. May be empty when using
+	// Criteria.
+	MemoryIDs []string `json:"memory_ids,omitempty"`
+
+	// AddTags are applied to every targeted memory.
+	AddTags []string `json:"add_tags,omitempty"`
+
+	// RemoveTags are removed from every targeted memory.
+	RemoveTags []string `json:"remove_tags,omitempty"`
+
+	// Criteria targets every memory matching an age, importance, type, or tag
+	// filter instead of (or in addition to) MemoryIDs.
+	Criteria *ForgetCriteria `json:"criteria,omitempty"`
+}
+
+// Validate checks TagMemoriesInput for values the server would reject.
+func (in TagMemoriesInput) Validate() error {
+	if len(in.AddTags) == 0 && len(in.RemoveTags) == 0 {
+		return &ValidationError{Field: "add_tags", Message: "at least one of add_tags or remove_tags is required"}
+	}
+	return nil
+}
+
+// TagError describes a failure to re-tag a specific memory.
+type TagError struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// TagMemoriesResponse is returned after a batch tag operation.
+type TagMemoriesResponse struct {
+	Updated int        `json:"updated"`
+	Errors  []TagError `json:"errors"`
+	Status  string     `json:"status"`
+}
+
+// ListTagsInput contains parameters for enumerating distinct tags.
+type ListTagsInput struct {
+	// AgentID overrides the default agent identifier.
+	AgentID *string `json:"agent_id,omitempty"`
+
+	// Prefix returns only tags starting with this string.
+	Prefix *string `json:"prefix,omitempty"`
+
+	// MinCount omits tags attached to fewer than this many memories.
+	MinCount *int `json:"min_count,omitempty"`
+}
+
+// TagInfo describes one distinct tag and how many memories carry it.
+type TagInfo struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// ListTagsResponse is returned after listing tags.
+type ListTagsResponse struct {
+	Tags  []TagInfo `json:"tags"`
+	Total int       `json:"total"`
+}
+
+// RenameTagInput contains parameters for renaming a tag across all memories.
+type RenameTagInput struct {
+	// OldTag is the tag to replace. Required.
+	OldTag string `json:"old_tag"`
+
+	// NewTag is the replacement tag. Required and must differ from OldTag.
+	NewTag string `json:"new_tag"`
+
+	// AgentID overrides the default agent identifier.
+	AgentID *string `json:"agent_id,omitempty"`
+
+	// DryRun reports how many memories would change without modifying
+	// storage.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// Validate checks RenameTagInput for values the server would reject.
+func (in RenameTagInput) Validate() error {
+	if in.OldTag == "" {
+		return &ValidationError{Field: "old_tag", Message: "is required"}
+	}
+	if in.NewTag == "" {
+		return &ValidationError{Field: "new_tag", Message: "is required"}
+	}
+	if in.OldTag == in.NewTag {
+		return &ValidationError{Field: "new_tag", Message: "must differ from old_tag"}
+	}
+	return nil
+}
+
+// RenameTagResponse is returned after renaming a tag. For a dry run,
+// UpdatedCount is the number of memories that would change.
+type RenameTagResponse struct {
+	UpdatedCount int    `json:"updated_count"`
+	Status       string `json:"status"`
+}
+
+// ---------------------------------------------------------------------------
+// Lookup
+// ---------------------------------------------------------------------------
+
+// LookupByHashInput contains parameters for retrieving a memory by the
+// content hash returned from Remember.
+type LookupByHashInput struct {
+	// ContentHash is the hash to look up. Required.
+	ContentHash string `json:"content_hash"`
+}
+
+// Validate checks LookupByHashInput for values the server would reject.
+func (in LookupByHashInput) Validate() error {
+	if in.ContentHash == "" {
+		return &ValidationError{Field: "content_hash", Message: "is required"}
+	}
+	return nil
+}
+
+// LookupByHashResponse is returned after a hash lookup. A miss is not an
+// error: Found is false and Memory is nil.
+type LookupByHashResponse struct {
+	Memory *RecalledMemory `json:"memory"`
+	Found  bool            `json:"found"`
+}
+
+// ---------------------------------------------------------------------------
+// Copy
+// ---------------------------------------------------------------------------
+
+// CopyMemoryInput contains parameters for copying a memory to another agent
+// or organization. Unlike Share, the copy is independent: its content, tags,
+// and metadata can be changed without affecting the source.
+type CopyMemoryInput struct {
+	// SourceMemoryID is the UUID of the memory to copy. Required.
+	SourceMemoryID string `json:"source_memory_id"`
+
+	// TargetAgentID owns the copy. Defaults to the calling agent.
+	TargetAgentID *string `json:"target_agent_id,omitempty"`
+
+	// TargetOrgID places the copy in another organization.
+	TargetOrgID *string `json:"target_org_id,omitempty"`
+
+	// InheritTags copies the source memory's tags onto the copy.
+	InheritTags bool `json:"inherit_tags"`
+
+	// InheritMetadata copies the source memory's metadata onto the copy.
+	InheritMetadata bool `json:"inherit_metadata"`
+}
+
+// Validate checks CopyMemoryInput for values the server would reject.
+func (in CopyMemoryInput) Validate() error {
+	if in.SourceMemoryID == "" {
+		return &ValidationError{Field: "source_memory_id", Message: "is required"}
+	}
+	return nil
+}
+
+// CopyMemoryResponse is returned after copying a memory.
+type CopyMemoryResponse struct {
+	NewMemoryID string `json:"new_memory_id"`
+	Status      string `json:"status"`
+}
+
+// ---------------------------------------------------------------------------
+// Archive
+// ---------------------------------------------------------------------------
+
+// ArchiveRecallInput contains parameters for searching memories archived by
+// the "archive" forget strategy. It accepts every RecallInput field.
+type ArchiveRecallInput struct {
+	RecallInput
+
+	// IncludeActive also searches active memories, so archived and active
+	// results are ranked together. RecalledMemory.Status tells them apart.
+	IncludeActive bool `json:"include_active,omitempty"`
+}
+
+// restoreArchiveInput is the argument of "mnemo.restore_archive".
+type restoreArchiveInput struct {
+	MemoryIDs []string `json:"memory_ids"`
+}
+
+// RestoreResponse is returned after moving archived memories back to active
+// storage.
+type RestoreResponse struct {
+	Restored []string      `json:"restored"`
+	Errors   []ForgetError `json:"errors"`
+
+	// Status is "restored" when every memory was restored.
+	Status string `json:"status"`
+}
+
+// ---------------------------------------------------------------------------
+// Access
+// ---------------------------------------------------------------------------
+
+// Permissions accepted by CanAccessInput.Permission and
+// DelegateInput.Permission.
+const (
+	PermissionRead     = "read"
+	PermissionWrite    = "write"
+	PermissionDelete   = "delete"
+	PermissionShare    = "share"
+	PermissionDelegate = "delegate"
+	PermissionAdmin    = "admin"
+)
+
+// CanAccessInput contains parameters for checking whether the calling agent
+// holds a permission on a memory, before attempting the operation.
+type CanAccessInput struct {
+	// MemoryID is the UUID of the memory to check. Required.
+	MemoryID string `json:"memory_id"`
+
+	// Permission is the access level to check; see the Permission*
+	// constants. Required.
+	Permission string `json:"permission"`
+}
+
+// Validate checks CanAccessInput for values the server would reject.
+func (in CanAccessInput) Validate() error {
+	if in.MemoryID == "" {
+		return &ValidationError{Field: "memory_id", Message: "is required"}
+	}
+	switch in.Permission {
+	case PermissionRead, PermissionWrite, PermissionDelete,
+		PermissionShare, PermissionDelegate, PermissionAdmin:
+		return nil
+	default:
+		return &ValidationError{Field: "permission", Message: fmt.Sprintf("unknown permission %q", in.Permission)}
+	}
+}
+
+// CanAccessResponse is returned after an access check.
+type CanAccessResponse struct {
+	Allowed bool `json:"allowed"`
+
+	// Reason explains a denial, for example which ACL rule applied.
+	Reason *string `json:"reason,omitempty"`
+}
+
+// BatchCheckAccessInput contains parameters for checking several permissions
+// in one call.
+type BatchCheckAccessInput struct {
+	// Checks are the memory/permission pairs to check. At least one is
+	// required.
+	Checks []CanAccessInput `json:"checks"`
+}
+
+// Validate checks BatchCheckAccessInput for values the server would reject.
+func (in BatchCheckAccessInput) Validate() error {
+	if len(in.Checks) == 0 {
+		return &ValidationError{Field: "checks", Message: "at least one check is required"}
+	}
+	for i, check := range in.Checks {
+		if err := check.Validate(); err != nil {
+			var verr *ValidationError
+			if errors.As(err, &verr) {
+				return &ValidationError{
+					Field:   "checks",
+					Message: fmt.Sprintf("check %d: %s %s", i, verr.Field, verr.Message),
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// CanAccessResult is the outcome of one check in a batch.
+type CanAccessResult struct {
+	MemoryID   string  `json:"memory_id"`
+	Permission string  `json:"permission"`
+	Allowed    bool    `json:"allowed"`
+	Reason     *string `json:"reason,omitempty"`
+}
+
+// BatchCheckAccessResponse is returned after a batch access check. Results
+// are in the same order as BatchCheckAccessInput.Checks.
+type BatchCheckAccessResponse struct {
+	Results []CanAccessResult `json:"results"`
+}
+
+// ---------------------------------------------------------------------------
+// Audit
+// ---------------------------------------------------------------------------
+
+// AuditQueryInput contains parameters for querying the audit log. Every
+// filter is optional; unset filters match all events.
+type AuditQueryInput struct {
+	// AgentID restricts results to events performed by this agent.
+	AgentID *string `json:"agent_id,omitempty"`
+
+	// MemoryID restricts results to events on this memory.
+	MemoryID *string `json:"memory_id,omitempty"`
+
+	// EventTypes restricts results to these event types, for example
+	// "memory_read" or "memory_deleted".
+	EventTypes []string `json:"event_types,omitempty"`
+
+	// After and Before bound the event timestamp.
+	After  *time.Time `json:"after,omitempty"`
+	Before *time.Time `json:"before,omitempty"`
+
+	// Limit caps the number of returned events.
+	Limit *int `json:"limit,omitempty"`
+}
+
+// AuditEvent is a single entry in the audit log.
+type AuditEvent struct {
+	ID        string `json:"id"`
+	MemoryID  string `json:"memory_id"`
+	AgentID   string `json:"agent_id"`
+	EventType string `json:"event_type"`
+	Detail    string `json:"detail"`
+	Timestamp string `json:"timestamp"`
+}
+
+// AuditResponse is returned by an audit query. Total counts every matching
+// event, which may exceed len(Events) when Limit is set.
+type AuditResponse struct {
+	Events []AuditEvent `json:"events"`
+	Total  int          `json:"total"`
+}
+
+// ---------------------------------------------------------------------------
+// Health
+// ---------------------------------------------------------------------------
+
+// Values of HealthStatus.Status.
+const (
+	HealthOK          = "ok"
+	HealthDegraded    = "degraded"
+	HealthUnavailable = "unavailable"
+)
+
+// HealthStatus reports the state of the server and its dependencies.
+type HealthStatus struct {
+	// Status is HealthOK, HealthDegraded, or HealthUnavailable.
+	Status string `json:"status"`
+
+	StorageOK        bool `json:"storage_ok"`
+	EmbeddingModelOK bool `json:"embedding_model_ok"`
+	HashChainOK      bool `json:"hash_chain_ok"`
+
+	// Version is the server version.
+	Version string `json:"version"`
+
+	// Uptime is the server uptime as reported by the server, for example
+	// "3h12m5s".
+	Uptime string `json:"uptime"`
+
+	// Details holds per-component diagnostics, typically populated only for
+	// failing components.
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// ---------------------------------------------------------------------------
+// Schema
+// ---------------------------------------------------------------------------
+
+// SchemaVersionResponse describes the server's storage schema.
+type SchemaVersionResponse struct {
+	// Version is the schema version currently on disk.
+	Version uint32 `json:"version"`
+
+	// MinCompatible is the oldest schema version the server can use without
+	// migrating.
+	MinCompatible uint32 `json:"min_compatible"`
+
+	// MigrationRequired reports that the database must be migrated before
+	// the server can serve requests.
+	MigrationRequired bool `json:"migration_required"`
+}
+
+// ---------------------------------------------------------------------------
+// Vacuum
+// ---------------------------------------------------------------------------
+
+// VacuumInput contains parameters for reclaiming storage held by
+// soft-deleted memories.
+type VacuumInput struct {
+	// AgentID restricts the vacuum to one agent's memories. Nil vacuums every
+	// agent the caller administers.
+	AgentID *string
+
+	// MaxDuration bounds how long the server spends vacuuming. When it
+	// elapses the server stops and reports a partial vacuum. Nil or zero
+	// means no limit.
+	MaxDuration *time.Duration
+}
+
+// vacuumInputWire is the on-the-wire shape of VacuumInput.
+type vacuumInputWire struct {
+	AgentID       *string `json:"agent_id,omitempty"`
+	MaxDurationMS *int64  `json:"max_duration_ms,omitempty"`
+}
+
+// MarshalJSON encodes the input, sending MaxDuration as whole milliseconds
+// and omitting it when zero.
+func (in VacuumInput) MarshalJSON() ([]byte, error) {
+	w := vacuumInputWire{AgentID: in.AgentID}
+	if in.MaxDuration != nil && *in.MaxDuration > 0 {
+		ms := in.MaxDuration.Milliseconds()
+		w.MaxDurationMS = &ms
+	}
+	return json.Marshal(w)
+}
+
+// VacuumResponse is returned after a vacuum.
+type VacuumResponse struct {
+	RecoveredBytes int64 `json:"recovered_bytes"`
+	DeletedCount   int   `json:"deleted_count"`
+
+	// Duration is how long the vacuum ran, for example "1.5s".
+	Duration string `json:"duration"`
+
+	// Status is "completed", or "partial" when MaxDuration cut it short.
+	Status string `json:"status"`
+}
+
+// ---------------------------------------------------------------------------
+// Backup
+// ---------------------------------------------------------------------------
+
+// Backup formats accepted by BackupInput.Format.
+const (
+	BackupFormatSQLite      = "sqlite"
+	BackupFormatParquet     = "parquet"
+	BackupFormatJSONArchive = "json_archive"
+)
+
+// BackupInput contains parameters for exporting the memory store.
+type BackupInput struct {
+	// Format is the backup format; see the BackupFormat* constants. Required.
+	Format string `json:"format"`
+
+	// Incremental exports only records changed since SinceTimestamp, or since
+	// the last backup when SinceTimestamp is nil.
+	Incremental bool `json:"incremental,omitempty"`
+
+	// SinceTimestamp is the lower bound for an incremental backup.
+	SinceTimestamp *time.Time `json:"since_timestamp,omitempty"`
+}
+
+// Validate checks BackupInput for values the server would reject.
+func (in BackupInput) Validate() error {
+	return validateBackupFormat(in.Format)
+}
+
+// validateBackupFormat reports whether format is a known backup format.
+func validateBackupFormat(format string) error {
+	switch format {
+	case BackupFormatSQLite, BackupFormatParquet, BackupFormatJSONArchive:
+		return nil
+	case "":
+		return &ValidationError{Field: "format", Message: "is required"}
+	default:
+		return &ValidationError{Field: "format", Message: fmt.Sprintf("unknown backup format %q", format)}
+	}
+}
+
+// BackupSummary describes a completed backup.
+type BackupSummary struct {
+	RecordCount int64 `json:"record_count"`
+	SizeBytes   int64 `json:"size_bytes"`
+
+	// Checksum is the server's SHA-256 of the backup bytes, hex-encoded.
+	Checksum string `json:"checksum"`
+
+	Format    string `json:"format"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Conflict strategies accepted by RestoreOptions.ConflictStrategy.
+const (
+	RestoreConflictSkip      = "skip"
+	RestoreConflictOverwrite = "overwrite"
+)
+
+// RestoreOptions contains parameters for importing a backup produced by
+// Backup.
+type RestoreOptions struct {
+	// Format is the format of the backup; see the BackupFormat* constants.
+	// Required.
+	Format string `json:"format"`
+
+	// ConflictStrategy decides what happens when a restored record already
+	// exists; see the RestoreConflict* constants. Empty uses the server
+	// default, RestoreConflictSkip.
+	ConflictStrategy string `json:"conflict_strategy,omitempty"`
+
+	// DryRun validates the backup and reports what would be imported without
+	// writing anything.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// Validate checks RestoreOptions for values the server would reject.
+func (o RestoreOptions) Validate() error {
+	return validateBackupFormat(o.Format)
+}
+
+// restoreChunkInput is one chunk of a backup streamed to mnemo.restore.
+// Chunks sharing an UploadID are reassembled by the server in ChunkIndex
+// order; the import runs when the Final chunk arrives.
+type restoreChunkInput struct {
+	RestoreOptions
+	UploadID   string `json:"upload_id"`
+	ChunkIndex int    `json:"chunk_index"`
+	Data       string `json:"data"`
+	Final      bool   `json:"final"`
+}
+
+// RestoreError describes a backup record that could not be imported.
+type RestoreError struct {
+	// RecordID is the ID of the failing record, when it could be read.
+	RecordID string `json:"record_id"`
+	Error    string `json:"error"`
+}
+
+// RestoreBackupResponse is returned after restoring a backup.
+type RestoreBackupResponse struct {
+	Imported int64          `json:"imported"`
+	Skipped  int64          `json:"skipped"`
+	Errors   []RestoreError `json:"errors"`
+
+	// Status is "restored", or "dry_run" when RestoreOptions.DryRun was set.
+	Status string `json:"status"`
+}
+
+// ---------------------------------------------------------------------------
+// Summarize
+// ---------------------------------------------------------------------------
+
+// SummarizeInput contains parameters for summarizing a conversation thread
+// with the server's language model.
+type SummarizeInput struct {
+	// ThreadID identifies the conversation thread. Required unless the
+	// context carries one; see WithThreadID.
+	ThreadID string `json:"thread_id"`
+
+	// BranchName is the branch to summarize. Defaults to "main".
+	BranchName *string `json:"branch_name,omitempty"`
+
+	// MaxMemories limits the summary to the most recent memories.
+	MaxMemories *int `json:"max_memories,omitempty"`
+
+	// SummaryPrompt replaces the server's default summarization prompt.
+	SummaryPrompt *string `json:"summary_prompt,omitempty"`
+
+	// StoreSummary stores the summary as a new memory in the thread.
+	StoreSummary bool `json:"store_summary,omitempty"`
+}
+
+// Validate checks SummarizeInput for values the server would reject.
+func (in SummarizeInput) Validate() error {
+	if in.MaxMemories != nil && *in.MaxMemories <= 0 {
+		return &ValidationError{Field: "max_memories", Message: fmt.Sprintf("must be positive, got %d", *in.MaxMemories)}
+	}
+	return nil
+}
+
+// SummaryResponse is returned after summarizing a thread.
+type SummaryResponse struct {
+	Summary string `json:"summary"`
+
+	// MemoryCount is the number of memories summarized.
+	MemoryCount int `json:"memory_count"`
+
+	// StoredMemoryID is the ID of the stored summary when
+	// SummarizeInput.StoreSummary was set, and nil otherwise.
+	StoredMemoryID *string `json:"stored_memory_id,omitempty"`
+
+	Status string `json:"status"`
+}
+
+// ---------------------------------------------------------------------------
+// Groups
+// ---------------------------------------------------------------------------
+
+// CreateGroupInput contains parameters for defining a named group of agents
+// that ShareInput.GroupID can target.
+type CreateGroupInput struct {
+	// GroupID is the identifier used to refer to the group. Required.
+	GroupID string `json:"group_id"`
+
+	// Name is a human-readable label for the group.
+	Name string `json:"name,omitempty"`
+
+	// AgentIDs lists the group's members. Required.
+	AgentIDs []string `json:"agent_ids"`
+}
+
+// Validate checks CreateGroupInput for values the server would reject.
+func (in CreateGroupInput) Validate() error {
+	if in.GroupID == "" {
+		return &ValidationError{Field: "group_id", Message: "is required"}
+	}
+	if len(in.AgentIDs) == 0 {
+		return &ValidationError{Field: "agent_ids", Message: "must not be empty"}
+	}
+	return nil
+}
+
+// AgentGroup describes one group of agents.
+type AgentGroup struct {
+	GroupID  string   `json:"group_id"`
+	Name     string   `json:"name"`
+	AgentIDs []string `json:"agent_ids"`
+}
+
+// CreateGroupResponse is returned after creating a group.
+type CreateGroupResponse struct {
+	AgentGroup
+	Status string `json:"status"`
+}
+
+// ListGroupsInput contains parameters for listing agent groups.
+type ListGroupsInput struct {
+	// AgentID overrides the default agent identifier.
+	AgentID *string `json:"agent_id,omitempty"`
+}
+
+// ListGroupsResponse is returned after listing groups.
+type ListGroupsResponse struct {
+	Groups []AgentGroup `json:"groups"`
+	Total  int          `json:"total"`
+}
+
+// ---------------------------------------------------------------------------
+// Compare
+// ---------------------------------------------------------------------------
+
+// compareMemoriesInput is the argument of "mnemo.compare_memories".
+type compareMemoriesInput struct {
+	MemoryIDA string `json:"memory_id_a"`
+	MemoryIDB string `json:"memory_id_b"`
+}
+
+// MemoryDiff describes how memory B differs from memory A, for example an
+// earlier and a later version of the same belief.
+type MemoryDiff struct {
+	MemoryIDA string `json:"memory_id_a"`
+	MemoryIDB string `json:"memory_id_b"`
+
+	// ContentDiff is a unified diff from A's content to B's.
+	ContentDiff string `json:"content_diff"`
+
+	// ImportanceDelta is B's importance minus A's.
+	ImportanceDelta float32 `json:"importance_delta"`
+
+	// TagsAdded and TagsRemoved list the tags B has that A lacks, and the
+	// reverse.
+	TagsAdded   []string `json:"tags_added"`
+	TagsRemoved []string `json:"tags_removed"`
+
+	// MetadataChanges maps each metadata key that differs to its value in
+	// B, or nil if B removed it.
+	MetadataChanges map[string]interface{} `json:"metadata_changes"`
+}
+
+// ---------------------------------------------------------------------------
+// Similarity
+// ---------------------------------------------------------------------------
+
+// ScoreSimilarityInput contains the two memories to compare.
+type ScoreSimilarityInput struct {
+	MemoryIDA string `json:"memory_id_a"`
+	MemoryIDB string `json:"memory_id_b"`
+}
+
+// Validate checks ScoreSimilarityInput for values the server would reject.
+func (in ScoreSimilarityInput) Validate() error {
+	if in.MemoryIDA == "" {
+		return &ValidationError{Field: "memory_id_a", Message: "is required"}
+	}
+	if in.MemoryIDB == "" {
+		return &ValidationError{Field: "memory_id_b", Message: "is required"}
+	}
+	return nil
+}
+
+// ScoreSimilarityResponse is returned after scoring two memories.
+type ScoreSimilarityResponse struct {
+	// Score is the cosine similarity of the memories' embeddings, in [0, 1].
+	Score  float32 `json:"score"`
+	Status string  `json:"status"`
+}
+
+// ---------------------------------------------------------------------------
+// Embedding
+// ---------------------------------------------------------------------------
+
+// GetEmbeddingInput contains the text to embed with the server's model.
+type GetEmbeddingInput struct {
+	// Content is the text to embed. Required.
+	Content string `json:"content"`
+
+	// ModelOverride selects an embedding model other than the server
+	// default.
+	ModelOverride *string `json:"model_override,omitempty"`
+}
+
+// Validate checks GetEmbeddingInput for values the server would reject.
+func (in GetEmbeddingInput) Validate() error {
+	if in.Content == "" {
+		return &ValidationError{Field: "content", Message: "is required"}
+	}
+	return nil
+}
+
+// GetEmbeddingResponse is returned after embedding text.
+type GetEmbeddingResponse struct {
+	Embedding  []float32 `json:"embedding"`
+	Model      string    `json:"model"`
+	Dimensions int       `json:"dimensions"`
+}
+
+// ---------------------------------------------------------------------------
+// Reset
+// ---------------------------------------------------------------------------
+
+// ResetConfirmation is the confirm string Reset requires.
+const ResetConfirmation = "RESET"
+
+// resetAgentInput is the argument of "mnemo.reset_agent".
+type resetAgentInput struct {
+	AgentID *string `json:"agent_id,omitempty"`
+	Confirm string  `json:"confirm"`
+}
+
+// ResetResponse is returned after clearing an agent's data.
+type ResetResponse struct {
+	DeletedMemories    int    `json:"deleted_memories"`
+	DeletedCheckpoints int    `json:"deleted_checkpoints"`
+	DeletedDelegations int    `json:"deleted_delegations"`
+	Status             string `json:"status"`
+}
+
+// ---------------------------------------------------------------------------
+// Preferences
+// ---------------------------------------------------------------------------
+
+// PreferenceInput contains parameters for creating a preference profile
+// that RecallInput.PersonalizedBy can name.
+type PreferenceInput struct {
+	// ProfileID identifies the profile, typically a user ID. Required.
+	ProfileID string `json:"profile_id"`
+
+	// AgentID overrides the default agent identifier.
+	AgentID *string `json:"agent_id,omitempty"`
+
+	// TagWeights adjusts the score of memories carrying each tag, for
+	// example {"vegetarian": 0.3}. Values must be within [-1, 1].
+	TagWeights map[string]float32 `json:"tag_weights,omitempty"`
+
+	// MemoryTypeWeights adjusts the score of memories of each type. Values
+	// must be within [-1, 1].
+	MemoryTypeWeights map[string]float32 `json:"memory_type_weights,omitempty"`
+
+	// Description is a human-readable note about the profile.
+	Description *string `json:"description,omitempty"`
+}
+
+// Validate checks PreferenceInput for values the server would reject.
+func (in PreferenceInput) Validate() error {
+	if in.ProfileID == "" {
+		return &ValidationError{Field: "profile_id", Message: "is required"}
+	}
+	for tag, w := range in.TagWeights {
+		if w < -1 || w > 1 {
+			return &ValidationError{Field: "tag_weights", Message: fmt.Sprintf("value for tag %q must be within [-1, 1], got %v", tag, w)}
+		}
+	}
+	for typ, w := range in.MemoryTypeWeights {
+		if w < -1 || w > 1 {
+			return &ValidationError{Field: "memory_type_weights", Message: fmt.Sprintf("value for type %q must be within [-1, 1], got %v", typ, w)}
+		}
+	}
+	return nil
+}
+
+// PreferenceResponse is returned after creating a preference profile.
+type PreferenceResponse struct {
+	ProfileID string `json:"profile_id"`
+	Status    string `json:"status"`
+}
+
+// ---------------------------------------------------------------------------
+// Transaction
+// ---------------------------------------------------------------------------
+
+// Operation types accepted in TransactionOp.Type.
+const (
+	TransactionOpRemember = "remember"
+	TransactionOpForget   = "forget"
+	TransactionOpUpdate   = "update"
+)
+
+// TransactionOp is a single operation within a transaction.
+type TransactionOp struct {
+	// Type is one of TransactionOpRemember, TransactionOpForget, or
+	// TransactionOpUpdate.
+	Type string `json:"type"`
+
+	// Payload holds the operation's arguments, for example a RememberInput
+	// or ForgetInput.
+	Payload interface{} `json:"payload"`
+}
+
+// TransactionInput contains parameters for applying several operations
+// atomically. Either every operation is applied or none are.
+type TransactionInput struct {
+	// Operations are applied in order. At least one is required.
+	Operations []TransactionOp `json:"operations"`
+}
+
+// Validate checks TransactionInput for values the server would reject.
+func (in TransactionInput) Validate() error {
+	if len(in.Operations) == 0 {
+		return &ValidationError{Field: "operations", Message: "at least one operation is required"}
+	}
+	for i, op := range in.Operations {
+		switch op.Type {
+		case TransactionOpRemember, TransactionOpForget, TransactionOpUpdate:
+		default:
+			return &ValidationError{
+				Field:   "operations",
+				Message: fmt.Sprintf("operation %d has unknown type %q", i, op.Type),
+			}
+		}
+	}
+	return nil
+}
+
+// TransactionResult is the outcome of one operation in a transaction.
+type TransactionResult struct {
+	// Index is the position of the operation in TransactionInput.Operations.
+	Index  int    `json:"index"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+
+	// ID is the memory the operation created or affected, if any.
+	ID *string `json:"id,omitempty"`
+
+	// Error describes why the operation failed.
+	Error *string `json:"error,omitempty"`
+}
+
+// TransactionResponse is returned after a transaction. Status is
+// "committed" when every operation succeeded and "rolled_back" when any
+// failed, in which case no operation took effect.
+type TransactionResponse struct {
+	Results []TransactionResult `json:"results"`
+	Status  string              `json:"status"`
+}
+
+// ---------------------------------------------------------------------------
+// Watch
+// ---------------------------------------------------------------------------
+
+// MemoryEvent describes a change to a memory observed by WatchMemory,
+// SubscribeTagEvents, or DiffSince.
+type MemoryEvent struct {
+	// Type is the kind of change: "remember", "update", or "forget".
+	Type string `json:"type"`
+
+	MemoryID  string      `json:"memory_id"`
+	AgentID   string      `json:"agent_id"`
+	Timestamp string      `json:"timestamp"`
+	Payload   interface{} `json:"payload,omitempty"`
+}
+
+// watchInput is the argument of a "mnemo.watch" poll.
+type watchInput struct {
+	MemoryID string  `json:"memory_id"`
+	Cursor   *string `json:"cursor,omitempty"`
+}
+
+// subscribeTagInput is the argument of a "mnemo.subscribe_tag" poll.
+type subscribeTagInput struct {
+	Tag        string   `json:"tag"`
+	EventTypes []string `json:"event_types"`
+	Cursor     *string  `json:"cursor,omitempty"`
+}
+
+// watchResponse is the result of a "mnemo.watch" or "mnemo.subscribe_tag"
+// poll. Cursor is passed back on the next poll so only newer events are
+// returned.
+type watchResponse struct {
+	Events []MemoryEvent `json:"events"`
+	Cursor *string       `json:"cursor,omitempty"`
+}
+
+// diffSinceInput is the argument of a "mnemo.diff_since" call.
+type diffSinceInput struct {
+	Since     time.Time `json:"since"`
+	BatchSize int       `json:"batch_size"`
+	Cursor    *string   `json:"cursor,omitempty"`
+}
+
+// diffSinceResponse is one batch of "mnemo.diff_since" events. HasMore
+// reports whether another batch follows Cursor.
+type diffSinceResponse struct {
+	Events  []MemoryEvent `json:"events"`
+	Cursor  *string       `json:"cursor,omitempty"`
+	HasMore bool          `json:"has_more"`
 }
 
 // ---------------------------------------------------------------------------
@@ -450,6 +2085,12 @@ type jsonRPCResponse struct {
 type jsonRPCResult struct {
 	Content []jsonRPCContent `json:"content,omitempty"`
 
+	// IsError marks a tool call that failed; Content holds the message.
+	IsError bool `json:"isError,omitempty"`
+
+	// Meta echoes request metadata, such as the correlation ID.
+	Meta *toolCallMeta `json:"_meta,omitempty"`
+
 	// Raw captures any other fields for non-tool-call responses (e.g.
 	// initialize).
 	Raw map[string]interface{} `json:"-"`
@@ -461,6 +2102,10 @@ type jsonRPCContent struct {
 	Text string `json:"text"`
 }
 
+// toolContents, passed as the dest of callTool, receives every content item
+// of a tool result undecoded.
+type toolContents []jsonRPCContent
+
 // jsonRPCError represents the error field of a JSON-RPC error response.
 type jsonRPCError struct {
 	Code    int    `json:"code"`
@@ -469,6 +2114,17 @@ type jsonRPCError struct {
 
 // toolCallParams is the params envelope for a tools/call request.
 type toolCallParams struct {
-	Name      string      `json:"name"`
-	Arguments interface{} `json:"arguments"`
+	Name      string        `json:"name"`
+	Arguments interface{}   `json:"arguments"`
+	Meta      *toolCallMeta `json:"_meta,omitempty"`
+}
+
+// toolCallMeta carries transport hints alongside a tools/call request.
+type toolCallMeta struct {
+	// ContentEncoding is set when Arguments holds encoded rather than plain
+	// JSON arguments.
+	ContentEncoding string `json:"contentEncoding,omitempty"`
+
+	// CorrelationID traces the call across logs; see WithCorrelationID.
+	CorrelationID string `json:"x-correlation-id,omitempty"`
 }
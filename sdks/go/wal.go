@@ -0,0 +1,194 @@
+package mnemo
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// walMu serializes access to write-ahead log files. It is package-level
+// because several clients, for example an old and a reconnected one, may
+// share a WALPath.
+var walMu sync.Mutex
+
+// walEntry is one line of the write-ahead log.
+type walEntry struct {
+	CreatedAt time.Time     `json:"created_at"`
+	Input     RememberInput `json:"input"`
+}
+
+// FlushWAL replays the Remember calls recorded in ClientOptions.WALPath and
+// returns how many succeeded. Replayed entries are removed from the log,
+// as are entries older than ClientOptions.WALMaxAge and entries the server
+// rejects outright. Any other failure, such as an unreachable server, a
+// closed client, or ctx ending, stops the flush and keeps the failed entry
+// and all later ones for the next flush. Only the client and context
+// errors are returned.
+//
+// NewClient calls FlushWAL once the connection is established, so callers
+// normally need it only to retry without reconnecting.
+func (c *Client) FlushWAL(ctx context.Context) (int, error) {
+	if c.opts.WALPath == "" {
+		return 0, nil
+	}
+
+	walMu.Lock()
+	defer walMu.Unlock()
+
+	entries, err := readWAL(c.opts.WALPath)
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	flushed := 0
+	var keep []walEntry
+	var stopErr error
+	for i, e := range entries {
+		if c.opts.WALMaxAge > 0 && now.Sub(e.CreatedAt) > c.opts.WALMaxAge {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			keep = append(keep, entries[i:]...)
+			stopErr = err
+			break
+		}
+		if _, err := c.remember(ctx, e.Input); err != nil {
+			if isServerRejection(err) {
+				c.warn(fmt.Errorf("mnemo: dropping write-ahead log entry from %s: %w",
+					e.CreatedAt.Format(time.RFC3339), err))
+				continue
+			}
+			keep = append(keep, entries[i:]...)
+			if !isTransportError(err) {
+				stopErr = err
+			}
+			break
+		}
+		flushed++
+	}
+
+	if err := writeWAL(c.opts.WALPath, keep); err != nil {
+		return flushed, err
+	}
+	return flushed, stopErr
+}
+
+// appendWAL records a failed remember in the write-ahead log.
+func (c *Client) appendWAL(input RememberInput) error {
+	data, err := json.Marshal(walEntry{CreatedAt: time.Now().UTC(), Input: input})
+	if err != nil {
+		return fmt.Errorf("mnemo: write-ahead log: %w", err)
+	}
+
+	walMu.Lock()
+	defer walMu.Unlock()
+
+	f, err := os.OpenFile(c.opts.WALPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("mnemo: write-ahead log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("mnemo: write-ahead log: %w", err)
+	}
+	return f.Close()
+}
+
+// readWAL parses the write-ahead log at path. A missing file is an empty
+// log; malformed lines are skipped. Lines have no length limit, since an
+// entry holds a whole RememberInput.
+func readWAL(path string) ([]walEntry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mnemo: write-ahead log: %w", err)
+	}
+
+	var entries []walEntry
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var e walEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// writeWAL atomically replaces the log at path with entries, removing the
+// file when there are none.
+func writeWAL(path string, entries []walEntry) error {
+	if len(entries) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("mnemo: write-ahead log: %w", err)
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("mnemo: write-ahead log: %w", err)
+		}
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("mnemo: write-ahead log: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("mnemo: write-ahead log: %w", err)
+	}
+	return nil
+}
+
+// isServerRejection reports whether err means the request was refused as
+// invalid, by the server or by client-side validation, so retrying it
+// unchanged cannot succeed.
+func isServerRejection(err error) bool {
+	var rpcErr *RPCError
+	var toolErr *ToolError
+	var verr *ValidationError
+	return errors.As(err, &rpcErr) || errors.As(err, &toolErr) || errors.As(err, &verr)
+}
+
+// isTransportError reports whether err means the request may not have
+// reached the server, as opposed to the server or client rejecting it.
+func isTransportError(err error) bool {
+	var rpcErr *RPCError
+	var toolErr *ToolError
+	var verr *ValidationError
+	switch {
+	case errors.As(err, &rpcErr), errors.As(err, &toolErr), errors.As(err, &verr),
+		errors.Is(err, ErrClientClosed),
+		errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return false
+	}
+	return true
+}
+
+// newIdempotencyKey returns a random key for RememberInput.IdempotencyKey.
+func newIdempotencyKey() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("mnemo: idempotency key: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
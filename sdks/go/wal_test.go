@@ -0,0 +1,205 @@
+package mnemo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestWAL pre-populates a write-ahead log file with entries.
+func writeTestWAL(t *testing.T, path string, entries ...walEntry) {
+	t.Helper()
+	if err := writeWAL(path, entries); err != nil {
+		t.Fatalf("writeWAL: %v", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestFlushWAL — verifies pending entries are replayed and removed.
+// ---------------------------------------------------------------------------
+
+func TestFlushWAL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mnemo.wal")
+	now := time.Now().UTC()
+	key := "key-1"
+	writeTestWAL(t, path,
+		walEntry{CreatedAt: now.Add(-time.Minute), Input: RememberInput{Content: "first", IdempotencyKey: &key}},
+		walEntry{CreatedAt: now.Add(-2 * time.Hour), Input: RememberInput{Content: "too old"}},
+		walEntry{CreatedAt: now, Input: RememberInput{Content: "second"}},
+	)
+
+	var replayed []RememberInput
+	opts := ClientOptions{WALPath: path, WALMaxAge: time.Hour}
+	c := newPipeClientWithOptions(t, opts, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in RememberInput
+		_ = json.Unmarshal(args, &in)
+		replayed = append(replayed, in)
+		return RememberResponse{ID: "mem-" + in.Content, Status: "remembered"}, nil
+	})
+
+	n, err := c.FlushWAL(context.Background())
+	if err != nil {
+		t.Fatalf("FlushWAL: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("FlushWAL() = %d, want 2", n)
+	}
+	if len(replayed) != 2 || replayed[0].Content != "first" || replayed[1].Content != "second" {
+		t.Fatalf("replayed = %+v, want first and second", replayed)
+	}
+	if replayed[0].IdempotencyKey == nil || *replayed[0].IdempotencyKey != key {
+		t.Errorf("replayed IdempotencyKey = %v, want %q", replayed[0].IdempotencyKey, key)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("WAL file still exists after a full flush: %v", err)
+	}
+
+	if n, err := c.FlushWAL(context.Background()); n != 0 || err != nil {
+		t.Errorf("FlushWAL() on empty log = %d, %v, want 0, nil", n, err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestFlushWALKeepsUnreachable — verifies transport failures stay logged.
+// ---------------------------------------------------------------------------
+
+func TestFlushWALKeepsUnreachable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mnemo.wal")
+	writeTestWAL(t, path,
+		walEntry{CreatedAt: time.Now().UTC(), Input: RememberInput{Content: "pending"}},
+	)
+
+	c := newPipeClientWithOptions(t, ClientOptions{WALPath: path}, nil)
+	_ = c.stdin.Close() // the server can no longer be reached
+
+	n, err := c.FlushWAL(context.Background())
+	if err != nil {
+		t.Fatalf("FlushWAL: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("FlushWAL() = %d, want 0", n)
+	}
+	entries, err := readWAL(path)
+	if err != nil {
+		t.Fatalf("readWAL: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Input.Content != "pending" {
+		t.Errorf("WAL entries = %+v, want the pending entry kept", entries)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestFlushWALInterrupted — verifies entries not yet flushed stay logged when
+// ctx is cancelled or the client is closed during a flush.
+// ---------------------------------------------------------------------------
+
+func TestFlushWALInterrupted(t *testing.T) {
+	tests := []struct {
+		name    string
+		stop    func(c *Client, cancel context.CancelFunc)
+		wantErr error
+	}{
+		{"cancel", func(c *Client, cancel context.CancelFunc) { cancel() }, context.Canceled},
+		{"close", func(c *Client, cancel context.CancelFunc) {
+			go c.Close()
+			for {
+				c.lifecycleMu.Lock()
+				closed := c.closed
+				c.lifecycleMu.Unlock()
+				if closed {
+					return
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}, ErrClientClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "mnemo.wal")
+			now := time.Now().UTC()
+			writeTestWAL(t, path,
+				walEntry{CreatedAt: now, Input: RememberInput{Content: "first"}},
+				walEntry{CreatedAt: now, Input: RememberInput{Content: "second"}},
+				walEntry{CreatedAt: now, Input: RememberInput{Content: "third"}},
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var c *Client
+			c = newPipeClientWithOptions(t, ClientOptions{WALPath: path}, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+				tt.stop(c, cancel)
+				return RememberResponse{ID: "mem-1", Status: "remembered"}, nil
+			})
+
+			_, err := c.FlushWAL(ctx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("FlushWAL() error = %v, want %v", err, tt.wantErr)
+			}
+			entries, err := readWAL(path)
+			if err != nil {
+				t.Fatalf("readWAL: %v", err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Input.Content)
+			}
+			if n := len(got); n < 2 || got[n-2] != "second" || got[n-1] != "third" {
+				t.Errorf("WAL entries = %q, want the unflushed second and third kept", got)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestRememberAppendsWAL — verifies failed remembers are logged with a key.
+// ---------------------------------------------------------------------------
+
+func TestRememberAppendsWAL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mnemo.wal")
+	c := newPipeClientWithOptions(t, ClientOptions{WALPath: path}, nil)
+	_ = c.stdin.Close()
+
+	if _, err := c.Remember(RememberInput{Content: "lost write"}); err == nil {
+		t.Fatal("Remember over a closed pipe: expected error, got nil")
+	}
+
+	entries, err := readWAL(path)
+	if err != nil {
+		t.Fatalf("readWAL: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Input.Content != "lost write" {
+		t.Fatalf("WAL entries = %+v, want the failed remember", entries)
+	}
+	if entries[0].Input.IdempotencyKey == nil || *entries[0].Input.IdempotencyKey == "" {
+		t.Error("logged entry has no idempotency key")
+	}
+	if entries[0].CreatedAt.IsZero() {
+		t.Error("logged entry has no CreatedAt")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestReadWALLongLine — verifies entries larger than a response are read.
+// ---------------------------------------------------------------------------
+
+func TestReadWALLongLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mnemo.wal")
+	large := strings.Repeat("x", 2*defaultMaxResponseBytes)
+	writeTestWAL(t, path,
+		walEntry{CreatedAt: time.Now().UTC(), Input: RememberInput{Content: large}},
+		walEntry{CreatedAt: time.Now().UTC(), Input: RememberInput{Content: "small"}},
+	)
+
+	entries, err := readWAL(path)
+	if err != nil {
+		t.Fatalf("readWAL: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Input.Content != large || entries[1].Input.Content != "small" {
+		t.Errorf("readWAL() returned %d entries, want the large and small entries", len(entries))
+	}
+}
@@ -0,0 +1,164 @@
+package mnemo
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// defaultPollInterval is used when WatchOptions.PollInterval is unset.
+const defaultPollInterval = time.Second
+
+// WatchOptions configures change notifications.
+type WatchOptions struct {
+	// PollInterval is how often the server is polled for new events.
+	// Defaults to one second.
+	PollInterval time.Duration
+}
+
+// WatchMemory delivers change events for memoryID, such as updates or
+// deletions made by other agents, on the returned channel. It polls the
+// "mnemo.watch" tool every WatchOptions.PollInterval.
+//
+// The first poll happens before WatchMemory returns, so a failure to start
+// watching is reported as an error. The channel is closed when ctx is done
+// or a later poll fails.
+func (c *Client) WatchMemory(ctx context.Context, memoryID string) (<-chan MemoryEvent, error) {
+	if memoryID == "" {
+		return nil, &ValidationError{Field: "memory_id", Message: "is required"}
+	}
+
+	args := func(cursor *string) interface{} {
+		return watchInput{MemoryID: memoryID, Cursor: cursor}
+	}
+	return c.pollEvents(ctx, "mnemo.watch", args, nil)
+}
+
+// SubscribeTagEvents delivers events for memories carrying tag on the
+// returned channel. eventTypes selects which kinds of change are wanted
+// ("remember", "forget", "update") and must not be empty; events of other
+// types are dropped by the client even if the server sends them. It polls
+// the "mnemo.subscribe_tag" tool and follows the same lifecycle as
+// WatchMemory.
+func (c *Client) SubscribeTagEvents(ctx context.Context, tag string, eventTypes []string) (<-chan MemoryEvent, error) {
+	if tag == "" {
+		return nil, &ValidationError{Field: "tag", Message: "is required"}
+	}
+	if len(eventTypes) == 0 {
+		return nil, &ValidationError{Field: "event_types", Message: "at least one event type is required"}
+	}
+
+	wanted := make(map[string]struct{}, len(eventTypes))
+	for _, t := range eventTypes {
+		wanted[t] = struct{}{}
+	}
+	keep := func(ev MemoryEvent) bool {
+		_, ok := wanted[ev.Type]
+		return ok
+	}
+
+	args := func(cursor *string) interface{} {
+		return subscribeTagInput{Tag: tag, EventTypes: eventTypes, Cursor: cursor}
+	}
+	return c.pollEvents(ctx, "mnemo.subscribe_tag", args, keep)
+}
+
+// DiffSince delivers, in order, every memory change since the given time,
+// for example so an agent resuming after downtime can catch up. It fetches
+// batches of up to batchSize events from the "mnemo.diff_since" tool.
+//
+// The first batch is fetched before DiffSince returns, so a failure to
+// start is reported as an error. The channel is closed once the last batch
+// has been delivered, or early when ctx is done or a later fetch fails.
+func (c *Client) DiffSince(ctx context.Context, since time.Time, batchSize int) (<-chan MemoryEvent, error) {
+	if batchSize <= 0 {
+		return nil, &ValidationError{Field: "batch_size", Message: fmt.Sprintf("must be positive, got %d", batchSize)}
+	}
+
+	var first diffSinceResponse
+	if err := c.callTool(ctx, "mnemo.diff_since", diffSinceInput{Since: since, BatchSize: batchSize}, &first); err != nil {
+		return nil, err
+	}
+
+	events := make(chan MemoryEvent)
+	go func() {
+		defer close(events)
+
+		resp := first
+		for {
+			for _, ev := range resp.Events {
+				select {
+				case events <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if !resp.HasMore || len(resp.Events) == 0 {
+				return
+			}
+
+			cursor := resp.Cursor
+			resp = diffSinceResponse{}
+			if err := c.callTool(ctx, "mnemo.diff_since", diffSinceInput{Since: since, BatchSize: batchSize, Cursor: cursor}, &resp); err != nil {
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// pollEvents repeatedly calls tool with the arguments built by args, passing
+// back the cursor from the previous poll, and delivers every event accepted
+// by keep (all events if keep is nil). The first poll runs synchronously so
+// its error can be returned.
+func (c *Client) pollEvents(ctx context.Context, tool string, args func(cursor *string) interface{}, keep func(MemoryEvent) bool) (<-chan MemoryEvent, error) {
+	var first watchResponse
+	if err := c.callTool(ctx, tool, args(nil), &first); err != nil {
+		return nil, err
+	}
+
+	events := make(chan MemoryEvent)
+	go func() {
+		defer close(events)
+
+		resp := first
+		var cursor *string
+		for {
+			for _, ev := range resp.Events {
+				if keep != nil && !keep(ev) {
+					continue
+				}
+				select {
+				case events <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if resp.Cursor != nil {
+				cursor = resp.Cursor
+			}
+
+			select {
+			case <-time.After(c.pollInterval()):
+			case <-ctx.Done():
+				return
+			}
+
+			resp = watchResponse{}
+			if err := c.callTool(ctx, tool, args(cursor), &resp); err != nil {
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// pollInterval returns the configured watch poll interval.
+func (c *Client) pollInterval() time.Duration {
+	if c.opts.Watch.PollInterval > 0 {
+		return c.opts.Watch.PollInterval
+	}
+	return defaultPollInterval
+}
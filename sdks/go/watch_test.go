@@ -0,0 +1,202 @@
+package mnemo
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// TestWatchMemory — verifies polled events arrive on the channel in order.
+// ---------------------------------------------------------------------------
+
+func TestWatchMemory(t *testing.T) {
+	var mu sync.Mutex
+	polls := 0
+	var cursors []string
+
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var in watchInput
+		_ = json.Unmarshal(args, &in)
+		if in.Cursor != nil {
+			cursors = append(cursors, *in.Cursor)
+		}
+
+		polls++
+		switch polls {
+		case 1:
+			cursor := "c1"
+			return watchResponse{Events: []MemoryEvent{{Type: "update", MemoryID: in.MemoryID, AgentID: "agent-2", Timestamp: "2024-06-01T12:00:00Z"}}, Cursor: &cursor}, nil
+		case 2:
+			cursor := "c2"
+			return watchResponse{Events: []MemoryEvent{{Type: "update", MemoryID: in.MemoryID, AgentID: "agent-3", Timestamp: "2024-06-01T12:00:05Z"}}, Cursor: &cursor}, nil
+		default:
+			return watchResponse{}, nil
+		}
+	})
+	c.opts.Watch.PollInterval = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := c.WatchMemory(ctx, "mem-1")
+	if err != nil {
+		t.Fatalf("WatchMemory: %v", err)
+	}
+
+	var got []MemoryEvent
+	for len(got) < 2 {
+		select {
+		case ev := <-events:
+			got = append(got, ev)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out after %d events", len(got))
+		}
+	}
+
+	if got[0].AgentID != "agent-2" || got[1].AgentID != "agent-3" {
+		t.Errorf("events = %+v, want agent-2 then agent-3", got)
+	}
+	for _, ev := range got {
+		if ev.MemoryID != "mem-1" || ev.Type != "update" {
+			t.Errorf("event = %+v, want update of mem-1", ev)
+		}
+	}
+
+	cancel()
+	for range events {
+		// Drain until the watcher notices cancellation and closes.
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(cursors) == 0 || cursors[0] != "c1" {
+		t.Errorf("cursors = %v, want the second poll to resume from c1", cursors)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestWatchMemoryEmptyID — verifies a memory ID is required.
+// ---------------------------------------------------------------------------
+
+func TestWatchMemoryEmptyID(t *testing.T) {
+	c := &Client{}
+	if _, err := c.WatchMemory(context.Background(), ""); err == nil {
+		t.Fatal("expected error for empty memory ID, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestSubscribeTagEventsFiltersTypes — verifies unsubscribed types are dropped.
+// ---------------------------------------------------------------------------
+
+func TestSubscribeTagEventsFiltersTypes(t *testing.T) {
+	var mu sync.Mutex
+	var gotArgs subscribeTagInput
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = json.Unmarshal(args, &gotArgs)
+		return watchResponse{Events: []MemoryEvent{
+			{Type: "update", MemoryID: "mem-1"},
+			{Type: "remember", MemoryID: "mem-2"},
+			{Type: "forget", MemoryID: "mem-3"},
+		}}, nil
+	})
+	c.opts.Watch.PollInterval = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := c.SubscribeTagEvents(ctx, "billing", []string{"remember", "forget"})
+	if err != nil {
+		t.Fatalf("SubscribeTagEvents: %v", err)
+	}
+
+	var got []string
+	for len(got) < 2 {
+		select {
+		case ev := <-events:
+			got = append(got, ev.Type)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out after %v", got)
+		}
+	}
+	if got[0] != "remember" || got[1] != "forget" {
+		t.Errorf("event types = %v, want [remember forget]", got)
+	}
+
+	mu.Lock()
+	if gotArgs.Tag != "billing" || len(gotArgs.EventTypes) != 2 {
+		t.Errorf("server args = %+v", gotArgs)
+	}
+	mu.Unlock()
+
+	if _, err := c.SubscribeTagEvents(ctx, "billing", nil); err == nil {
+		t.Error("SubscribeTagEvents without event types: expected error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestDiffSince — verifies every batch arrives in order before the channel
+// closes.
+// ---------------------------------------------------------------------------
+
+func TestDiffSince(t *testing.T) {
+	since := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	var cursors []string
+	c := newPipeClient(t, func(name string, args json.RawMessage) (interface{}, *jsonRPCError) {
+		var in diffSinceInput
+		_ = json.Unmarshal(args, &in)
+		if !in.Since.Equal(since) || in.BatchSize != 2 {
+			return nil, &jsonRPCError{Code: -32602, Message: "bad arguments " + string(args)}
+		}
+		if in.Cursor == nil {
+			cursor := "b1"
+			return diffSinceResponse{Events: []MemoryEvent{
+				{Type: "created", MemoryID: "mem-1"},
+				{Type: "updated", MemoryID: "mem-1"},
+			}, Cursor: &cursor, HasMore: true}, nil
+		}
+		cursors = append(cursors, *in.Cursor)
+		return diffSinceResponse{Events: []MemoryEvent{{Type: "deleted", MemoryID: "mem-2"}}}, nil
+	})
+
+	events, err := c.DiffSince(context.Background(), since, 2)
+	if err != nil {
+		t.Fatalf("DiffSince: %v", err)
+	}
+
+	var got []string
+	timeout := time.After(time.Second)
+	for done := false; !done; {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				done = true
+				break
+			}
+			got = append(got, ev.Type+":"+ev.MemoryID)
+		case <-timeout:
+			t.Fatalf("timed out after %v", got)
+		}
+	}
+
+	want := []string{"created:mem-1", "updated:mem-1", "deleted:mem-2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(cursors, []string{"b1"}) {
+		t.Errorf("cursors = %v, want the second batch to resume from b1", cursors)
+	}
+
+	if _, err := c.DiffSince(context.Background(), since, 0); err == nil {
+		t.Error("DiffSince with batch size 0 = nil error, want ValidationError")
+	}
+}